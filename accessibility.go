@@ -0,0 +1,437 @@
+package gosui
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"strings"
+)
+
+// Role is the kind of user interface object an element is to assistive
+// technologies, like screen readers
+type Role int
+
+const (
+	RoleNone Role = iota // The element isn't exposed, its children are
+	RoleWindow
+	RoleGroup
+	RoleText
+	RoleTextInput
+	RoleImage
+	RoleButton
+	RoleCheckBox
+	RoleRadioButton
+	RoleSwitch
+	RoleSlider
+	RoleSpinButton
+	RoleProgressBar
+	RoleComboBox
+	RoleList
+	RoleListItem
+	RoleMenuBar
+	RoleMenu
+	RoleMenuItem
+	RoleTabList
+	RoleTab
+	RoleTabPanel
+	RoleTree
+	RoleTreeItem
+	RoleGrid
+	RoleRow
+	RoleCell
+	RoleColumnHeader
+	RoleDialog
+	RoleTooltip
+	RoleSplitter
+)
+
+var roleNames = [...]string{
+	"none", "window", "group", "text", "text input", "image", "button", "check box",
+	"radio button", "switch", "slider", "spin button", "progress bar", "combo box",
+	"list", "list item", "menu bar", "menu", "menu item", "tab list", "tab",
+	"tab panel", "tree", "tree item", "grid", "row", "cell", "column header",
+	"dialog", "tooltip", "splitter",
+}
+
+func (r Role) String() string {
+	if r < 0 || int(r) >= len(roleNames) {
+		return fmt.Sprintf("Role(%d)", int(r))
+	}
+	return roleNames[r]
+}
+
+// MarshalText encodes the role as its name
+func (r Role) MarshalText() ([]byte, error) {
+	return []byte(r.String()), nil
+}
+
+// leaf reports whether elements of the role present their descendants
+// themselves, which aren't exposed
+func (r Role) leaf() bool {
+	switch r {
+	case RoleTextInput, RoleImage, RoleSlider, RoleSpinButton, RoleProgressBar,
+		RoleComboBox, RoleSplitter:
+		return true
+	}
+	return r.namedByContent()
+}
+
+// namedByContent reports whether elements of the role are named by the texts
+// of their descendants when they aren't given a name
+func (r Role) namedByContent() bool {
+	switch r {
+	case RoleText, RoleButton, RoleCheckBox, RoleRadioButton, RoleSwitch, RoleListItem,
+		RoleMenuItem, RoleTab, RoleTreeItem, RoleCell, RoleColumnHeader, RoleTooltip:
+		return true
+	}
+	return false
+}
+
+// AccessState is a set of states of an accessible element
+type AccessState uint
+
+const (
+	StateFocusable AccessState = 1 << iota
+	StateFocused
+	StateDisabled
+	StateEditable
+	StateCheckable
+	StateChecked
+	StateMixed // Partially checked
+	StateSelected
+	StateExpandable
+	StateExpanded
+	StatePressed
+	StateModal
+)
+
+var stateNames = []string{
+	"focusable", "focused", "disabled", "editable", "checkable", "checked", "mixed",
+	"selected", "expandable", "expanded", "pressed", "modal",
+}
+
+// Names returns the names of the states in the set
+func (s AccessState) Names() (names []string) {
+	for i, n := range stateNames {
+		if s&(1<<uint(i)) != 0 {
+			names = append(names, n)
+		}
+	}
+	return names
+}
+
+func (s AccessState) String() string {
+	return strings.Join(s.Names(), " ")
+}
+
+// MarshalText encodes the states as their names separated by spaces
+func (s AccessState) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// Accessible describes an element to assistive technologies
+type Accessible struct {
+	Role        Role        `json:"role"`
+	Name        string      `json:"name,omitempty"` // Label of the element, its text if empty and the role is a leaf one
+	Description string      `json:"description,omitempty"`
+	Value       string      `json:"value,omitempty"` // Current value of inputs, like the text of a text input
+	States      AccessState `json:"states,omitempty"`
+	// Hidden hides the element and its descendants, like decorative glyphs
+	Hidden bool `json:"-"`
+}
+
+// accessibleHandler is implemented by handlers that describe their element,
+// like the ones of widgets
+type accessibleHandler interface {
+	Accessible() Accessible
+}
+
+var (
+	accessKey    = NewDataKey("accessible")
+	accessIDKey  = NewDataKey("access id")
+	lastAccessID int
+)
+
+// SetAccessible describes the element to assistive technologies. The fields
+// that aren't zero override the description given by its handler, states
+// are added to the ones of the handler.
+func SetAccessible(e IElement, a Accessible) {
+	e.BaseElement().SetData(accessKey, &a)
+	if s := SceneOf(e); s != nil {
+		s.accessDirty = true
+	}
+}
+
+// AccessibleOf returns the description of the element, made of the one of
+// its handler, the one set with SetAccessible and the states of the element.
+// Text elements are texts, unless they're empty, and editable ones are text
+// inputs by default.
+func AccessibleOf(e IElement) (a Accessible) {
+	b := e.BaseElement()
+	if ce, ok := e.(*ConcreteElement); ok && ce.IsText() {
+		switch ts := ce.TextShape(); {
+		case ts.Editable:
+			a = Accessible{Role: RoleTextInput, Value: ts.Content, States: StateEditable}
+		case ts.Content != "":
+			a = Accessible{Role: RoleText, Name: ts.Content}
+		}
+	}
+	if h, ok := b.Handler.(accessibleHandler); ok {
+		a = h.Accessible()
+	}
+	if set, ok := b.Data(accessKey).(*Accessible); ok {
+		if set.Role != RoleNone {
+			a.Role = set.Role
+		}
+		if set.Name != "" {
+			a.Name = set.Name
+		}
+		if set.Description != "" {
+			a.Description = set.Description
+		}
+		if set.Value != "" {
+			a.Value = set.Value
+		}
+		a.States |= set.States
+		a.Hidden = a.Hidden || set.Hidden
+	}
+	if isFocusable(e) {
+		a.States |= StateFocusable
+	}
+	if !b.EnabledInTree() {
+		a.States |= StateDisabled
+	}
+	return a
+}
+
+// AccessNode is a node of the accessibility tree, which is derived from the
+// element tree: hidden elements and elements with no role aren't part of it,
+// but the children of the latter are.
+// It only holds plain values, so that adapters can export it as it is.
+type AccessNode struct {
+	ID int `json:"id"` // Stable for the life of the element
+	Accessible
+	Bounds   image.Rectangle `json:"bounds"`
+	Children []*AccessNode   `json:"children,omitempty"`
+	Parent   *AccessNode     `json:"-"`
+	Element  IElement        `json:"-"`
+}
+
+// accessID returns the id of the element in accessibility trees
+func accessID(b *Element) int {
+	if id, ok := b.Data(accessIDKey).(int); ok {
+		return id
+	}
+	lastAccessID++
+	b.SetData(accessIDKey, lastAccessID)
+	return lastAccessID
+}
+
+// BuildAccessTree derives the accessibility tree of e and its descendants.
+// The root node is e, whatever its role.
+func BuildAccessTree(e IElement) *AccessNode {
+	n := newAccessNode(e, AccessibleOf(e))
+	if !n.Role.leaf() {
+		n.addChildren(e)
+	}
+	return n
+}
+
+func newAccessNode(e IElement, a Accessible) *AccessNode {
+	return &AccessNode{ID: accessID(e.BaseElement()), Accessible: a, Bounds: Bounds(e), Element: e}
+}
+
+func (n *AccessNode) addChildren(e IElement) {
+	if a, ok := e.(*AbstractElement); ok {
+		for _, c := range a.children {
+			n.add(c)
+		}
+	}
+}
+
+// add adds the node of c to the children of n, or the nodes of its children
+// if it has no role
+func (n *AccessNode) add(c IElement) {
+	if !c.BaseElement().Visible() {
+		return
+	}
+	ca := AccessibleOf(c)
+	if ca.Hidden {
+		return
+	}
+	if ca.Role == RoleNone {
+		n.addChildren(c)
+		return
+	}
+	cn := newAccessNode(c, ca)
+	cn.Parent = n
+	if cn.Name == "" && ca.Role.namedByContent() {
+		cn.Name = textOf(c)
+	}
+	if !ca.Role.leaf() {
+		cn.addChildren(c)
+	}
+	n.Children = append(n.Children, cn)
+}
+
+// textOf returns the visible texts of the element and its descendants,
+// separated by spaces
+func textOf(e IElement) string {
+	var l []string
+	for o := visibleConcreteDescns(e).Front(); o != nil; o = o.Next() {
+		if ce := o.Value.(*ConcreteElement); ce.IsText() && ce.TextShape().Content != "" {
+			l = append(l, ce.TextShape().Content)
+		}
+	}
+	return strings.Join(l, " ")
+}
+
+// Find returns the node of the element in the tree of n, nil if it has none
+func (n *AccessNode) Find(e IElement) *AccessNode {
+	if n.Element.BaseElement() == e.BaseElement() {
+		return n
+	}
+	for _, c := range n.Children {
+		if f := c.Find(e); f != nil {
+			return f
+		}
+	}
+	return nil
+}
+
+// String returns a one-line description of the node:
+// its role, quoted name, value and states
+func (n *AccessNode) String() string {
+	var b bytes.Buffer
+	b.WriteString(n.Role.String())
+	if n.Name != "" {
+		fmt.Fprintf(&b, " %q", n.Name)
+	}
+	if n.Value != "" {
+		fmt.Fprintf(&b, " value=%q", n.Value)
+	}
+	if n.States != 0 {
+		b.WriteString(" [" + n.States.String() + "]")
+	}
+	return b.String()
+}
+
+// Dump returns the descriptions of the node and its descendants, one per
+// line and indented by depth
+func (n *AccessNode) Dump() string {
+	var b bytes.Buffer
+	n.dump(&b, 0)
+	return b.String()
+}
+
+func (n *AccessNode) dump(b *bytes.Buffer, depth int) {
+	b.WriteString(strings.Repeat("  ", depth) + n.String() + "\n")
+	for _, c := range n.Children {
+		c.dump(b, depth+1)
+	}
+}
+
+// AccessAdapter exposes the accessibility tree of a scene to the assistive
+// technologies of a platform
+type AccessAdapter interface {
+	// TreeChanged is called with the new tree when the scene has changed,
+	// once per redraw
+	TreeChanged(root *AccessNode)
+	// FocusChanged is called with the node of the element given the focus,
+	// or of its closest ancestor in the tree; nil when no element has it
+	FocusChanged(n *AccessNode)
+}
+
+// SetAccessAdapter makes the scene report its accessibility tree to a,
+// nil stops reporting it
+func (s *Scene) SetAccessAdapter(a AccessAdapter) {
+	s.access = a
+	s.accessTree = nil
+	if a != nil {
+		s.accessDirty = true
+		s.updateAccess()
+	}
+}
+
+// AccessTree returns the accessibility tree of the scene. Its root is a
+// window holding the elements of the scene, then its open popups.
+func (s *Scene) AccessTree() *AccessNode {
+	if s.accessTree == nil || s.accessDirty {
+		s.accessTree = newAccessNode(s.root, Accessible{Role: RoleWindow})
+		s.accessTree.Bounds = s.viewport
+		for _, c := range s.root.children {
+			if c != IElement(s.overlay) {
+				s.accessTree.add(c)
+			}
+		}
+		s.accessTree.add(s.overlay)
+		s.accessDirty = false
+		if n := s.accessFocused(); n != nil {
+			n.States |= StateFocused
+		}
+		for _, p := range s.popups {
+			if n := s.accessTree.Find(p.AbstractElement); n != nil && p.Modal {
+				n.States |= StateModal
+			}
+		}
+	}
+	return s.accessTree
+}
+
+// updateAccess rebuilds the tree and reports it if the scene has changed
+func (s *Scene) updateAccess() {
+	if s.access != nil && (s.accessDirty || s.accessTree == nil) {
+		s.access.TreeChanged(s.AccessTree())
+	}
+}
+
+// accessFocus reports the focused element to the adapter
+func (s *Scene) accessFocus() {
+	if s.access == nil {
+		return
+	}
+	s.updateAccess()
+	s.access.FocusChanged(s.accessFocused())
+}
+
+// accessFocused returns the node of the focused element, or of its closest
+// ancestor that has one
+func (s *Scene) accessFocused() (n *AccessNode) {
+	for e := s.focused; e != nil && n == nil; {
+		n = s.accessTree.Find(e)
+		if p := e.BaseElement().parent; p != nil {
+			e = p
+		} else {
+			e = nil
+		}
+	}
+	return n
+}
+
+// AccessRecorder is an AccessAdapter that keeps what it's told, for tests
+type AccessRecorder struct {
+	Tree    *AccessNode // The last tree reported
+	Updates int         // Number of trees reported
+	Focus   []string    // Descriptions of the focused nodes, "" for none
+}
+
+func (r *AccessRecorder) TreeChanged(root *AccessNode) {
+	r.Tree = root
+	r.Updates++
+}
+
+func (r *AccessRecorder) FocusChanged(n *AccessNode) {
+	if n == nil {
+		r.Focus = append(r.Focus, "")
+		return
+	}
+	r.Focus = append(r.Focus, n.String())
+}
+
+// Dump returns the dump of the last tree reported
+func (r *AccessRecorder) Dump() string {
+	if r.Tree == nil {
+		return ""
+	}
+	return r.Tree.Dump()
+}
@@ -0,0 +1,62 @@
+package gosui
+
+import (
+	"encoding/json"
+
+	chk "launchpad.net/gocheck"
+)
+
+func (s *MySuite) TestAccessTree(c *chk.C) {
+	sc := NewScene()
+	form := NewAbstractElement(sc.Root(), MakeRect(0, 0, 200, 100))
+	SetAccessible(form, Accessible{Role: RoleGroup, Name: "Login"})
+	NewRectElement(form, MakeRect(0, 0, 200, 100))
+	title := NewTextElement(form, 10, 20, Font{"Arial", 10, Regular}, false)
+	title.TextShape().Content = "Name"
+	in := NewTextInputElement(form, 10, 50, Font{"Arial", 10, Regular})
+	in.Input().SetText("bob")
+	icon := NewRectElement(form, MakeRect(150, 40, 160, 50))
+	SetAccessible(icon, Accessible{Role: RoleImage, Name: "user"})
+	deco := NewTextElement(sc.Root(), 10, 90, Font{"Arial", 10, Regular}, false)
+	deco.TextShape().Content = "★"
+	SetAccessible(deco, Accessible{Hidden: true})
+
+	rec := new(AccessRecorder)
+	sc.SetAccessAdapter(rec)
+	c.Check(rec.Dump(), chk.Equals, `window
+  group "Login"
+    text "Name"
+    text input value="bob" [focusable editable]
+    image "user"
+`)
+	c.Check(rec.Tree.Children[0].Children[1].Bounds, chk.Equals, in.Area)
+
+	// Focus and changes are reported
+	sc.Focus(in)
+	c.Check(rec.Focus, chk.DeepEquals, []string{`text input value="bob" [focusable focused editable]`})
+	sc.HandleChar(&CharEvent{Char: '!'})
+	updates := rec.Updates
+	sc.RedrawDirty(new(DummyBackend))
+	c.Check(rec.Updates, chk.Equals, updates+1)
+	c.Check(rec.Tree.Children[0].Children[1].String(), chk.Equals, `text input value="bob!" [focusable focused editable]`)
+	sc.RedrawDirty(new(DummyBackend))
+	c.Check(rec.Updates, chk.Equals, updates+1)
+
+	// Disabling the form takes the focus away
+	SetVisible(icon, false)
+	SetEnabled(form, false)
+	c.Check(rec.Focus[1], chk.Equals, "")
+	sc.RedrawDirty(new(DummyBackend))
+	c.Check(rec.Dump(), chk.Equals, `window
+  group "Login" [disabled]
+    text "Name" [disabled]
+    text input value="bob!" [disabled editable]
+`)
+
+	// Ids are stable and the tree exports to JSON
+	id := rec.Tree.Children[0].ID
+	c.Check(sc.AccessTree().Children[0].ID, chk.Equals, id)
+	js, err := json.Marshal(sc.AccessTree().Children[0].Children[0])
+	c.Assert(err, chk.IsNil)
+	c.Check(string(js), chk.Matches, `\{"id":\d+,"role":"text","name":"Name","states":"disabled","bounds":.*\}`)
+}
@@ -0,0 +1,356 @@
+package gosui
+
+import (
+	"image"
+	"math"
+	"sort"
+	"time"
+)
+
+// Easing maps the progress of an animation, from 0 to 1, to the progress of
+// the animated values. It should return 0 for 0 and 1 for 1.
+type Easing func(t float64) float64
+
+var (
+	Linear    Easing = func(t float64) float64 { return t }
+	EaseIn    Easing = func(t float64) float64 { return t * t * t }
+	EaseOut   Easing = func(t float64) float64 { t = 1 - t; return 1 - t*t*t }
+	EaseInOut Easing = func(t float64) float64 {
+		if t < 0.5 {
+			return 4 * t * t * t
+		}
+		t = 2 - 2*t
+		return 1 - t*t*t/2
+	}
+	// EaseOutBack overshoots the target a little, then settles on it
+	EaseOutBack Easing = func(t float64) float64 {
+		const c = 1.70158
+		t--
+		return 1 + (c+1)*t*t*t + c*t*t
+	}
+)
+
+// Animation is something that changes with time, like a Tween or a Timeline.
+// Scene.Play runs animations.
+type Animation interface {
+	Duration() time.Duration
+	// Seek applies the state of the animation at t, between 0 and Duration
+	Seek(t time.Duration)
+	// Reset makes the animation read its start values again on the next Seek
+	Reset()
+}
+
+// Tween changes some values over a duration. Its start values are read
+// when it's first seeked, so that it can follow other animations of the same values.
+type Tween struct {
+	duration time.Duration
+	start    func()
+	apply    func(p float64)
+	started  bool
+
+	// Easing shapes the progress of the tween, nil means Linear
+	Easing Easing
+}
+
+// NewTween creates a tween lasting d. start, which can be nil, is called to read
+// the start values, then apply is called with the eased progress of the tween.
+func NewTween(d time.Duration, start func(), apply func(p float64)) *Tween {
+	return &Tween{duration: d, start: start, apply: apply}
+}
+
+// Ease sets the easing of the tween and returns it
+func (t *Tween) Ease(e Easing) *Tween {
+	t.Easing = e
+	return t
+}
+
+func (t *Tween) Duration() time.Duration {
+	return t.duration
+}
+
+func (t *Tween) Seek(at time.Duration) {
+	if !t.started {
+		t.started = true
+		if t.start != nil {
+			t.start()
+		}
+	}
+	p := 1.0
+	if t.duration > 0 {
+		p = math.Max(0, math.Min(1, float64(at)/float64(t.duration)))
+	}
+	if t.Easing != nil && p > 0 && p < 1 {
+		p = t.Easing(p)
+	}
+	t.apply(p)
+}
+
+func (t *Tween) Reset() {
+	t.started = false
+}
+
+func lerp(a, b int, p float64) int {
+	return a + int(math.Floor(float64(b-a)*p+0.5))
+}
+
+func lerpPoint(a, b image.Point, p float64) image.Point {
+	return image.Point{lerp(a.X, b.X, p), lerp(a.Y, b.Y, p)}
+}
+
+func lerpColor(a, b Color, p float64) Color {
+	return Color{
+		uint8(lerp(int(a.R), int(b.R), p)), uint8(lerp(int(a.G), int(b.G), p)),
+		uint8(lerp(int(a.B), int(b.B), p)), uint8(lerp(int(a.A), int(b.A), p)),
+	}
+}
+
+// MoveTo creates a tween moving the element and its descendants so that its
+// top-left corner ends at pt
+func MoveTo(e IElement, pt image.Point, d time.Duration) *Tween {
+	var from image.Point
+	return NewTween(d, func() { from = e.BaseElement().Area.Min }, func(p float64) {
+		delta := lerpPoint(from, pt, p).Sub(e.BaseElement().Area.Min)
+		Invalidate(e)
+		e.MoveBy(delta.X, delta.Y)
+		Invalidate(e)
+	})
+}
+
+// ResizeTo creates a tween changing the area of the element with Resize
+func ResizeTo(e IElement, area image.Rectangle, d time.Duration) *Tween {
+	var from image.Rectangle
+	return NewTween(d, func() { from = e.BaseElement().Area }, func(p float64) {
+		Resize(e, image.Rectangle{lerpPoint(from.Min, area.Min, p), lerpPoint(from.Max, area.Max, p)})
+	})
+}
+
+// FadeTo creates a tween changing the opacity of the element
+func FadeTo(e IElement, opacity float64, d time.Duration) *Tween {
+	var from float64
+	b := e.BaseElement()
+	return NewTween(d, func() { from = b.Opacity() }, func(p float64) {
+		b.SetOpacity(from + (opacity-from)*p)
+		Invalidate(e)
+	})
+}
+
+// PaintTo creates a tween changing the colors and stroke width of the element
+func PaintTo(e *ConcreteElement, paint Paint, d time.Duration) *Tween {
+	var from Paint
+	return NewTween(d, func() { from = e.Paint }, func(p float64) {
+		e.FillColor = lerpColor(from.FillColor, paint.FillColor, p)
+		e.StrokeColor = lerpColor(from.StrokeColor, paint.StrokeColor, p)
+		e.StrokeWidth = lerp(from.StrokeWidth, paint.StrokeWidth, p)
+		Invalidate(e)
+	})
+}
+
+// CornersTo creates a tween changing the corner radii of a rectangle element
+func CornersTo(e *ConcreteElement, radii RectCornersRad, d time.Duration) *Tween {
+	var from RectCornersRad
+	r := e.RectShape()
+	return NewTween(d, func() { from = r.CornerRadiis() }, func(p float64) {
+		r.SetCornerRadiis(RectCornersRad{
+			lerp(from.TopLeft, radii.TopLeft, p), lerp(from.TopRight, radii.TopRight, p),
+			lerp(from.BotLeft, radii.BotLeft, p), lerp(from.BotRight, radii.BotRight, p),
+		})
+		Invalidate(e)
+	})
+}
+
+// ZIndexTo creates a tween changing the z-index of the element
+func ZIndexTo(e *ConcreteElement, z float32, d time.Duration) *Tween {
+	var from float32
+	return NewTween(d, func() { from = e.ZIndex() }, func(p float64) {
+		e.SetZIndex(from + (z-from)*float32(p))
+		Invalidate(e)
+	})
+}
+
+type timelineItem struct {
+	at   time.Duration
+	a    Animation
+	done bool // Seeked to its end
+}
+
+// Timeline runs animations at given offsets, they can overlap
+type Timeline struct {
+	items []*timelineItem // Sorted by offset
+	last  time.Duration   // Offset of the last added animation
+	pos   time.Duration
+}
+
+// Sequence creates a timeline running the animations one after the other
+func Sequence(anims ...Animation) *Timeline {
+	t := new(Timeline)
+	for _, a := range anims {
+		t.Then(a)
+	}
+	return t
+}
+
+// Parallel creates a timeline running the animations at the same time
+func Parallel(anims ...Animation) *Timeline {
+	t := new(Timeline)
+	for _, a := range anims {
+		t.Add(0, a)
+	}
+	return t
+}
+
+// Add adds an animation starting at offset at and returns the timeline
+func (t *Timeline) Add(at time.Duration, a Animation) *Timeline {
+	t.items = append(t.items, &timelineItem{at: at, a: a})
+	sort.Stable(timelineOrder(t.items))
+	t.last = at
+	return t
+}
+
+// Then adds an animation starting when all the others have ended
+func (t *Timeline) Then(a Animation) *Timeline {
+	return t.Add(t.Duration(), a)
+}
+
+// With adds an animation starting with the last added one
+func (t *Timeline) With(a Animation) *Timeline {
+	return t.Add(t.last, a)
+}
+
+type timelineOrder []*timelineItem
+
+func (l timelineOrder) Len() int           { return len(l) }
+func (l timelineOrder) Swap(i, j int)      { l[i], l[j] = l[j], l[i] }
+func (l timelineOrder) Less(i, j int) bool { return l[i].at < l[j].at }
+
+func (t *Timeline) Duration() (d time.Duration) {
+	for _, it := range t.items {
+		if end := it.at + it.a.Duration(); end > d {
+			d = end
+		}
+	}
+	return d
+}
+
+// Seek seeks the animations that have started at t. Going forward, they are
+// seeked in the order they start and those that had already ended are left alone.
+// Going backward, they are seeked in the reverse order.
+func (t *Timeline) Seek(at time.Duration) {
+	if at >= t.pos {
+		for _, it := range t.items {
+			if at < it.at || it.done {
+				continue
+			}
+			end := it.a.Duration()
+			if at-it.at >= end {
+				it.a.Seek(end)
+				it.done = true
+			} else {
+				it.a.Seek(at - it.at)
+			}
+		}
+	} else {
+		for i := len(t.items) - 1; i >= 0; i-- {
+			it := t.items[i]
+			if t.pos < it.at || (it.done && at >= it.at+it.a.Duration()) {
+				continue
+			}
+			it.done = false
+			if at > it.at {
+				it.a.Seek(at - it.at)
+			} else {
+				it.a.Seek(0)
+			}
+		}
+	}
+	t.pos = at
+}
+
+func (t *Timeline) Reset() {
+	t.pos = 0
+	for _, it := range t.items {
+		it.done = false
+		it.a.Reset()
+	}
+}
+
+// Player runs an animation on a Scene
+type Player struct {
+	scene    *Scene
+	a        Animation
+	pos      time.Duration
+	reversed bool
+	running  bool
+	ticker   int // Incremented to stop the running ticker
+
+	// OnDone is called when the animation reaches its end, or its start if it's reversed
+	OnDone func()
+}
+
+// Play starts an animation, it's seeked to its start at once
+// and progresses on each frame of the scene
+func (s *Scene) Play(a Animation) *Player {
+	p := &Player{scene: s, a: a}
+	a.Reset()
+	a.Seek(0)
+	p.run()
+	return p
+}
+
+func (p *Player) run() {
+	p.ticker++
+	id := p.ticker
+	p.running = true
+	p.scene.Animate(func(dt time.Duration) bool {
+		if !p.running || p.ticker != id {
+			return false
+		}
+		if p.reversed {
+			p.pos -= dt
+		} else {
+			p.pos += dt
+		}
+		if p.pos <= 0 && p.reversed || p.pos >= p.a.Duration() && !p.reversed {
+			p.Finish()
+			return false
+		}
+		p.a.Seek(p.pos)
+		return true
+	})
+}
+
+// Running reports whether the animation is playing
+func (p *Player) Running() bool {
+	return p.running
+}
+
+// Position returns the current time of the animation
+func (p *Player) Position() time.Duration {
+	return p.pos
+}
+
+// Cancel stops the animation where it is
+func (p *Player) Cancel() {
+	p.running = false
+}
+
+// Reverse plays the animation backward from where it is,
+// or forward again if it was reversed
+func (p *Player) Reverse() {
+	p.reversed = !p.reversed
+	if !p.running {
+		p.run()
+	}
+}
+
+// Finish jumps to the end of the animation, or its start if it's reversed, and stops it
+func (p *Player) Finish() {
+	p.pos = p.a.Duration()
+	if p.reversed {
+		p.pos = 0
+	}
+	p.a.Seek(p.pos)
+	p.running = false
+	if p.OnDone != nil {
+		p.OnDone()
+	}
+}
@@ -0,0 +1,117 @@
+package gosui
+
+import (
+	"image"
+	"math"
+	"time"
+
+	chk "launchpad.net/gocheck"
+)
+
+func (s *MySuite) TestEasings(c *chk.C) {
+	for _, e := range []Easing{Linear, EaseIn, EaseOut, EaseInOut, EaseOutBack} {
+		c.Check(math.Abs(e(0)) < 1e-9, chk.Equals, true)
+		c.Check(math.Abs(e(1)-1) < 1e-9, chk.Equals, true)
+	}
+	c.Check(EaseIn(0.5) < 0.5, chk.Equals, true)
+	c.Check(EaseOut(0.5) > 0.5, chk.Equals, true)
+	c.Check(EaseInOut(0.5), chk.Equals, 0.5)
+	c.Check(EaseOutBack(0.8) > 1, chk.Equals, true)
+}
+
+func (s *MySuite) TestTweens(c *chk.C) {
+	sc := NewScene()
+	clock := NewFakeClock(time.Unix(0, 0))
+	sc.SetClock(clock)
+	a := NewAbstractElement(sc.Root(), MakeRect(0, 0, 100, 100))
+	r := NewRectElement(a, MakeRect(10, 10, 20, 20))
+	r.Paint = Paint{FillColor: Color{0, 0, 0, 255}}
+	step := func(d time.Duration) {
+		clock.Advance(d)
+		sc.Update()
+	}
+
+	p := sc.Play(Parallel(
+		MoveTo(a, image.Point{100, 0}, 100*time.Millisecond),
+		PaintTo(r, Paint{FillColor: Color{200, 100, 0, 255}, StrokeWidth: 2}, 200*time.Millisecond),
+		FadeTo(a, 0, 200*time.Millisecond).Ease(EaseIn),
+	))
+	step(0)
+	sc.RedrawDirty(&DummyBackend{})
+	step(50 * time.Millisecond)
+	c.Check(r.Area, chk.Equals, MakeRect(60, 10, 70, 20))
+	c.Check(r.FillColor, chk.Equals, Color{50, 25, 0, 255})
+	c.Check(a.Opacity() > 0.98, chk.Equals, true)
+	c.Check(sc.NeedsRedraw(), chk.Equals, true)
+	step(100 * time.Millisecond)
+	c.Check(a.Area.Min, chk.Equals, image.Point{100, 0})
+	c.Check(r.StrokeWidth, chk.Equals, 2)
+
+	// Reversing plays back from where it is
+	p.Reverse()
+	step(100 * time.Millisecond)
+	c.Check(r.Area, chk.Equals, MakeRect(60, 10, 70, 20))
+	c.Check(p.Running(), chk.Equals, true)
+	done := false
+	p.OnDone = func() { done = true }
+	step(time.Second)
+	c.Check(done, chk.Equals, true)
+	c.Check(r.Area, chk.Equals, MakeRect(10, 10, 20, 20))
+	c.Check(r.Paint, chk.Equals, Paint{FillColor: Color{0, 0, 0, 255}})
+	c.Check(a.Opacity(), chk.Equals, 1.0)
+	c.Check(sc.Animating(), chk.Equals, false)
+
+	// A cancelled animation stays where it is
+	p = sc.Play(ResizeTo(r, MakeRect(0, 0, 40, 40), 100*time.Millisecond))
+	step(0)
+	step(50 * time.Millisecond)
+	p.Cancel()
+	step(50 * time.Millisecond)
+	c.Check(r.Area, chk.Equals, MakeRect(5, 5, 30, 30))
+	c.Check(p.Running(), chk.Equals, false)
+}
+
+func (s *MySuite) TestTimeline(c *chk.C) {
+	sc := NewScene()
+	r := NewRectElement(sc.Root(), MakeRect(0, 0, 10, 10))
+	// The second move starts from where the first one ends
+	tl := Sequence(
+		MoveTo(r, image.Point{100, 0}, time.Second),
+		MoveTo(r, image.Point{100, 100}, time.Second),
+	).With(CornersTo(r, RectCornersRad{10, 10, 10, 10}, 500*time.Millisecond)).
+		Add(0, ZIndexTo(r, 2, 2*time.Second))
+	c.Check(tl.Duration(), chk.Equals, 2*time.Second)
+	tl.Seek(0)
+	tl.Seek(1500 * time.Millisecond)
+	c.Check(r.Area.Min, chk.Equals, image.Point{100, 50})
+	c.Check(r.RectShape().CornerRadiis(), chk.Equals, RectCornersRad{10, 10, 10, 10})
+	c.Check(r.ZIndex(), chk.Equals, float32(1.5))
+	tl.Seek(500 * time.Millisecond)
+	c.Check(r.Area.Min, chk.Equals, image.Point{50, 0})
+	c.Check(r.RectShape().CornerRadiis(), chk.Equals, RectCornersRad{})
+	tl.Seek(2 * time.Second)
+	c.Check(r.Area.Min, chk.Equals, image.Point{100, 100})
+}
+
+func (s *MySuite) TestOpacity(c *chk.C) {
+	a := NewAbstractElement(NewRootElement(), MakeRect(0, 0, 10, 10))
+	r := NewRectElement(a, MakeRect(0, 0, 10, 10))
+	r.Paint = Paint{FillColor: Color{10, 20, 30, 200}, StrokeColor: Color{0, 0, 0, 100}}
+	a.SetOpacity(0.5)
+	r.SetOpacity(2)
+	c.Check(r.Opacity(), chk.Equals, 1.0)
+	b := &paintRecorder{}
+	r.Draw(b)
+	c.Check(b.paints, chk.DeepEquals, []Paint{{FillColor: Color{10, 20, 30, 100}, StrokeColor: Color{0, 0, 0, 50}}})
+	c.Check(r.Paint.FillColor.A, chk.Equals, uint8(200))
+}
+
+// paintRecorder records the paints of the drawn rectangles
+type paintRecorder struct {
+	DummyBackend
+	paints []Paint
+}
+
+func (b *paintRecorder) DrawRect(r image.Rectangle, radii [4]int, p Paint) {
+	b.paints = append(b.paints, p)
+}
@@ -0,0 +1,469 @@
+//go:build linux
+// +build linux
+
+// Package atspi exposes the accessibility tree of a gosui scene to the
+// screen readers of Linux desktops, like Orca, with AT-SPI over D-Bus.
+//
+// Nodes implement the Accessible and Component interfaces, the values of
+// inputs are given in the "value" attribute. Changes of focus, states, names
+// and children are reported as AT-SPI events.
+//
+//	a, err := atspi.Connect("My app")
+//	if err == nil {
+//		scene.SetAccessAdapter(a)
+//		defer a.Close()
+//	}
+package atspi
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/godbus/dbus/v5"
+	gs "github.com/phaikawl/gosui"
+)
+
+const (
+	basePath = "/org/a11y/atspi/accessible"
+	rootPath = basePath + "/root"
+	nullPath = "/org/a11y/atspi/null"
+
+	ifaceAccessible  = "org.a11y.atspi.Accessible"
+	ifaceApplication = "org.a11y.atspi.Application"
+	ifaceComponent   = "org.a11y.atspi.Component"
+	ifaceProperties  = "org.freedesktop.DBus.Properties"
+	eventObject      = "org.a11y.atspi.Event.Object."
+)
+
+// ref is the reference to an accessible object: a bus name and a path
+type ref struct {
+	Name string
+	Path dbus.ObjectPath
+}
+
+// Adapter is a gs.AccessAdapter registering a scene as an application on
+// the accessibility bus. Its methods are called on the UI goroutine, the
+// requests of the bus are answered from the last tree reported.
+type Adapter struct {
+	conn    *dbus.Conn
+	appName string
+	self    string // Unique name of the connection
+	parent  ref    // Desktop the application is embedded in
+	appID   int32
+
+	mu      sync.Mutex // Guards the fields below
+	root    *gs.AccessNode
+	nodes   map[int]*gs.AccessNode
+	focused *gs.AccessNode
+}
+
+// Connect connects to the accessibility bus and registers an application
+// named appName
+func Connect(appName string) (*Adapter, error) {
+	session, err := dbus.SessionBus()
+	if err != nil {
+		return nil, err
+	}
+	var addr string
+	if err := session.Object("org.a11y.Bus", "/org/a11y/bus").Call("org.a11y.Bus.GetAddress", 0).Store(&addr); err != nil {
+		return nil, fmt.Errorf("atspi: no accessibility bus: %v", err)
+	}
+	conn, err := dbus.Connect(addr)
+	if err != nil {
+		return nil, err
+	}
+	a := &Adapter{conn: conn, appName: appName, self: conn.Names()[0], nodes: make(map[int]*gs.AccessNode)}
+	exports := []struct {
+		v     interface{}
+		iface string
+	}{
+		{accessible{a}, ifaceAccessible},
+		{application{a}, ifaceApplication},
+		{component{a}, ifaceComponent},
+		{properties{a}, ifaceProperties},
+	}
+	for _, e := range exports {
+		if err := conn.ExportSubtree(e.v, basePath, e.iface); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+	registry := conn.Object("org.a11y.atspi.Registry", rootPath)
+	if err := registry.Call("org.a11y.atspi.Socket.Embed", 0, ref{a.self, rootPath}).Store(&a.parent); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("atspi: can't register the application: %v", err)
+	}
+	return a, nil
+}
+
+// Close unregisters the application
+func (a *Adapter) Close() error {
+	a.conn.Object("org.a11y.atspi.Registry", rootPath).Call("org.a11y.atspi.Socket.Unembed", 0, ref{a.self, rootPath})
+	return a.conn.Close()
+}
+
+func path(n *gs.AccessNode) dbus.ObjectPath {
+	return dbus.ObjectPath(basePath + "/" + strconv.Itoa(n.ID))
+}
+
+func (a *Adapter) ref(n *gs.AccessNode) ref {
+	if n == nil {
+		return ref{"", nullPath}
+	}
+	return ref{a.self, path(n)}
+}
+
+// TreeChanged keeps the tree and reports what changed since the previous one
+func (a *Adapter) TreeChanged(root *gs.AccessNode) {
+	nodes := make(map[int]*gs.AccessNode)
+	var index func(n *gs.AccessNode)
+	index = func(n *gs.AccessNode) {
+		nodes[n.ID] = n
+		for _, c := range n.Children {
+			index(c)
+		}
+	}
+	index(root)
+
+	a.mu.Lock()
+	old := a.nodes
+	a.root, a.nodes = root, nodes
+	if a.focused != nil {
+		a.focused = nodes[a.focused.ID]
+	}
+	a.mu.Unlock()
+
+	for id, n := range nodes {
+		if o := old[id]; o != nil {
+			a.diff(o, n)
+		}
+	}
+}
+
+// diff emits the events of the changes of a node
+func (a *Adapter) diff(o, n *gs.AccessNode) {
+	p := path(n)
+	if o.Name != n.Name {
+		a.emit(p, "PropertyChange", "accessible-name", 0, 0, dbus.MakeVariant(n.Name))
+	}
+	if o.Description != n.Description {
+		a.emit(p, "PropertyChange", "accessible-description", 0, 0, dbus.MakeVariant(n.Description))
+	}
+	if o.States&gs.StateDisabled != n.States&gs.StateDisabled {
+		on := n.States&gs.StateDisabled == 0
+		a.emitState(p, "enabled", on)
+		a.emitState(p, "sensitive", on)
+	}
+	for _, s := range states {
+		// The focus is reported by FocusChanged
+		if s.state != gs.StateFocused && o.States&s.state != n.States&s.state {
+			a.emitState(p, s.name, n.States&s.state != 0)
+		}
+	}
+	had := make(map[int]bool)
+	for _, c := range o.Children {
+		had[c.ID] = true
+	}
+	for i, c := range n.Children {
+		if !had[c.ID] {
+			a.emit(p, "ChildrenChanged", "add", i, 0, dbus.MakeVariant(a.ref(c)))
+		}
+		delete(had, c.ID)
+	}
+	for i, c := range o.Children {
+		if had[c.ID] {
+			a.emit(p, "ChildrenChanged", "remove", i, 0, dbus.MakeVariant(a.ref(c)))
+		}
+	}
+}
+
+// FocusChanged reports the focus leaving the previous node and entering n
+func (a *Adapter) FocusChanged(n *gs.AccessNode) {
+	a.mu.Lock()
+	prev := a.focused
+	a.focused = n
+	a.mu.Unlock()
+	if prev != nil {
+		a.emitState(path(prev), "focused", false)
+	}
+	if n != nil {
+		a.emitState(path(n), "focused", true)
+		a.conn.Emit(path(n), "org.a11y.atspi.Event.Focus.Focus", "", int32(0), int32(0), dbus.MakeVariant(int32(0)), map[string]dbus.Variant{})
+	}
+}
+
+func (a *Adapter) emitState(p dbus.ObjectPath, state string, on bool) {
+	v := 0
+	if on {
+		v = 1
+	}
+	a.emit(p, "StateChanged", state, v, 0, dbus.MakeVariant(int32(0)))
+}
+
+// emit sends an event of the Object interface, errors are ignored since
+// nothing can be done about them
+func (a *Adapter) emit(p dbus.ObjectPath, event, detail string, detail1, detail2 int, data dbus.Variant) {
+	a.conn.Emit(p, eventObject+event, detail, int32(detail1), int32(detail2), data, map[string]dbus.Variant{})
+}
+
+// object returns the node at the path of a call, nil for the application.
+// It must be called with mu held.
+func (a *Adapter) object(msg dbus.Message) (n *gs.AccessNode, err *dbus.Error) {
+	p, _ := msg.Headers[dbus.FieldPath].Value().(dbus.ObjectPath)
+	if p == rootPath {
+		return nil, nil
+	}
+	id, e := strconv.Atoi(strings.TrimPrefix(string(p), basePath+"/"))
+	if n = a.nodes[id]; e != nil || n == nil {
+		return nil, dbus.MakeFailedError(fmt.Errorf("atspi: unknown object %s", p))
+	}
+	return n, nil
+}
+
+// children returns the children of a node, or the window for the application
+func (a *Adapter) children(n *gs.AccessNode) []*gs.AccessNode {
+	if n == nil {
+		if a.root == nil {
+			return nil
+		}
+		return []*gs.AccessNode{a.root}
+	}
+	return n.Children
+}
+
+// accessible implements org.a11y.atspi.Accessible
+type accessible struct{ a *Adapter }
+
+func (x accessible) GetChildAtIndex(msg dbus.Message, i int32) (ref, *dbus.Error) {
+	x.a.mu.Lock()
+	defer x.a.mu.Unlock()
+	n, err := x.a.object(msg)
+	if err != nil {
+		return ref{}, err
+	}
+	c := x.a.children(n)
+	if i < 0 || int(i) >= len(c) {
+		return x.a.ref(nil), nil
+	}
+	return x.a.ref(c[i]), nil
+}
+
+func (x accessible) GetChildren(msg dbus.Message) ([]ref, *dbus.Error) {
+	x.a.mu.Lock()
+	defer x.a.mu.Unlock()
+	n, err := x.a.object(msg)
+	if err != nil {
+		return nil, err
+	}
+	refs := []ref{}
+	for _, c := range x.a.children(n) {
+		refs = append(refs, x.a.ref(c))
+	}
+	return refs, nil
+}
+
+func (x accessible) GetIndexInParent(msg dbus.Message) (int32, *dbus.Error) {
+	x.a.mu.Lock()
+	defer x.a.mu.Unlock()
+	n, err := x.a.object(msg)
+	if err != nil || n == nil || n.Parent == nil {
+		// The application and the window are the first child of their parent
+		return 0, err
+	}
+	for i, c := range n.Parent.Children {
+		if c == n {
+			return int32(i), nil
+		}
+	}
+	return -1, nil
+}
+
+// relation is a relation type and its targets
+type relation struct {
+	Type    uint32
+	Targets []ref
+}
+
+func (x accessible) GetRelationSet(msg dbus.Message) ([]relation, *dbus.Error) {
+	return []relation{}, nil
+}
+
+func (x accessible) GetRole(msg dbus.Message) (uint32, *dbus.Error) {
+	x.a.mu.Lock()
+	defer x.a.mu.Unlock()
+	n, err := x.a.object(msg)
+	switch {
+	case err != nil:
+		return 0, err
+	case n == nil:
+		return roleApplication, nil
+	}
+	return atspiRole(n.Role), nil
+}
+
+func (x accessible) GetRoleName(msg dbus.Message) (string, *dbus.Error) {
+	x.a.mu.Lock()
+	defer x.a.mu.Unlock()
+	n, err := x.a.object(msg)
+	switch {
+	case err != nil:
+		return "", err
+	case n == nil:
+		return "application", nil
+	}
+	return n.Role.String(), nil
+}
+
+func (x accessible) GetLocalizedRoleName(msg dbus.Message) (string, *dbus.Error) {
+	return x.GetRoleName(msg)
+}
+
+func (x accessible) GetState(msg dbus.Message) ([]uint32, *dbus.Error) {
+	x.a.mu.Lock()
+	defer x.a.mu.Unlock()
+	n, err := x.a.object(msg)
+	switch {
+	case err != nil:
+		return nil, err
+	case n == nil:
+		return []uint32{0, 0}, nil
+	}
+	return stateSet(n), nil
+}
+
+func (x accessible) GetAttributes(msg dbus.Message) (map[string]string, *dbus.Error) {
+	x.a.mu.Lock()
+	defer x.a.mu.Unlock()
+	n, err := x.a.object(msg)
+	attrs := map[string]string{"toolkit": "gosui"}
+	if n != nil && n.Value != "" {
+		attrs["value"] = n.Value
+	}
+	return attrs, err
+}
+
+func (x accessible) GetApplication(msg dbus.Message) (ref, *dbus.Error) {
+	return ref{x.a.self, rootPath}, nil
+}
+
+func (x accessible) GetInterfaces(msg dbus.Message) ([]string, *dbus.Error) {
+	x.a.mu.Lock()
+	defer x.a.mu.Unlock()
+	n, err := x.a.object(msg)
+	switch {
+	case err != nil:
+		return nil, err
+	case n == nil:
+		return []string{ifaceAccessible, ifaceApplication}, nil
+	}
+	return []string{ifaceAccessible, ifaceComponent}, nil
+}
+
+// application implements org.a11y.atspi.Application, for the root
+type application struct{ a *Adapter }
+
+func (x application) GetLocale(lctype uint32) (string, *dbus.Error) {
+	return "", nil
+}
+
+// extents is a rectangle as (x, y, width, height)
+type extents struct {
+	X, Y, W, H int32
+}
+
+// component implements org.a11y.atspi.Component. Coordinates are relative
+// to the window whatever the coordinate type asked, the position of the
+// window isn't known.
+type component struct{ a *Adapter }
+
+func (x component) GetExtents(msg dbus.Message, coordType uint32) (extents, *dbus.Error) {
+	x.a.mu.Lock()
+	defer x.a.mu.Unlock()
+	n, err := x.a.object(msg)
+	if err != nil || n == nil {
+		return extents{}, err
+	}
+	b := n.Bounds
+	return extents{int32(b.Min.X), int32(b.Min.Y), int32(b.Dx()), int32(b.Dy())}, nil
+}
+
+func (x component) Contains(msg dbus.Message, px, py int32, coordType uint32) (bool, *dbus.Error) {
+	e, err := x.GetExtents(msg, coordType)
+	return px >= e.X && py >= e.Y && px < e.X+e.W && py < e.Y+e.H, err
+}
+
+func (x component) GrabFocus(msg dbus.Message) (bool, *dbus.Error) {
+	return false, nil
+}
+
+// properties implements org.freedesktop.DBus.Properties for all objects
+type properties struct{ a *Adapter }
+
+func (x properties) Get(msg dbus.Message, iface, name string) (dbus.Variant, *dbus.Error) {
+	all, err := x.GetAll(msg, iface)
+	if err != nil {
+		return dbus.Variant{}, err
+	}
+	v, ok := all[name]
+	if !ok {
+		return dbus.Variant{}, dbus.MakeFailedError(fmt.Errorf("atspi: no property %s.%s", iface, name))
+	}
+	return v, nil
+}
+
+func (x properties) GetAll(msg dbus.Message, iface string) (map[string]dbus.Variant, *dbus.Error) {
+	a := x.a
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	n, err := a.object(msg)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case iface == ifaceApplication && n == nil:
+		return map[string]dbus.Variant{
+			"ToolkitName":  dbus.MakeVariant("gosui"),
+			"Version":      dbus.MakeVariant("1.0"),
+			"AtspiVersion": dbus.MakeVariant("2.1"),
+			"Id":           dbus.MakeVariant(a.appID),
+		}, nil
+	case iface != ifaceAccessible:
+		return map[string]dbus.Variant{}, nil
+	case n == nil:
+		return map[string]dbus.Variant{
+			"Name":         dbus.MakeVariant(a.appName),
+			"Description":  dbus.MakeVariant(""),
+			"Parent":       dbus.MakeVariant(a.parent),
+			"ChildCount":   dbus.MakeVariant(int32(len(a.children(nil)))),
+			"Locale":       dbus.MakeVariant(""),
+			"AccessibleId": dbus.MakeVariant(""),
+		}, nil
+	}
+	parent := ref{a.self, rootPath}
+	if n.Parent != nil {
+		parent = a.ref(n.Parent)
+	}
+	return map[string]dbus.Variant{
+		"Name":         dbus.MakeVariant(n.Name),
+		"Description":  dbus.MakeVariant(n.Description),
+		"Parent":       dbus.MakeVariant(parent),
+		"ChildCount":   dbus.MakeVariant(int32(len(n.Children))),
+		"Locale":       dbus.MakeVariant(""),
+		"AccessibleId": dbus.MakeVariant(strconv.Itoa(n.ID)),
+	}, nil
+}
+
+// Set only accepts the id the registry gives to the application
+func (x properties) Set(msg dbus.Message, iface, name string, v dbus.Variant) *dbus.Error {
+	if iface == ifaceApplication && name == "Id" {
+		if id, ok := v.Value().(int32); ok {
+			x.a.mu.Lock()
+			x.a.appID = id
+			x.a.mu.Unlock()
+			return nil
+		}
+	}
+	return dbus.MakeFailedError(fmt.Errorf("atspi: property %s.%s is read-only", iface, name))
+}
@@ -0,0 +1,144 @@
+//go:build linux
+// +build linux
+
+package atspi
+
+import (
+	gs "github.com/phaikawl/gosui"
+)
+
+// Values of AtspiRole
+const (
+	roleCheckBox          = 7
+	roleComboBox          = 11
+	roleDialog            = 16
+	roleFrame             = 23
+	roleImage             = 27
+	roleLabel             = 29
+	roleList              = 31
+	roleListItem          = 32
+	roleMenu              = 33
+	roleMenuBar           = 34
+	roleMenuItem          = 35
+	rolePageTab           = 37
+	rolePageTabList       = 38
+	rolePanel             = 39
+	roleProgressBar       = 42
+	rolePushButton        = 43
+	roleRadioButton       = 44
+	roleSeparator         = 50
+	roleSlider            = 51
+	roleSpinButton        = 52
+	roleTable             = 55
+	roleTableCell         = 56
+	roleTableColumnHeader = 57
+	roleToggleButton      = 62
+	roleToolTip           = 64
+	roleTree              = 65
+	roleUnknown           = 67
+	roleApplication       = 75
+	roleEntry             = 79
+	roleTableRow          = 90
+	roleTreeItem          = 91
+)
+
+var roles = map[gs.Role]uint32{
+	gs.RoleWindow:       roleFrame,
+	gs.RoleGroup:        rolePanel,
+	gs.RoleText:         roleLabel,
+	gs.RoleTextInput:    roleEntry,
+	gs.RoleImage:        roleImage,
+	gs.RoleButton:       rolePushButton,
+	gs.RoleCheckBox:     roleCheckBox,
+	gs.RoleRadioButton:  roleRadioButton,
+	gs.RoleSwitch:       roleToggleButton,
+	gs.RoleSlider:       roleSlider,
+	gs.RoleSpinButton:   roleSpinButton,
+	gs.RoleProgressBar:  roleProgressBar,
+	gs.RoleComboBox:     roleComboBox,
+	gs.RoleList:         roleList,
+	gs.RoleListItem:     roleListItem,
+	gs.RoleMenuBar:      roleMenuBar,
+	gs.RoleMenu:         roleMenu,
+	gs.RoleMenuItem:     roleMenuItem,
+	gs.RoleTabList:      rolePageTabList,
+	gs.RoleTab:          rolePageTab,
+	gs.RoleTabPanel:     rolePanel,
+	gs.RoleTree:         roleTree,
+	gs.RoleTreeItem:     roleTreeItem,
+	gs.RoleGrid:         roleTable,
+	gs.RoleRow:          roleTableRow,
+	gs.RoleCell:         roleTableCell,
+	gs.RoleColumnHeader: roleTableColumnHeader,
+	gs.RoleDialog:       roleDialog,
+	gs.RoleTooltip:      roleToolTip,
+	gs.RoleSplitter:     roleSeparator,
+}
+
+func atspiRole(r gs.Role) uint32 {
+	if v, ok := roles[r]; ok {
+		return v
+	}
+	return roleUnknown
+}
+
+// Values of AtspiStateType
+const (
+	stateChecked       = 4
+	stateEditable      = 7
+	stateEnabled       = 8
+	stateExpandable    = 9
+	stateExpanded      = 10
+	stateFocusable     = 11
+	stateFocused       = 12
+	stateModal         = 16
+	statePressed       = 20
+	stateSelectable    = 22
+	stateSelected      = 23
+	stateSensitive     = 24
+	stateShowing       = 25
+	stateVisible       = 30
+	stateIndeterminate = 32
+	stateCheckable     = 41
+)
+
+// states maps gosui states to AT-SPI states and the names of their events
+var states = []struct {
+	state gs.AccessState
+	value uint
+	name  string
+}{
+	{gs.StateFocusable, stateFocusable, "focusable"},
+	{gs.StateFocused, stateFocused, "focused"},
+	{gs.StateEditable, stateEditable, "editable"},
+	{gs.StateCheckable, stateCheckable, "checkable"},
+	{gs.StateChecked, stateChecked, "checked"},
+	{gs.StateMixed, stateIndeterminate, "indeterminate"},
+	{gs.StateSelected, stateSelected, "selected"},
+	{gs.StateExpandable, stateExpandable, "expandable"},
+	{gs.StateExpanded, stateExpanded, "expanded"},
+	{gs.StatePressed, statePressed, "pressed"},
+	{gs.StateModal, stateModal, "modal"},
+}
+
+// stateSet returns the AT-SPI state set of a node, a bit set in two words
+func stateSet(n *gs.AccessNode) []uint32 {
+	set := []uint32{0, 0}
+	add := func(v uint) { set[v/32] |= 1 << (v % 32) }
+	add(stateVisible)
+	add(stateShowing)
+	if n.States&gs.StateDisabled == 0 {
+		add(stateEnabled)
+		add(stateSensitive)
+	}
+	switch n.Role {
+	case gs.RoleListItem, gs.RoleTab, gs.RoleTreeItem, gs.RoleRow:
+		add(stateSelectable)
+	}
+	for _, s := range states {
+		if n.States&s.state != 0 {
+			add(s.value)
+		}
+	}
+	return set
+}
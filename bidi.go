@@ -0,0 +1,323 @@
+package gosui
+
+import "unicode"
+
+// bidiClass is the bidirectional type of a character in the Unicode
+// bidirectional algorithm (UAX #9)
+type bidiClass uint8
+
+const (
+	bidiL   bidiClass = iota // Left to right
+	bidiR                    // Right to left
+	bidiAL                   // Arabic letters
+	bidiEN                   // European digits
+	bidiES                   // Plus and minus signs
+	bidiET                   // Currency, degree and percent signs
+	bidiAN                   // Arabic digits
+	bidiCS                   // Number separators
+	bidiNSM                  // Combining marks
+	bidiBN                   // Format and control characters
+	bidiB                    // Paragraph separators
+	bidiS                    // Tabs
+	bidiWS                   // Spaces
+	bidiON                   // Other neutrals
+)
+
+var (
+	rtlScripts    = []*unicode.RangeTable{unicode.Hebrew, unicode.Samaritan, unicode.Mandaic, unicode.Nko, unicode.Adlam}
+	arabicScripts = []*unicode.RangeTable{unicode.Arabic, unicode.Syriac, unicode.Thaana}
+)
+
+// bidiClassOf returns the bidirectional type of r. It is derived from the
+// scripts and categories of the unicode package, which gives the type of the
+// characters in use in left to right, Hebrew and Arabic text.
+func bidiClassOf(r rune) bidiClass {
+	switch {
+	case r == '\t' || r == 0x0b || r == 0x1f:
+		return bidiS
+	case r == '\n' || r == '\r' || r >= 0x1c && r <= 0x1e || r == 0x85 || r == 0x2029:
+		return bidiB
+	case r == 0x200e:
+		return bidiL
+	case r == 0x200f:
+		return bidiR
+	case r == 0x61c:
+		return bidiAL
+	case r >= '0' && r <= '9', r >= 0x6f0 && r <= 0x6f9, r >= 0xff10 && r <= 0xff19,
+		r == 0xb2, r == 0xb3, r == 0xb9, r == 0x2070, r >= 0x2074 && r <= 0x2079, r >= 0x2080 && r <= 0x2089:
+		return bidiEN
+	case r >= 0x600 && r <= 0x605, r >= 0x660 && r <= 0x669, r == 0x66b, r == 0x66c, r == 0x6dd:
+		return bidiAN
+	case r == '+', r == '-', r == 0x207a, r == 0x207b, r == 0x208a, r == 0x208b, r == 0x2212,
+		r == 0xfb29, r == 0xfe62, r == 0xfe63, r == 0xff0b, r == 0xff0d:
+		return bidiES
+	case r == '#', r == '%', r == 0xb0, r == 0xb1, r == 0x609, r == 0x60a, r == 0x66a,
+		r >= 0x2030 && r <= 0x2034, unicode.Is(unicode.Sc, r):
+		return bidiET
+	case r == ',', r == '.', r == '/', r == ':', r == 0xa0, r == 0x60c, r == 0x202f, r == 0x2044,
+		r == 0xfe50, r == 0xfe52, r == 0xfe55, r == 0xff0c, r == 0xff0e, r == 0xff0f, r == 0xff1a:
+		return bidiCS
+	case unicode.In(r, unicode.Mn, unicode.Me):
+		return bidiNSM
+	case unicode.In(r, unicode.Cf, unicode.Cc):
+		return bidiBN
+	case r == 0x0c, r == 0x2028, unicode.Is(unicode.Zs, r):
+		return bidiWS
+	case unicode.In(r, rtlScripts...):
+		return bidiR
+	case unicode.In(r, arabicScripts...):
+		return bidiAL
+	case unicode.In(r, unicode.P, unicode.S, unicode.No):
+		return bidiON
+	}
+	return bidiL
+}
+
+// strongOf returns the direction a resolved type counts as next to neutrals,
+// bidiON for neutrals
+func strongOf(t bidiClass) bidiClass {
+	switch t {
+	case bidiL:
+		return bidiL
+	case bidiR, bidiEN, bidiAN:
+		return bidiR
+	}
+	return bidiON
+}
+
+// bidiLevels returns the embedding level of each rune of a line of text, odd
+// levels being right to left, and the level of the paragraph: 1 if its first
+// strong character is right to left, else 0. It follows the implicit rules of
+// UAX #9, bracket pairs included. Explicit embeddings, overrides and isolates
+// are ignored.
+func bidiLevels(text []rune) (levels []uint8, para uint8) {
+	n := len(text)
+	orig := make([]bidiClass, n)
+	for i, r := range text {
+		orig[i] = bidiClassOf(r)
+	}
+	for _, t := range orig {
+		if t == bidiL {
+			break
+		}
+		if t == bidiR || t == bidiAL {
+			para = 1
+			break
+		}
+	}
+	e := bidiL
+	if para == 1 {
+		e = bidiR
+	}
+	types := make([]bidiClass, n)
+	copy(types, orig)
+
+	// W1: marks and format characters take the type of the character before them
+	prev := e
+	for i, t := range types {
+		if t == bidiNSM || t == bidiBN {
+			types[i] = prev
+		} else {
+			prev = t
+		}
+	}
+	// W2, W3: numbers after Arabic letters are Arabic numbers, Arabic letters are right to left
+	strong := e
+	for i, t := range types {
+		switch t {
+		case bidiL, bidiR:
+			strong = t
+		case bidiAL:
+			strong = t
+			types[i] = bidiR
+		case bidiEN:
+			if strong == bidiAL {
+				types[i] = bidiAN
+			}
+		}
+	}
+	// W4: single separators between numbers of the same type
+	for i := 1; i+1 < n; i++ {
+		a, b := types[i-1], types[i+1]
+		switch types[i] {
+		case bidiES:
+			if a == bidiEN && b == bidiEN {
+				types[i] = bidiEN
+			}
+		case bidiCS:
+			if a == b && (a == bidiEN || a == bidiAN) {
+				types[i] = a
+			}
+		}
+	}
+	// W5: terminators next to European numbers
+	for i := 0; i < n; {
+		if types[i] != bidiET {
+			i++
+			continue
+		}
+		j := i
+		for j < n && types[j] == bidiET {
+			j++
+		}
+		if i > 0 && types[i-1] == bidiEN || j < n && types[j] == bidiEN {
+			for k := i; k < j; k++ {
+				types[k] = bidiEN
+			}
+		}
+		i = j
+	}
+	// W6, W7: other separators are neutrals, European numbers in left to right text are left to right
+	strong = e
+	for i, t := range types {
+		switch t {
+		case bidiES, bidiET, bidiCS:
+			types[i] = bidiON
+		case bidiL, bidiR:
+			strong = t
+		case bidiEN:
+			if strong == bidiL {
+				types[i] = bidiL
+			}
+		}
+	}
+	resolveBrackets(text, orig, types, e)
+	// N1, N2: neutrals between characters of the same direction take it, the others the paragraph's
+	for i := 0; i < n; {
+		if strongOf(types[i]) != bidiON {
+			i++
+			continue
+		}
+		j := i
+		for j < n && strongOf(types[j]) == bidiON {
+			j++
+		}
+		before, after := e, e
+		if i > 0 {
+			before = strongOf(types[i-1])
+		}
+		if j < n {
+			after = strongOf(types[j])
+		}
+		dir := e
+		if before == after {
+			dir = before
+		}
+		for k := i; k < j; k++ {
+			types[k] = dir
+		}
+		i = j
+	}
+	// I1, I2
+	levels = make([]uint8, n)
+	for i, t := range types {
+		lv := para
+		switch {
+		case para == 0 && t == bidiR:
+			lv = 1
+		case para == 0 && (t == bidiEN || t == bidiAN):
+			lv = 2
+		case para == 1 && t != bidiR:
+			lv = 2
+		}
+		levels[i] = lv
+	}
+	// L1: separators and the spaces before them or at the end of the line
+	// are at the paragraph level
+	trailing := true
+	for i := n - 1; i >= 0; i-- {
+		switch orig[i] {
+		case bidiS, bidiB:
+			levels[i] = para
+			trailing = true
+		case bidiWS, bidiBN:
+			if trailing {
+				levels[i] = para
+			}
+		default:
+			trailing = false
+		}
+	}
+	return levels, para
+}
+
+var closingBrackets = map[rune]rune{'(': ')', '[': ']', '{': '}'}
+
+// resolveBrackets applies rule N0 of UAX #9: paired brackets take the
+// direction of the text they enclose, e being the paragraph direction
+func resolveBrackets(text []rune, orig, types []bidiClass, e bidiClass) {
+	type opening struct {
+		pos   int
+		close rune
+	}
+	var stack []opening
+	var pairs [][2]int
+	for i, r := range text {
+		if types[i] != bidiON {
+			continue
+		}
+		if c, ok := closingBrackets[r]; ok {
+			if len(stack) == 63 {
+				break
+			}
+			stack = append(stack, opening{i, c})
+			continue
+		}
+		for j := len(stack) - 1; j >= 0; j-- {
+			if stack[j].close == r {
+				pairs = append(pairs, [2]int{stack[j].pos, i})
+				stack = stack[:j]
+				break
+			}
+		}
+	}
+	// Pairs are resolved in the order of their opening brackets
+	for i := 1; i < len(pairs); i++ {
+		for j := i; j > 0 && pairs[j][0] < pairs[j-1][0]; j-- {
+			pairs[j], pairs[j-1] = pairs[j-1], pairs[j]
+		}
+	}
+	for _, p := range pairs {
+		dir, opposite := bidiON, false
+		for k := p[0] + 1; k < p[1] && dir == bidiON; k++ {
+			switch s := strongOf(types[k]); {
+			case s == e:
+				dir = e
+			case s != bidiON:
+				opposite = true
+			}
+		}
+		if dir == bidiON && opposite {
+			// The brackets take the direction of the text before them
+			dir = e
+			for k := p[0] - 1; k >= 0; k-- {
+				if s := strongOf(types[k]); s != bidiON {
+					dir = s
+					break
+				}
+			}
+		}
+		if dir == bidiON {
+			continue
+		}
+		for _, b := range p {
+			types[b] = dir
+			for k := b + 1; k < len(text) && orig[k] == bidiNSM; k++ {
+				types[k] = dir
+			}
+		}
+	}
+}
+
+var mirrors = map[rune]rune{
+	'(': ')', ')': '(', '<': '>', '>': '<', '[': ']', ']': '[', '{': '}', '}': '{',
+	'«': '»', '»': '«', '‹': '›', '›': '‹', '≤': '≥', '≥': '≤',
+}
+
+// mirrored returns the character drawn for r in right to left text
+func mirrored(r rune) rune {
+	if m, ok := mirrors[r]; ok {
+		return m
+	}
+	return r
+}
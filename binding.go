@@ -0,0 +1,212 @@
+package gosui
+
+import (
+	"fmt"
+	"image"
+	"reflect"
+)
+
+// observer is a function called when observables change, shared by all the
+// observables it watches so that it runs once per batch
+type observer struct {
+	fn        func()
+	cancelled bool
+	queued    bool
+}
+
+// Observable is a value that calls its observers when it changes.
+// Like elements, observables must only be used on the UI goroutine.
+type Observable struct {
+	value     interface{}
+	observers []*observer
+}
+
+var (
+	batchDepth int
+	batched    []*observer // Observers to call at the end of the batch
+)
+
+// NewObservable creates an observable holding v
+func NewObservable(v interface{}) *Observable {
+	return &Observable{value: v}
+}
+
+// Get returns the value
+func (o *Observable) Get() interface{} {
+	return o.value
+}
+
+// String returns the value formatted with fmt.Sprint
+func (o *Observable) String() string {
+	return fmt.Sprint(o.value)
+}
+
+// Set changes the value and calls the observers if it's different.
+// In a Batch, they are called once at the end of it.
+func (o *Observable) Set(v interface{}) {
+	if equalValues(o.value, v) {
+		return
+	}
+	o.value = v
+	Batch(func() {
+		for _, ob := range o.observers {
+			if !ob.queued && !ob.cancelled {
+				ob.queued = true
+				batched = append(batched, ob)
+			}
+		}
+	})
+}
+
+// Observe calls fn with the value each time it changes, until cancel is called
+func (o *Observable) Observe(fn func(v interface{})) (cancel func()) {
+	return Watch(func() { fn(o.value) }, o)
+}
+
+func (o *Observable) addObserver(ob *observer) {
+	o.observers = append(o.observers, ob)
+}
+
+func (o *Observable) removeObserver(ob *observer) {
+	for i, x := range o.observers {
+		if x == ob {
+			o.observers = append(o.observers[:i], o.observers[i+1:]...)
+			return
+		}
+	}
+}
+
+// equalValues compares values with ==, values of types that can't be compared are always different
+func equalValues(a, b interface{}) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	ta, tb := reflect.TypeOf(a), reflect.TypeOf(b)
+	return ta == tb && ta.Comparable() && a == b
+}
+
+// Watch calls fn each time one of the observables changes, until cancel is called.
+// fn is called once per Batch, however many of them changed.
+func Watch(fn func(), obs ...*Observable) (cancel func()) {
+	ob := &observer{fn: fn}
+	for _, o := range obs {
+		o.addObserver(ob)
+	}
+	return func() {
+		ob.cancelled = true
+		for _, o := range obs {
+			o.removeObserver(ob)
+		}
+	}
+}
+
+// Batch runs fn, delaying the calls to the observers of the observables it
+// changes until it returns. Each observer is then called once.
+// Batches can be nested, the observers are called at the end of the outermost one.
+func Batch(fn func()) {
+	batchDepth++
+	defer func() {
+		batchDepth--
+		if batchDepth > 0 {
+			return
+		}
+		// Observers can change other observables, their observers are called in the same loop
+		batchDepth++
+		for i := 0; i < len(batched); i++ {
+			ob := batched[i]
+			ob.queued = false
+			if !ob.cancelled {
+				ob.fn()
+			}
+		}
+		batched = batched[:0]
+		batchDepth--
+	}()
+	fn()
+}
+
+// Derive creates an observable holding the result of fn, computed again when one of deps changes
+func Derive(fn func() interface{}, deps ...*Observable) *Observable {
+	d := NewObservable(fn())
+	Watch(func() { d.Set(fn()) }, deps...)
+	return d
+}
+
+// Bind calls apply with the value of the observable now and each time it changes,
+// invalidating the area of the element before and after. The binding lasts until
+// Unbind is called on the element or one of its ancestors.
+func Bind(e IElement, o *Observable, apply func(v interface{})) {
+	update := func() {
+		Invalidate(e)
+		apply(o.value)
+		Invalidate(e)
+	}
+	apply(o.value)
+	addBinding(e, Watch(update, o))
+}
+
+func addBinding(e IElement, cancel func()) {
+	b := e.BaseElement()
+	b.bindings = append(b.bindings, cancel)
+}
+
+// Unbind removes the bindings of the element and its descendants
+func Unbind(e IElement) {
+	b := e.BaseElement()
+	for _, cancel := range b.bindings {
+		cancel()
+	}
+	b.bindings = nil
+	if a, ok := e.(*AbstractElement); ok {
+		for _, c := range a.children {
+			Unbind(c)
+		}
+	}
+}
+
+// BindText makes the content of a text element follow the value of the
+// observable, formatted with fmt.Sprint. For text inputs the binding goes
+// both ways: edits set the observable to the new text.
+func BindText(e *ConcreteElement, o *Observable) {
+	Bind(e, o, func(v interface{}) { setContent(e, fmt.Sprint(v)) })
+	if h, ok := e.Handler.(*InputHandler); ok {
+		prev, bound := h.OnChange, true
+		addBinding(e, func() { bound = false })
+		h.OnChange = func(text string) {
+			if bound {
+				o.Set(text)
+			}
+			if prev != nil {
+				prev(text)
+			}
+		}
+	}
+}
+
+// setContent changes the text of a text element, measuring it so that its new area gets redrawn
+func setContent(e *ConcreteElement, text string) {
+	ts := e.TextShape()
+	if ts.Content == text {
+		return
+	}
+	if h, ok := e.Handler.(*InputHandler); ok {
+		h.SetText(text)
+		return
+	}
+	ts.Content = text
+	w, h := MeasureText(text, ts.Font)
+	e.Area.Min = image.Point{ts.origin.X, ts.origin.Y - h}
+	e.UpdateSize(w, h)
+}
+
+// BindFill makes the fill color of the element follow the observable, which holds a Color
+func BindFill(e IElement, o *Observable) {
+	b := e.BaseElement()
+	Bind(e, o, func(v interface{}) { b.FillColor = v.(Color) })
+}
+
+// BindVisible shows the element when the observable holds true and hides it
+// when it holds false
+func BindVisible(e IElement, o *Observable) {
+	Bind(e, o, func(v interface{}) { SetVisible(e, v.(bool)) })
+}
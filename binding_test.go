@@ -0,0 +1,82 @@
+package gosui
+
+import (
+	"image"
+
+	chk "launchpad.net/gocheck"
+)
+
+func (s *MySuite) TestObservables(c *chk.C) {
+	a, b := NewObservable(1), NewObservable("x")
+	var calls []string
+	cancel := Watch(func() { calls = append(calls, a.String()+b.String()) }, a, b)
+	sum := Derive(func() interface{} { return a.Get().(int) * 2 }, a)
+	var sums []interface{}
+	sum.Observe(func(v interface{}) { sums = append(sums, v) })
+
+	a.Set(2)
+	a.Set(2)
+	Batch(func() {
+		a.Set(3)
+		b.Set("y")
+		Batch(func() { a.Set(4) })
+		c.Check(calls, chk.HasLen, 1)
+	})
+	c.Check(calls, chk.DeepEquals, []string{"2x", "4y"})
+	c.Check(sums, chk.DeepEquals, []interface{}{4, 8})
+
+	cancel()
+	b.Set([]int{1})
+	b.Set([]int{1})
+	c.Check(calls, chk.HasLen, 2)
+}
+
+func (s *MySuite) TestBindings(c *chk.C) {
+	sc := NewScene()
+	box := NewAbstractElement(sc.Root(), MakeRect(0, 0, 200, 200))
+	label := NewTextElement(box, 10, 30, Font{Family: "Arial", Size: 14}, false)
+	r := NewRectElement(box, MakeRect(100, 100, 150, 150))
+	input := NewTextInputElement(box, 10, 80, Font{Family: "Arial", Size: 14})
+
+	count, fill, shown := NewObservable(0), NewObservable(Color{255, 0, 0, 255}), NewObservable(true)
+	name := NewObservable("Bob")
+	var changes []string
+	input.Input().OnChange = func(text string) { changes = append(changes, text) }
+	BindText(label, count)
+	BindFill(r, fill)
+	BindVisible(r, shown)
+	BindText(input, name)
+	c.Check(label.TextShape().Content, chk.Equals, "0")
+	c.Check(r.FillColor, chk.Equals, Color{255, 0, 0, 255})
+	c.Check(input.Input().Text(), chk.Equals, "Bob")
+	sc.RedrawDirty(new(DummyBackend))
+
+	// Only the area of the changed element is redrawn
+	fill.Set(Color{0, 0, 255, 255})
+	c.Check(r.FillColor, chk.Equals, Color{0, 0, 255, 255})
+	c.Check(sc.dirty, chk.DeepEquals, []image.Rectangle{r.Area})
+	sc.RedrawDirty(new(DummyBackend))
+
+	Batch(func() {
+		count.Set(12)
+		shown.Set(false)
+		fill.Set(Color{0, 255, 0, 255})
+	})
+	c.Check(label.TextShape().Content, chk.Equals, "12")
+	c.Check(r.Visible(), chk.Equals, false)
+	c.Check(sc.dirty, chk.HasLen, 2)
+
+	// Edits write back
+	sc.Focus(input)
+	sc.HandleChar(&CharEvent{Char: '!'})
+	c.Check(name.Get(), chk.Equals, "Bob!")
+	c.Check(changes, chk.DeepEquals, []string{"Bob!"})
+	c.Check(input.Input().Caret(), chk.Equals, 4)
+
+	Unbind(box)
+	count.Set(13)
+	sc.HandleChar(&CharEvent{Char: '?'})
+	c.Check(label.TextShape().Content, chk.Equals, "12")
+	c.Check(name.Get(), chk.Equals, "Bob!")
+	c.Check(changes, chk.DeepEquals, []string{"Bob!", "Bob!?"})
+}
@@ -0,0 +1,35 @@
+package gosui
+
+import "time"
+
+// Clock tells the time to a Scene. Windows use the system clock,
+// tests use a FakeClock to control time.
+type Clock interface {
+	Now() time.Time
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time { return time.Now() }
+
+// SystemClock is the real time Clock
+var SystemClock Clock = systemClock{}
+
+// FakeClock is a Clock whose time only changes when it's advanced
+type FakeClock struct {
+	now time.Time
+}
+
+// NewFakeClock creates a FakeClock set to t
+func NewFakeClock(t time.Time) *FakeClock {
+	return &FakeClock{now: t}
+}
+
+func (c *FakeClock) Now() time.Time {
+	return c.now
+}
+
+// Advance moves the time of the clock forward by d
+func (c *FakeClock) Advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
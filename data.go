@@ -0,0 +1,77 @@
+package gosui
+
+import "sync/atomic"
+
+// DataKey identifies a slot of data that a package or an algorithm attaches to
+// elements. Slots are indexed, not hashed: getting and setting data doesn't
+// allocate, except when storing values that aren't pointers in interfaces.
+type DataKey struct {
+	slot int
+	name string
+}
+
+var dataSlots int32 // Allocated atomically, packages may create keys from several goroutines
+
+// NewDataKey creates a new data slot, usually stored in a package variable.
+// The name is only used for debugging.
+func NewDataKey(name string) DataKey {
+	return DataKey{int(atomic.AddInt32(&dataSlots, 1)) - 1, name}
+}
+
+// String returns the name of the key
+func (k DataKey) String() string {
+	return k.name
+}
+
+// SetData attaches data to the element in the slot of key
+func (e *Element) SetData(key DataKey, data interface{}) {
+	if key.slot >= len(e.data) {
+		grown := make([]interface{}, atomic.LoadInt32(&dataSlots))
+		copy(grown, e.data)
+		e.data = grown
+	}
+	e.data[key.slot] = data
+}
+
+// Data returns the data of the element in the slot of key, nil if there's none
+func (e *Element) Data(key DataKey) interface{} {
+	if key.slot >= len(e.data) {
+		return nil
+	}
+	return e.data[key.slot]
+}
+
+// Marker marks elements visited by a run of an algorithm. Starting a new run
+// clears the marks of all elements at once, by changing the generation that
+// marks must have.
+type Marker struct {
+	slot int
+	gen  uint64
+}
+
+var markerSlots int32
+
+// NewMarker creates a marker with its own slot in elements
+func NewMarker() *Marker {
+	return &Marker{slot: int(atomic.AddInt32(&markerSlots, 1)) - 1, gen: 1}
+}
+
+// Reset clears the marks of all elements
+func (m *Marker) Reset() {
+	m.gen++
+}
+
+// Mark marks the element until the next Reset
+func (m *Marker) Mark(e *Element) {
+	if m.slot >= len(e.marks) {
+		grown := make([]uint64, atomic.LoadInt32(&markerSlots))
+		copy(grown, e.marks)
+		e.marks = grown
+	}
+	e.marks[m.slot] = m.gen
+}
+
+// Marked reports whether the element has been marked since the last Reset
+func (m *Marker) Marked(e *Element) bool {
+	return m.slot < len(e.marks) && e.marks[m.slot] == m.gen
+}
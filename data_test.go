@@ -0,0 +1,130 @@
+package gosui
+
+import (
+	"container/list"
+	"image"
+	"math/rand"
+	"sync"
+	"testing"
+
+	chk "launchpad.net/gocheck"
+)
+
+func (s *MySuite) TestElementData(c *chk.C) {
+	type info struct{ n int }
+	k1, k2 := NewDataKey("one"), NewDataKey("two")
+	e := new(Element)
+	c.Check(e.Data(k1), chk.IsNil)
+	e.SetData(k2, &info{2})
+	e.SetData(k1, "a")
+	c.Check(e.Data(k1), chk.Equals, "a")
+	c.Check(e.Data(k2).(*info).n, chk.Equals, 2)
+	c.Check(k2.String(), chk.Equals, "two")
+
+	m1, m2 := NewMarker(), NewMarker()
+	m2.Mark(e)
+	c.Check(m1.Marked(e), chk.Equals, false)
+	c.Check(m2.Marked(e), chk.Equals, true)
+	m1.Mark(e)
+	m2.Reset()
+	c.Check(m1.Marked(e), chk.Equals, true)
+	c.Check(m2.Marked(e), chk.Equals, false)
+
+	// Keys created at the same time get their own slots
+	keys := make([]DataKey, 8)
+	var wg sync.WaitGroup
+	for i := range keys {
+		wg.Add(1)
+		go func(i int) {
+			keys[i] = NewDataKey("concurrent")
+			wg.Done()
+		}(i)
+	}
+	wg.Wait()
+	slots := make(map[int]bool)
+	for _, k := range keys {
+		slots[k.slot] = true
+	}
+	c.Check(slots, chk.HasLen, len(keys))
+
+	// Each scene redraws its tree with its own marker
+	r1, r2 := NewScene().Root(), NewScene().Root()
+	c.Check(redrawMarker(r1), chk.Equals, redrawMarker(r1))
+	c.Check(redrawMarker(r1) != redrawMarker(r2), chk.Equals, true)
+}
+
+// benchTree creates the same random tree of n rects for each benchmark
+func benchTree(n int) *AbstractElement {
+	r := rand.New(rand.NewSource(1))
+	rnd := func() int { return r.Intn(500) }
+	root := NewRootElement()
+	l := []*AbstractElement{root}
+	for i := 1; i < n; i++ {
+		p := NewAbstractElement(l[r.Intn(len(l))], MakeRectWH(rnd(), rnd(), rnd(), rnd()))
+		NewRectElement(p, MakeRectWH(rnd(), rnd(), rnd(), rnd()))
+		l = append(l, p)
+	}
+	return root
+}
+
+// mapOverlapped finds elements to redraw like OverlappedAlgorithm did when its
+// marks were boxed bools in a map of each element, cleared in the whole tree
+// before each run. It's the baseline of BenchmarkRedrawArea.
+type mapOverlapped map[*Element]map[string]interface{}
+
+func (m mapOverlapped) init(root *AbstractElement) {
+	for o := root.AllConcreteDescns().Front(); o != nil; o = o.Next() {
+		e := &o.Value.(*ConcreteElement).Element
+		if m[e] == nil {
+			m[e] = make(map[string]interface{})
+		}
+		m[e]["addedToRedraw"] = false
+	}
+}
+
+func (m mapOverlapped) fetch(area image.Rectangle, e *AbstractElement, li *list.List) *list.List {
+	for _, oi := range e.children {
+		o := oi.BaseElement()
+		v := m[o]["addedToRedraw"]
+		if area.Overlaps(o.Area) && (v == nil || !v.(bool)) {
+			if oi.IsConcrete() {
+				m[o]["addedToRedraw"] = true
+				li.PushBack(oi)
+			} else {
+				li = m.fetch(area, oi.(*AbstractElement), li)
+			}
+		}
+	}
+	return li
+}
+
+func benchAreas(b *testing.B) []image.Rectangle {
+	r := rand.New(rand.NewSource(2))
+	areas := make([]image.Rectangle, 100)
+	for i := range areas {
+		areas[i] = MakeRectWH(r.Intn(500), r.Intn(500), r.Intn(100), r.Intn(100))
+	}
+	b.ResetTimer()
+	b.ReportAllocs()
+	return areas
+}
+
+func BenchmarkRedrawAreaMapData(b *testing.B) {
+	root := benchTree(1000)
+	m := make(mapOverlapped)
+	areas := benchAreas(b)
+	for i := 0; i < b.N; i++ {
+		m.init(root)
+		m.fetch(areas[i%len(areas)], root, list.New())
+	}
+}
+
+func BenchmarkRedrawArea(b *testing.B) {
+	root := benchTree(1000)
+	areas := benchAreas(b)
+	marker := NewMarker()
+	for i := 0; i < b.N; i++ {
+		alg := NewOverlappedAlgorithm(marker)
+		alg.fetchOverlappingConcreteElems(areas[i%len(areas)], root, list.New())
+	}
+}
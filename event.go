@@ -1,6 +1,10 @@
 package gosui
 
-import "image"
+import (
+	"image"
+	"strconv"
+	"strings"
+)
 
 const (
 	MouseButtonLeft   = 0
@@ -9,12 +13,102 @@ const (
 	EventPress        = 0
 	EventRelease      = 1
 	EventRepeat       = 2
+	EventMove         = 3
+	EventScroll       = 4
+)
+
+// Key identifies a keyboard key.
+// Printable keys use the code of their unshifted US-layout character
+// (letters are uppercase), the others start from 256
+type Key int
+
+const (
+	KeyUnknown   Key = -1
+	KeySpace     Key = ' '
+	Key0         Key = '0'
+	Key9         Key = '9'
+	KeyA         Key = 'A'
+	KeyY         Key = 'Y'
+	KeyZ         Key = 'Z'
+	KeyEscape    Key = 256
+	KeyEnter     Key = 257
+	KeyTab       Key = 258
+	KeyBackspace Key = 259
+	KeyInsert    Key = 260
+	KeyDelete    Key = 261
+	KeyRight     Key = 262
+	KeyLeft      Key = 263
+	KeyDown      Key = 264
+	KeyUp        Key = 265
+	KeyPageUp    Key = 266
+	KeyPageDown  Key = 267
+	KeyHome      Key = 268
+	KeyEnd       Key = 269
+	KeyF1        Key = 290
+	KeyF2        Key = 291
+	KeyF12       Key = 301
 )
 
 type Modifiers struct {
 	Control, Shift, Alt, Super bool
 }
 
+// Shortcut is a key combined with modifiers, like Ctrl+S
+type Shortcut struct {
+	Key Key
+	Mod Modifiers
+}
+
+var keyNames = map[Key]string{
+	KeySpace:     "Space",
+	KeyEscape:    "Esc",
+	KeyEnter:     "Enter",
+	KeyTab:       "Tab",
+	KeyBackspace: "Backspace",
+	KeyInsert:    "Ins",
+	KeyDelete:    "Del",
+	KeyRight:     "Right",
+	KeyLeft:      "Left",
+	KeyDown:      "Down",
+	KeyUp:        "Up",
+	KeyPageUp:    "PgUp",
+	KeyPageDown:  "PgDn",
+	KeyHome:      "Home",
+	KeyEnd:       "End",
+}
+
+// String returns the name of the key, like "A", "Enter" or "F5"
+func (k Key) String() string {
+	if name, ok := keyNames[k]; ok {
+		return name
+	}
+	switch {
+	case k >= KeyF1 && k <= KeyF12:
+		return "F" + strconv.Itoa(int(k-KeyF1)+1)
+	case k > KeySpace && k < KeyEscape:
+		return string(rune(k))
+	}
+	return "?"
+}
+
+// String returns the label of the shortcut, like "Ctrl+Shift+S"
+func (s Shortcut) String() string {
+	var parts []string
+	if s.Mod.Control {
+		parts = append(parts, "Ctrl")
+	}
+	if s.Mod.Alt {
+		parts = append(parts, "Alt")
+	}
+	if s.Mod.Shift {
+		parts = append(parts, "Shift")
+	}
+	if s.Mod.Super {
+		parts = append(parts, "Super")
+	}
+	return strings.Join(append(parts, s.Key.String()), "+")
+}
+
 type MouseButton int
 
 type EventAction int
@@ -23,24 +117,78 @@ type mouseHandler interface {
 	OnMouseEvent(*MouseEvent) bool
 }
 
+// keyHandler is implemented by handlers that want key events,
+// they are delivered to the focused element then bubble up to its ancestors
+type keyHandler interface {
+	OnKeyEvent(*KeyEvent) bool
+}
+
+// charHandler is implemented by handlers that want text input
+type charHandler interface {
+	OnCharEvent(*CharEvent) bool
+}
+
+// hoverHandler is notified when the mouse cursor enters or leaves the element
+type hoverHandler interface {
+	OnMouseEnter()
+	OnMouseLeave()
+}
+
+// focusHandler is notified when the element gains or loses the keyboard focus
+type focusHandler interface {
+	OnFocus()
+	OnBlur()
+}
+
+// enableHandler is notified when the element or one of its ancestors is
+// enabled or disabled, with whether the element is now enabled in the tree
+type enableHandler interface {
+	OnEnabledChange(enabled bool)
+}
+
+// focusable is implemented by handlers of elements that can take the keyboard focus
+type focusable interface {
+	Focusable() bool
+}
+
 type MouseEvent struct {
 	Pos    image.Point
 	Button MouseButton
 	Mod    Modifiers
 	Action EventAction
+	// Scroll is the wheel offset of EventScroll events, Y is positive when the wheel turns up
+	Scroll image.Point
+}
+
+// KeyEvent is a key press, release or repeat
+type KeyEvent struct {
+	Key    Key
+	Mod    Modifiers
+	Action EventAction
+}
+
+// CharEvent carries a character typed by the user
+type CharEvent struct {
+	Char rune
 }
 
 func HandleMouse(evt *MouseEvent, e *AbstractElement) {
 	for _, child := range e.children {
-		if !evt.Pos.In(child.BaseElement().Area) {
-			continue
-		}
-		propagate := true
-		if handler, ok := child.BaseElement().Handler.(mouseHandler); ok {
-			propagate = handler.OnMouseEvent(evt)
-		}
-		if !child.IsConcrete() && propagate {
-			HandleMouse(evt, child.(*AbstractElement))
-		}
+		dispatchMouse(evt, child)
+	}
+}
+
+// dispatchMouse sends the event to the element and, unless its handler stops it,
+// to its descendants. Hidden and disabled elements are skipped with their descendants.
+func dispatchMouse(evt *MouseEvent, e IElement) {
+	if b := e.BaseElement(); !evt.Pos.In(b.Area) || b.hidden || b.disabled {
+		return
+	}
+	propagate := true
+	if handler, ok := e.BaseElement().Handler.(mouseHandler); ok {
+		propagate = handler.OnMouseEvent(evt)
+	}
+	if !e.IsConcrete() && propagate {
+		HandleMouse(evt, e.(*AbstractElement))
 	}
 }
@@ -0,0 +1,371 @@
+package gosui
+
+import (
+	"image"
+	"math"
+	"sort"
+)
+
+var (
+	tabIndexKey   = NewDataKey("tab index")
+	focusScopeKey = NewDataKey("focus scope")
+	scopeFocusKey = NewDataKey("scope focus") // Element last focused in a scope
+)
+
+// SetTabIndex sets the place of an element in the keyboard navigation.
+// Like in HTML, elements with a positive index come first by increasing index,
+// then the ones with the default index 0 in tree order. Elements with a
+// negative index are skipped by Tab and the arrows, they are still focused by
+// clicks and Focus. A focus scope with a negative index is skipped as a whole.
+func SetTabIndex(e IElement, i int) {
+	e.BaseElement().SetData(tabIndexKey, i)
+}
+
+// TabIndex returns the tab index of the element, see SetTabIndex
+func TabIndex(e IElement) int {
+	i, _ := e.BaseElement().Data(tabIndexKey).(int)
+	return i
+}
+
+// FocusScope tells how the keyboard moves the focus among the elements of a container
+type FocusScope int
+
+const (
+	// ScopeNone makes the elements of the container part of the scope of its parent
+	ScopeNone FocusScope = iota
+	// ScopeGroup orders the elements of the container with their own tab
+	// indexes, the container taking the place of its tab index in the order of
+	// its parent. The arrows don't leave the container.
+	ScopeGroup
+	// ScopeCycle is a group where Tab also stays inside, wrapping around
+	ScopeCycle
+	// ScopeOnce makes the container a single Tab stop, like toolbars and radio
+	// groups: Tab enters at the element focused last in it, or the first one,
+	// and the arrows move inside.
+	ScopeOnce
+)
+
+// SetFocusScope makes a container a focus scope
+func SetFocusScope(e IElement, scope FocusScope) {
+	e.BaseElement().SetData(focusScopeKey, scope)
+}
+
+// FocusScopeOf returns the focus scope of the element, see SetFocusScope
+func FocusScopeOf(e IElement) FocusScope {
+	sc, _ := e.BaseElement().Data(focusScopeKey).(FocusScope)
+	return sc
+}
+
+// scopeOf returns the innermost focus scope containing e, among the ones
+// accepted by match, or nil
+func scopeOf(e IElement, match func(FocusScope) bool) IElement {
+	for p := e.BaseElement().parent; p != nil; p = p.parent {
+		if sc := FocusScopeOf(p); sc != ScopeNone && match(sc) {
+			return p
+		}
+	}
+	return nil
+}
+
+// rememberFocus records e as the element last focused in the scopes containing it
+func rememberFocus(e IElement) {
+	for p := e.BaseElement().parent; p != nil; p = p.parent {
+		if FocusScopeOf(p) != ScopeNone {
+			p.SetData(scopeFocusKey, e)
+		}
+	}
+}
+
+// tabEntry is an element of the Tab order of a scope, either a focusable
+// element or a nested scope ordered as a whole
+type tabEntry struct {
+	e     IElement
+	scope FocusScope // Scope of e if the entry stands for the elements inside it
+}
+
+type byTabIndex []tabEntry
+
+func (l byTabIndex) Len() int      { return len(l) }
+func (l byTabIndex) Swap(i, j int) { l[i], l[j] = l[j], l[i] }
+func (l byTabIndex) Less(i, j int) bool {
+	return tabRank(l[i].e) < tabRank(l[j].e)
+}
+
+// tabRank puts positive tab indexes before the default one
+func tabRank(e IElement) int {
+	if i := TabIndex(e); i > 0 {
+		return i
+	}
+	return maxInt
+}
+
+// tabEntries appends the entries of the Tab order of the scope c to l,
+// in tree order
+func tabEntries(e IElement, l []tabEntry, c IElement) []tabEntry {
+	b := e.BaseElement()
+	if b.hidden || b.disabled || TabIndex(e) < 0 {
+		return l
+	}
+	if sc := FocusScopeOf(e); sc != ScopeNone && e != c {
+		return append(l, tabEntry{e, sc})
+	}
+	if isFocusable(e) {
+		l = append(l, tabEntry{e, ScopeNone})
+	}
+	if a, ok := e.(*AbstractElement); ok {
+		for _, child := range a.children {
+			l = tabEntries(child, l, c)
+		}
+	}
+	return l
+}
+
+// tabStops returns the elements Tab goes through in the scope c, in order
+func tabStops(c IElement) (l []IElement) {
+	entries := tabEntries(c, nil, c)
+	sort.Stable(byTabIndex(entries))
+	for _, t := range entries {
+		switch t.scope {
+		case ScopeNone:
+			l = append(l, t.e)
+		case ScopeOnce:
+			if f := scopeEntry(t.e); f != nil {
+				l = append(l, f)
+			}
+		default:
+			l = append(l, tabStops(t.e)...)
+		}
+	}
+	return l
+}
+
+// scopeEntry returns the element Tab focuses when entering the scope c
+func scopeEntry(c IElement) IElement {
+	if f, ok := c.BaseElement().Data(scopeFocusKey).(IElement); ok &&
+		Within(f, c) && isFocusable(f) && TabIndex(f) >= 0 {
+		return f
+	}
+	if l := tabStops(c); len(l) > 0 {
+		return l[0]
+	}
+	return nil
+}
+
+// navScopes returns the containers the keyboard moves the focus in: the
+// innermost scope of the focused element accepted by match, or the open modal
+// popup and the ones above it, or the root
+func (s *Scene) navScopes(match func(FocusScope) bool) []IElement {
+	if s.focused != nil {
+		if c := scopeOf(s.focused, match); c != nil && !s.blockedByModal(c) {
+			return []IElement{c}
+		}
+	}
+	if m := s.TopModal(); m != nil {
+		var l []IElement
+		for _, p := range s.popups[s.popupIndex(m):] {
+			l = append(l, p.AbstractElement)
+		}
+		return l
+	}
+	return []IElement{s.root}
+}
+
+// FocusNext moves the focus to the next element in the Tab order, or the
+// previous one if backward is true. The order wraps around in the scene, in
+// modal popups and in ScopeCycle containers.
+func (s *Scene) FocusNext(backward bool) {
+	var l []IElement
+	cycle := func(sc FocusScope) bool { return sc == ScopeCycle }
+	for _, c := range s.navScopes(cycle) {
+		l = append(l, tabStops(c)...)
+	}
+	if len(l) == 0 {
+		return
+	}
+	i := -1
+	for j, o := range l {
+		if o == s.focused {
+			i = j
+		}
+	}
+	switch {
+	case i < 0 && backward:
+		i = len(l) - 1
+	case i < 0:
+		i = 0
+	case backward:
+		i = (i - 1 + len(l)) % len(l)
+	default:
+		i = (i + 1) % len(l)
+	}
+	s.Focus(l[i])
+}
+
+// navigables appends the elements of e that the arrows can focus to l,
+// including the ones in nested scopes
+func navigables(e IElement, l []IElement) []IElement {
+	b := e.BaseElement()
+	if b.hidden || b.disabled || TabIndex(e) < 0 {
+		return l
+	}
+	if isFocusable(e) {
+		l = append(l, e)
+	}
+	if a, ok := e.(*AbstractElement); ok {
+		for _, c := range a.children {
+			l = navigables(c, l)
+		}
+	}
+	return l
+}
+
+// focusArea returns the area of an element for the keyboard navigation
+func focusArea(e IElement) image.Rectangle {
+	if a := e.BaseElement().Area; !a.Empty() {
+		return a
+	}
+	return Bounds(e)
+}
+
+// FocusToward moves the focus to the nearest element in the direction of an
+// arrow key, KeyLeft, KeyRight, KeyUp or KeyDown, without leaving the focus
+// scope of the focused element. Elements are compared by the distance
+// between their areas, those that aren't aligned with the focused element
+// being farther. It reports whether the focus moved.
+func (s *Scene) FocusToward(arrow Key) bool {
+	if s.focused == nil {
+		return false
+	}
+	var l []IElement
+	all := func(FocusScope) bool { return true }
+	for _, c := range s.navScopes(all) {
+		l = navigables(c, l)
+	}
+	from := focusArea(s.focused)
+	var best IElement
+	bestDist, bestOff := 0, 0
+	for _, e := range l {
+		if e == s.focused {
+			continue
+		}
+		dist, off, ok := spatialDistance(from, focusArea(e), arrow)
+		if ok && (best == nil || dist < bestDist || dist == bestDist && off < bestOff) {
+			best, bestDist, bestOff = e, dist, off
+		}
+	}
+	if best == nil {
+		return false
+	}
+	s.Focus(best)
+	return true
+}
+
+// spatialDistance returns how far to is from the area from in the direction
+// of the arrow, and the offset between their centers across it to break ties.
+// ok is false if to isn't in that direction.
+func spatialDistance(from, to image.Rectangle, arrow Key) (dist, off int, ok bool) {
+	// Rotate the areas so that the arrow points right
+	rot := func(r image.Rectangle) image.Rectangle {
+		switch arrow {
+		case KeyLeft:
+			return MakeRect(-r.Max.X, r.Min.Y, -r.Min.X, r.Max.Y)
+		case KeyDown:
+			return MakeRect(r.Min.Y, r.Min.X, r.Max.Y, r.Max.X)
+		case KeyUp:
+			return MakeRect(-r.Max.Y, r.Min.X, -r.Min.Y, r.Max.X)
+		}
+		return r
+	}
+	from, to = rot(from), rot(to)
+	if to.Min.X <= from.Min.X || to.Max.X <= from.Max.X {
+		return 0, 0, false
+	}
+	along := to.Min.X - from.Max.X
+	if along < 0 {
+		along = 0
+	}
+	// The gap between the areas across the direction, 0 if they're aligned
+	across := 0
+	if to.Max.Y <= from.Min.Y {
+		across = from.Min.Y - to.Max.Y
+	} else if to.Min.Y >= from.Max.Y {
+		across = to.Min.Y - from.Max.Y
+	}
+	off = (to.Min.Y + to.Max.Y) - (from.Min.Y + from.Max.Y)
+	if off < 0 {
+		off = -off
+	}
+	return along + 2*across, off, true
+}
+
+// FocusRing is the look of the ring drawn around the focused element when the
+// focus was moved with the keyboard
+type FocusRing struct {
+	Color  Color
+	Width  int // A width of 0 disables the ring
+	Offset int // Space between the element and the ring
+}
+
+// DefaultFocusRing is the focus ring of new scenes
+var DefaultFocusRing = FocusRing{Color: Color{0x3b, 0x82, 0xf6, 0xff}, Width: 2, Offset: 1}
+
+// SetFocusRing changes the look of the focus ring
+func (s *Scene) SetFocusRing(r FocusRing) {
+	s.ringStyle = r
+	s.updateFocusRing()
+}
+
+// FocusVisible reports whether the focus ring is shown, that is whether an
+// element has the focus and it was given by the keyboard
+func (s *Scene) FocusVisible() bool {
+	return s.focusVisible && s.ring != nil
+}
+
+// focusShape returns the area of the element and the corner radii of the
+// rectangle that covers it: the element itself or one of its descendants,
+// like the background of a button
+func focusShape(e IElement) (area image.Rectangle, radii [4]int) {
+	area = focusArea(e)
+	li := visibleConcreteDescns(e)
+	for o := li.Front(); o != nil; o = o.Next() {
+		c := o.Value.(*ConcreteElement)
+		if r, ok := c.shape.(*RectShape); ok && c.Area == area {
+			return area, r.cornerRadiis
+		}
+	}
+	return area, radii
+}
+
+// updateFocusRing places the ring around the focused element, invalidating
+// the areas of the ring when it changes
+func (s *Scene) updateFocusRing() {
+	var ring *ConcreteElement
+	st := s.ringStyle
+	if e := s.focused; e != nil && s.focusVisible && st.Width > 0 && e.BaseElement().VisibleInTree() {
+		area, radii := focusShape(e)
+		d := st.Offset + st.Width
+		shape := new(RectShape)
+		for i, r := range radii {
+			if r > 0 {
+				// The ring follows the rounded corners at a constant distance
+				shape.cornerRadiis[i] = r + d
+			}
+		}
+		ring = &ConcreteElement{shape: shape}
+		ring.Area = area.Inset(-d)
+		ring.StrokeWidth, ring.StrokeColor = st.Width, st.Color
+		ring.layer, ring.zIndex = e.BaseElement().layer, math.MaxFloat32
+	}
+	if s.ring != nil && (ring == nil || !sameRing(s.ring, ring)) {
+		s.InvalidateArea(s.ring.Area)
+	}
+	if ring != nil && (s.ring == nil || !sameRing(s.ring, ring)) {
+		s.InvalidateArea(ring.Area)
+	}
+	s.ring = ring
+}
+
+func sameRing(a, b *ConcreteElement) bool {
+	return a.Area == b.Area && a.Paint == b.Paint && a.layer == b.layer &&
+		*a.shape.(*RectShape) == *b.shape.(*RectShape)
+}
@@ -0,0 +1,151 @@
+package gosui
+
+import (
+	"fmt"
+	"image"
+
+	chk "launchpad.net/gocheck"
+)
+
+// ringBackend records the rectangles drawn with a stroke
+type ringBackend struct {
+	DummyBackend
+	rings []string
+}
+
+func (b *ringBackend) DrawRect(rect image.Rectangle, radii [4]int, paint Paint) {
+	if paint.StrokeWidth > 0 {
+		b.rings = append(b.rings, fmt.Sprint(rect, radii))
+	}
+}
+
+func (b *ringBackend) DrawElementsInArea(l DrawPriorityList, area image.Rectangle) {
+	for _, o := range l {
+		o.Draw(b)
+	}
+}
+
+func newFocusable(parent *AbstractElement, area image.Rectangle) *ConcreteElement {
+	e := NewRectElement(parent, area)
+	e.Handler = &recHandler{focus: true}
+	return e
+}
+
+func (s *MySuite) TestTabOrder(c *chk.C) {
+	sc := NewScene()
+	a := newFocusable(sc.Root(), MakeRect(0, 0, 10, 10))
+	b := newFocusable(sc.Root(), MakeRect(20, 0, 30, 10))
+	bar := NewAbstractElement(sc.Root(), MakeRect(0, 20, 100, 30))
+	SetFocusScope(bar, ScopeOnce)
+	t1 := newFocusable(bar, MakeRect(0, 20, 10, 30))
+	t2 := newFocusable(bar, MakeRect(20, 20, 30, 30))
+	d := newFocusable(sc.Root(), MakeRect(0, 40, 10, 50))
+	SetTabIndex(d, 1)
+	SetTabIndex(b, -1)
+	var order []IElement
+	tab := func(shift bool) IElement {
+		sc.HandleKey(&KeyEvent{Key: KeyTab, Mod: Modifiers{Shift: shift}})
+		order = append(order, sc.Focused())
+		return sc.Focused()
+	}
+
+	for i := 0; i < 4; i++ {
+		tab(false)
+	}
+	c.Check(order, chk.DeepEquals, []IElement{d, a, t1, d})
+	c.Check(TabIndex(d), chk.Equals, 1)
+
+	// Tab comes back to the element focused last in the toolbar
+	tab(true)
+	sc.HandleKey(&KeyEvent{Key: KeyRight})
+	c.Check(sc.Focused(), chk.Equals, IElement(t2))
+	c.Check(sc.FocusToward(KeyDown), chk.Equals, false)
+	tab(false)
+	c.Check(tab(true), chk.Equals, IElement(t2))
+	sc.Focus(t1)
+	c.Check(tab(false), chk.Equals, IElement(d))
+	c.Check(tab(true), chk.Equals, IElement(t1))
+
+	// Tab stays in cycling scopes
+	SetFocusScope(bar, ScopeCycle)
+	c.Check(tab(false), chk.Equals, IElement(t2))
+	c.Check(tab(false), chk.Equals, IElement(t1))
+	SetFocusScope(bar, ScopeGroup)
+	c.Check(tab(false), chk.Equals, IElement(t2))
+	c.Check(tab(false), chk.Equals, IElement(d))
+}
+
+func (s *MySuite) TestArrowNavigation(c *chk.C) {
+	sc := NewScene()
+	a := newFocusable(sc.Root(), MakeRect(0, 0, 40, 20))
+	b := newFocusable(sc.Root(), MakeRect(50, 0, 90, 20))
+	cc := newFocusable(sc.Root(), MakeRect(0, 30, 40, 50))
+	d := newFocusable(sc.Root(), MakeRect(50, 30, 90, 50))
+	wide := newFocusable(sc.Root(), MakeRect(0, 100, 200, 120))
+	far := newFocusable(sc.Root(), MakeRect(300, 60, 340, 80))
+	sc.Focus(a)
+	move := func(k Key) IElement {
+		sc.HandleKey(&KeyEvent{Key: k})
+		return sc.Focused()
+	}
+
+	c.Check(move(KeyRight), chk.Equals, IElement(b))
+	c.Check(move(KeyDown), chk.Equals, IElement(d))
+	c.Check(move(KeyLeft), chk.Equals, IElement(cc))
+	c.Check(move(KeyLeft), chk.Equals, IElement(cc))
+	c.Check(move(KeyUp), chk.Equals, IElement(a))
+	sc.Focus(d)
+	// Elements off the row or the column are reached too
+	c.Check(move(KeyRight), chk.Equals, IElement(far))
+	c.Check(move(KeyDown), chk.Equals, IElement(wide))
+
+	// Arrows with modifiers don't move the focus
+	sc.HandleKey(&KeyEvent{Key: KeyUp, Mod: Modifiers{Shift: true}})
+	c.Check(sc.Focused(), chk.Equals, IElement(wide))
+	SetEnabled(d, false)
+	c.Check(move(KeyUp), chk.Equals, IElement(cc))
+}
+
+func (s *MySuite) TestFocusRing(c *chk.C) {
+	sc := NewScene()
+	a := newFocusable(sc.Root(), MakeRect(10, 10, 50, 30))
+	a.RectShape().SetAllCornerRadiusTo(4)
+	box := NewAbstractElement(sc.Root(), MakeRect(10, 40, 50, 60))
+	box.Handler = &recHandler{focus: true}
+	NewRectElement(box, MakeRect(10, 40, 50, 60))
+	be := new(ringBackend)
+	sc.RedrawDirty(be)
+
+	// Clicks don't show the ring
+	sc.HandleMouse(&MouseEvent{Pos: image.Pt(20, 20), Action: EventPress})
+	c.Check(sc.Focused(), chk.Equals, IElement(a))
+	c.Check(sc.FocusVisible(), chk.Equals, false)
+	sc.RedrawDirty(be)
+	c.Check(be.rings, chk.HasLen, 0)
+
+	sc.HandleKey(&KeyEvent{Key: KeyTab})
+	c.Check(sc.FocusVisible(), chk.Equals, true)
+	sc.HandleKey(&KeyEvent{Key: KeyTab, Mod: Modifiers{Shift: true}})
+	sc.RedrawDirty(be)
+	c.Check(be.rings, chk.DeepEquals, []string{"(7,7)-(53,33) [7 7 7 7]"})
+
+	// The ring follows the element and takes the corners of its background
+	be.rings = nil
+	sc.Focus(box)
+	box.MoveBy(0, 10)
+	sc.RedrawDirty(be)
+	c.Check(be.rings, chk.DeepEquals, []string{"(7,47)-(53,73) [0 0 0 0]"})
+	be.rings = nil
+	sc.SetFocusRing(FocusRing{Color: Color{255, 0, 0, 255}, Width: 1})
+	sc.RedrawDirty(be)
+	c.Check(be.rings, chk.DeepEquals, []string{"(9,49)-(51,71) [0 0 0 0]"})
+
+	// Dirty areas away from the ring don't draw it
+	be.rings = nil
+	sc.InvalidateArea(MakeRect(100, 100, 110, 110))
+	sc.RedrawDirty(be)
+	c.Check(be.rings, chk.HasLen, 0)
+	sc.Focus(nil)
+	c.Check(sc.FocusVisible(), chk.Equals, false)
+	c.Check(sc.NeedsRedraw(), chk.Equals, true)
+}
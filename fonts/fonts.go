@@ -0,0 +1,426 @@
+// Package fonts loads font files and finds the faces to draw text with.
+//
+// A Registry holds the faces of TrueType and OpenType files. It matches the
+// family and style of a gosui Font to the closest face, and splits text into
+// runs of faces that have its glyphs, going through a chain of fallback
+// families for the missing ones, like CJK or emoji fonts.
+// Default is the registry shared by the backends, it also measures text:
+//
+//	fonts.Default.AddDir("assets/fonts")
+//	fonts.Default.SetFallback("Noto Sans CJK JP", "Noto Color Emoji")
+//	gs.Measurer = fonts.Default
+package fonts
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/sfnt"
+	"golang.org/x/image/math/fixed"
+
+	gs "github.com/phaikawl/gosui"
+)
+
+// Default is the registry used by the backends
+var Default = NewRegistry()
+
+// Face is a font of a file, with its family and style
+type Face struct {
+	Family string
+	Style  string // Subfamily, like "Bold Italic"
+	Weight int    // From 100 to 900, 400 is regular and 700 bold
+	Italic bool   // Italic or oblique
+	Path   string // File the face was loaded from, "" if it was given as bytes
+	Index  int    // Index of the face in its collection file
+
+	data []byte
+	font *sfnt.Font
+
+	mu     sync.Mutex // Guards buf and glyphs
+	buf    sfnt.Buffer
+	glyphs map[rune]sfnt.GlyphIndex
+}
+
+func (f *Face) String() string {
+	return f.Family + " " + f.Style
+}
+
+// Data returns the content of the file of the face, for backends that load
+// faces themselves
+func (f *Face) Data() []byte {
+	return f.data
+}
+
+// glyph returns the glyph of a rune, 0 if the face doesn't have it.
+// It must be called with mu held.
+func (f *Face) glyph(r rune) sfnt.GlyphIndex {
+	g, ok := f.glyphs[r]
+	if !ok {
+		g, _ = f.font.GlyphIndex(&f.buf, r)
+		f.glyphs[r] = g
+	}
+	return g
+}
+
+// HasGlyph reports whether the face can draw the rune
+func (f *Face) HasGlyph(r rune) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.glyph(r) != 0
+}
+
+// advance returns the width of the text at a size in pixels, with kerning
+func (f *Face) advance(text string, size int) (w fixed.Int26_6) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	ppem := fixed.I(size)
+	var prev sfnt.GlyphIndex
+	for _, r := range text {
+		g := f.glyph(r)
+		if prev != 0 {
+			if k, err := f.font.Kern(&f.buf, prev, g, ppem, font.HintingNone); err == nil {
+				w += k
+			}
+		}
+		if a, err := f.font.GlyphAdvance(&f.buf, g, ppem, font.HintingNone); err == nil {
+			w += a
+		}
+		prev = g
+	}
+	return w
+}
+
+// Advance returns the width of the text drawn with the face at a size in pixels
+func (f *Face) Advance(text string, size int) int {
+	return f.advance(text, size).Ceil()
+}
+
+// Metrics returns the ascent and descent of the face at a size in pixels
+func (f *Face) Metrics(size int) (ascent, descent int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	m, err := f.font.Metrics(&f.buf, fixed.I(size), font.HintingNone)
+	if err != nil {
+		return size, 0
+	}
+	return m.Ascent.Ceil(), m.Descent.Ceil()
+}
+
+// Registry holds font faces by family. It's safe for concurrent use.
+type Registry struct {
+	mu       sync.RWMutex
+	families map[string][]*Face // Keyed by lowercase family
+	fallback []string
+}
+
+// NewRegistry creates an empty registry
+func NewRegistry() *Registry {
+	return &Registry{families: make(map[string][]*Face)}
+}
+
+// Add loads the faces of a font file given as bytes, like an embedded one.
+// Collections add all their faces.
+func (r *Registry) Add(data []byte) ([]*Face, error) {
+	return r.add(data, "")
+}
+
+// AddFile loads the faces of a font file
+func (r *Registry) AddFile(path string) ([]*Face, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return r.add(data, path)
+}
+
+// fontExts are the extensions of the files loaded by AddDir
+var fontExts = map[string]bool{".ttf": true, ".otf": true, ".ttc": true, ".otc": true}
+
+// AddDir loads the font files of a directory and its subdirectories, found by
+// their extension. It returns the first error, after loading the other files.
+func (r *Registry) AddDir(dir string) (first error) {
+	filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err == nil && !info.IsDir() && fontExts[strings.ToLower(filepath.Ext(path))] {
+			_, err = r.AddFile(path)
+		}
+		if err != nil && first == nil {
+			first = err
+		}
+		return nil
+	})
+	return first
+}
+
+func (r *Registry) add(data []byte, path string) ([]*Face, error) {
+	c, err := sfnt.ParseCollection(data)
+	if err != nil {
+		return nil, fmt.Errorf("fonts: %s: %v", nameOr(path), err)
+	}
+	var faces []*Face
+	for i := 0; i < c.NumFonts(); i++ {
+		fnt, err := c.Font(i)
+		if err != nil {
+			return nil, fmt.Errorf("fonts: %s: %v", nameOr(path), err)
+		}
+		f := &Face{Path: path, Index: i, data: data, font: fnt, glyphs: make(map[rune]sfnt.GlyphIndex)}
+		f.Family = f.name(sfnt.NameIDTypographicFamily, sfnt.NameIDFamily)
+		f.Style = f.name(sfnt.NameIDTypographicSubfamily, sfnt.NameIDSubfamily)
+		if f.Family == "" {
+			return nil, fmt.Errorf("fonts: %s: no family name", nameOr(path))
+		}
+		f.Weight, f.Italic = styleOf(data, i, f.Style)
+		faces = append(faces, f)
+	}
+	r.mu.Lock()
+	for _, f := range faces {
+		k := strings.ToLower(f.Family)
+		r.families[k] = append(r.families[k], f)
+	}
+	r.mu.Unlock()
+	return faces, nil
+}
+
+func nameOr(path string) string {
+	if path == "" {
+		return "font data"
+	}
+	return path
+}
+
+// name returns the first of the names of the face that is set
+func (f *Face) name(ids ...sfnt.NameID) string {
+	for _, id := range ids {
+		if s, err := f.font.Name(&f.buf, id); err == nil && s != "" {
+			return s
+		}
+	}
+	return ""
+}
+
+// styleOf returns the weight and slant of the face i of a file, read from
+// its OS/2 table, or guessed from its subfamily if it has none
+func styleOf(data []byte, i int, subfamily string) (weight int, italic bool) {
+	if os2 := table(data, i, "OS/2"); len(os2) >= 64 {
+		weight = int(binary.BigEndian.Uint16(os2[4:]))
+		sel := binary.BigEndian.Uint16(os2[62:])
+		// Bits of fsSelection: 0 is italic, 9 oblique
+		if weight >= 100 && weight <= 1000 {
+			return weight, sel&(1<<0|1<<9) != 0
+		}
+	}
+	s := strings.ToLower(subfamily)
+	weight = 400
+	for _, w := range []struct {
+		name   string
+		weight int
+	}{{"thin", 100}, {"light", 300}, {"medium", 500}, {"semibold", 600}, {"bold", 700}, {"black", 900}} {
+		if strings.Contains(s, w.name) {
+			weight = w.weight
+		}
+	}
+	return weight, strings.Contains(s, "italic") || strings.Contains(s, "oblique")
+}
+
+// table returns a table of the face i of a font file, nil if it's missing
+func table(data []byte, i int, tag string) []byte {
+	be := binary.BigEndian
+	dir := 0
+	if len(data) >= 12 && string(data[:4]) == "ttcf" {
+		if n := int(be.Uint32(data[8:])); i >= n || len(data) < 12+4*n {
+			return nil
+		}
+		dir = int(be.Uint32(data[12+4*i:]))
+	}
+	if len(data) < dir+12 {
+		return nil
+	}
+	n := int(be.Uint16(data[dir+4:]))
+	for j := 0; j < n; j++ {
+		rec := dir + 12 + 16*j
+		if len(data) < rec+16 {
+			return nil
+		}
+		if string(data[rec:rec+4]) == tag {
+			off, size := int(be.Uint32(data[rec+8:])), int(be.Uint32(data[rec+12:]))
+			if off+size > len(data) {
+				return nil
+			}
+			return data[off : off+size]
+		}
+	}
+	return nil
+}
+
+// Families returns the names of the families of the registry, sorted
+func (r *Registry) Families() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var l []string
+	for _, faces := range r.families {
+		l = append(l, faces[0].Family)
+	}
+	sort.Strings(l)
+	return l
+}
+
+// Faces returns the faces of a family, whose name isn't case sensitive
+func (r *Registry) Faces(family string) []*Face {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return append([]*Face(nil), r.families[strings.ToLower(family)]...)
+}
+
+// SetFallback sets the families searched, in order, for the characters that
+// the face of a font can't draw
+func (r *Registry) SetFallback(families ...string) {
+	r.mu.Lock()
+	r.fallback = append([]string(nil), families...)
+	r.mu.Unlock()
+}
+
+// Fallback returns the fallback families
+func (r *Registry) Fallback() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return append([]string(nil), r.fallback...)
+}
+
+// Match returns the face of the font's family closest to its style, and the
+// part of the style the backend has to synthesize, like bold for a family
+// without bold face. Italic faces are preferred to the weight, like in CSS.
+// Fonts of unknown families get the first fallback family that is
+// registered. The face is nil if there's none.
+func (r *Registry) Match(f gs.Font) (face *Face, synthetic gs.FontStyle) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	face = r.match(f.Family, f.Style)
+	for i := 0; face == nil && i < len(r.fallback); i++ {
+		face = r.match(r.fallback[i], f.Style)
+	}
+	if face == nil {
+		return nil, synthetic
+	}
+	return face, synthesized(face, f.Style)
+}
+
+// match returns the face of a family closest to a style, nil if the family
+// isn't registered. It must be called with mu held.
+func (r *Registry) match(family string, st gs.FontStyle) (best *Face) {
+	target := 400
+	if st.Bold {
+		target = 700
+	}
+	bestDist := 0
+	for _, f := range r.families[strings.ToLower(family)] {
+		d := weightDistance(f.Weight, target)
+		if f.Italic != st.Italic {
+			d += 10000
+		}
+		if best == nil || d < bestDist {
+			best, bestDist = f, d
+		}
+	}
+	return best
+}
+
+// weightDistance ranks a weight for a wanted one: bold looks at heavier
+// weights first, the others at lighter weights first
+func weightDistance(w, target int) int {
+	switch {
+	case w == target:
+		return 0
+	case target > 500 && w > target, target <= 500 && w < target:
+		return abs(w - target)
+	}
+	return 1000 + abs(w-target)
+}
+
+func abs(i int) int {
+	if i < 0 {
+		return -i
+	}
+	return i
+}
+
+func synthesized(f *Face, st gs.FontStyle) gs.FontStyle {
+	return gs.FontStyle{Bold: st.Bold && f.Weight < 600, Italic: st.Italic && !f.Italic}
+}
+
+// Run is a part of a text drawn with one face
+type Run struct {
+	Face       *Face
+	Synthetic  gs.FontStyle // Part of the style the backend has to synthesize
+	Start, End int          // Byte offsets of the run in the text
+}
+
+// Runs splits the text into runs of the faces drawing its characters: the
+// matched face of the font when it has them, else the first fallback family
+// that does. Characters that no face has are left to the matched face, which
+// draws them as missing glyphs. Runs is nil if no face matches the font.
+func (r *Registry) Runs(text string, f gs.Font) []Run {
+	primary, synth := r.Match(f)
+	if primary == nil {
+		return nil
+	}
+	r.mu.RLock()
+	var chain []*Face
+	for _, fam := range r.fallback {
+		if face := r.match(fam, f.Style); face != nil && face != primary {
+			chain = append(chain, face)
+		}
+	}
+	r.mu.RUnlock()
+
+	var runs []Run
+	var cur *Face
+	joined := false // Whether the previous rune was a zero width joiner
+	for i, c := range text {
+		face := cur
+		if cur == nil || !gs.ExtendsCluster(c) && !joined {
+			face = primary
+			if !primary.HasGlyph(c) {
+				for _, fb := range chain {
+					if fb.HasGlyph(c) {
+						face = fb
+						break
+					}
+				}
+			}
+		}
+		joined = c == 0x200d
+		if face != cur {
+			s := synth
+			if face != primary {
+				s = synthesized(face, f.Style)
+			}
+			runs = append(runs, Run{Face: face, Synthetic: s, Start: i})
+			cur = face
+		}
+		runs[len(runs)-1].End = i + utf8.RuneLen(c)
+	}
+	return runs
+}
+
+// MeasureText returns the size of the text drawn with the font, it
+// implements gs.TextMeasurer. Without a face for the font, the size is
+// estimated like gs.MeasureText does.
+func (r *Registry) MeasureText(text string, f gs.Font) (w, h int) {
+	primary, _ := r.Match(f)
+	if primary == nil {
+		return utf8.RuneCountInString(text) * f.Size * 11 / 20, f.Size
+	}
+	var adv fixed.Int26_6
+	for _, run := range r.Runs(text, f) {
+		adv += run.Face.advance(text[run.Start:run.End], f.Size)
+	}
+	ascent, descent := primary.Metrics(f.Size)
+	return adv.Ceil(), ascent + descent
+}
@@ -0,0 +1,196 @@
+package fonts
+
+import (
+	"encoding/binary"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"unicode/utf16"
+
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/goitalic"
+	"golang.org/x/image/font/gofont/gomedium"
+	"golang.org/x/image/font/gofont/goregular"
+	chk "launchpad.net/gocheck"
+
+	gs "github.com/phaikawl/gosui"
+)
+
+func Test(t *testing.T) { chk.TestingT(t) }
+
+type FontsSuite struct{}
+
+var _ = chk.Suite(&FontsSuite{})
+
+var _ gs.TextMeasurer = Default
+
+// testFont builds a font whose glyphs, all empty and 1em wide, are the runes
+// from first to last
+func testFont(family string, weight int, first, last rune) []byte {
+	be := binary.BigEndian
+	n := int(last-first) + 2
+	u16 := func(b []byte, vs ...int) []byte {
+		for _, v := range vs {
+			b = be.AppendUint16(b, uint16(v))
+		}
+		return b
+	}
+	u32 := func(b []byte, vs ...int) []byte {
+		for _, v := range vs {
+			b = be.AppendUint32(b, uint32(v))
+		}
+		return b
+	}
+	os2 := make([]byte, 96)
+	be.PutUint16(os2, 2)
+	be.PutUint16(os2[4:], uint16(weight))
+	head := make([]byte, 54)
+	be.PutUint32(head, 0x10000)
+	be.PutUint16(head[18:], 1000)
+	hhea := make([]byte, 36)
+	be.PutUint32(hhea, 0x10000)
+	be.PutUint16(hhea[4:], 800)
+	be.PutUint16(hhea[6:], uint16(0x10000-200))
+	be.PutUint16(hhea[34:], 1)
+	maxp := make([]byte, 32)
+	be.PutUint32(maxp, 0x10000)
+	be.PutUint16(maxp[4:], uint16(n))
+	post := make([]byte, 32)
+	be.PutUint32(post, 0x30000)
+	var names []byte
+	for _, s := range []string{family, "Regular"} {
+		for _, u := range utf16.Encode([]rune(s)) {
+			names = u16(names, int(u))
+		}
+	}
+	name := u16(nil, 0, 2, 6+12*2,
+		3, 1, 0x409, 1, 2*len(family), 0,
+		3, 1, 0x409, 2, 2*len("Regular"), 2*len(family))
+	tables := []struct {
+		tag  string
+		data []byte
+	}{
+		{"OS/2", os2},
+		{"cmap", u32(u16(nil, 0, 1, 3, 10, 0, 12, 12, 0), 28, 0, 1, int(first), int(last), 1)},
+		{"glyf", nil},
+		{"head", head},
+		{"hhea", hhea},
+		{"hmtx", u16(nil, 1000, 0)},
+		{"loca", make([]byte, 2*(n+1))},
+		{"maxp", maxp},
+		{"name", append(name, names...)},
+		{"post", post},
+	}
+	data := u16(u32(nil, 0x10000), len(tables), 0, 0, 0)
+	off := len(data) + 16*len(tables)
+	var body []byte
+	for _, t := range tables {
+		data = u32(append(data, t.tag...), 0, off+len(body), len(t.data))
+		body = append(body, t.data...)
+		for len(body)%4 != 0 {
+			body = append(body, 0)
+		}
+	}
+	return append(data, body...)
+}
+
+func mustAdd(c *chk.C, r *Registry, data []byte) *Face {
+	faces, err := r.Add(data)
+	c.Assert(err, chk.IsNil)
+	c.Assert(faces, chk.HasLen, 1)
+	return faces[0]
+}
+
+func (s *FontsSuite) TestMatch(c *chk.C) {
+	r := NewRegistry()
+	regular := mustAdd(c, r, goregular.TTF)
+	bold := mustAdd(c, r, gobold.TTF)
+	italic := mustAdd(c, r, goitalic.TTF)
+	medium := mustAdd(c, r, gomedium.TTF)
+	c.Check(r.Families(), chk.DeepEquals, []string{"Go", "Go Medium"})
+	c.Check(bold.String(), chk.Equals, "Go Bold")
+	c.Check([]int{regular.Weight, bold.Weight, medium.Weight}, chk.DeepEquals, []int{400, 600, 500})
+	c.Check(italic.Italic, chk.Equals, true)
+
+	match := func(family string, st gs.FontStyle) (*Face, gs.FontStyle) {
+		return r.Match(gs.Font{Family: family, Size: 12, Style: st})
+	}
+	f, synth := match("go", gs.Regular)
+	c.Check(f, chk.Equals, regular)
+	c.Check(synth, chk.Equals, gs.Regular)
+	f, _ = match("Go", gs.Bold)
+	c.Check(f, chk.Equals, bold)
+	// Italic wins over the weight, the bold is synthesized
+	f, synth = match("Go", gs.BoldItalic)
+	c.Check(f, chk.Equals, italic)
+	c.Check(synth, chk.Equals, gs.Bold)
+	f, synth = match("Go Medium", gs.BoldItalic)
+	c.Check(f, chk.Equals, medium)
+	c.Check(synth, chk.Equals, gs.BoldItalic)
+
+	f, _ = match("Nothing", gs.Regular)
+	c.Check(f, chk.IsNil)
+	r.SetFallback("Missing", "Go Medium")
+	f, _ = match("Nothing", gs.Regular)
+	c.Check(f, chk.Equals, medium)
+}
+
+func (s *FontsSuite) TestFallback(c *chk.C) {
+	r := NewRegistry()
+	goFace := mustAdd(c, r, goregular.TTF)
+	cjk := mustAdd(c, r, testFont("Test CJK", 400, 0x4e00, 0x4e0f))
+	emoji := mustAdd(c, r, testFont("Test Emoji", 400, 0x1f600, 0x1f64f))
+	c.Check(cjk.HasGlyph(0x4e01), chk.Equals, true)
+	c.Check(cjk.HasGlyph('a'), chk.Equals, false)
+	font := gs.Font{Family: "Go", Size: 10, Style: gs.Bold}
+	text := "ab一丁c😀\U0001F3FBé中"
+
+	// Without fallback everything is left to the matched face
+	c.Check(r.Runs(text, font), chk.DeepEquals, []Run{{goFace, gs.Bold, 0, len(text)}})
+
+	r.SetFallback("Test CJK", "Test Emoji")
+	c.Check(r.Fallback(), chk.DeepEquals, []string{"Test CJK", "Test Emoji"})
+	c.Check(r.Runs(text, font), chk.DeepEquals, []Run{
+		{goFace, gs.Bold, 0, 2},
+		{cjk, gs.Bold, 2, 8},
+		{goFace, gs.Bold, 8, 9},
+		// The skin tone goes with its emoji
+		{emoji, gs.Bold, 9, 17},
+		{goFace, gs.Bold, 17, len(text)},
+	})
+	c.Check(r.Runs(text, gs.Font{Family: "Unknown", Size: 10})[0].Face, chk.Equals, cjk)
+
+	// Fallback glyphs are measured with their face, 1em wide in test fonts
+	w, h := r.MeasureText("一丁", gs.Font{Family: "Go", Size: 10})
+	c.Check(w, chk.Equals, 20)
+	ascent, descent := goFace.Metrics(10)
+	c.Check(h, chk.Equals, ascent+descent)
+	w, _ = r.MeasureText("ab", gs.Font{Family: "Go", Size: 10})
+	c.Check(w, chk.Equals, goFace.Advance("ab", 10))
+	w, h = NewRegistry().MeasureText("ab", gs.Font{Family: "Go", Size: 10})
+	c.Check([]int{w, h}, chk.DeepEquals, []int{11, 10})
+}
+
+func (s *FontsSuite) TestAddDir(c *chk.C) {
+	dir := c.MkDir()
+	write := func(name string, data []byte) {
+		c.Assert(ioutil.WriteFile(filepath.Join(dir, name), data, 0644), chk.IsNil)
+	}
+	write("a.ttf", testFont("Test A", 300, 'a', 'z'))
+	write("notes.txt", []byte("not a font"))
+	write("broken.OTF", []byte("not a font either"))
+	write("b.ttf", goregular.TTF)
+
+	r := NewRegistry()
+	err := r.AddDir(dir)
+	c.Check(err, chk.ErrorMatches, "fonts: .*broken.OTF: .*")
+	c.Check(r.Families(), chk.DeepEquals, []string{"Go", "Test A"})
+	faces := r.Faces("test a")
+	c.Assert(faces, chk.HasLen, 1)
+	c.Check(faces[0].Path, chk.Equals, filepath.Join(dir, "a.ttf"))
+	c.Check(faces[0].Weight, chk.Equals, 300)
+	c.Check(faces[0].Data(), chk.HasLen, len(testFont("Test A", 300, 'a', 'z')))
+
+	_, err = r.Add([]byte("nope"))
+	c.Check(err, chk.ErrorMatches, "fonts: font data: .*")
+}
@@ -0,0 +1,115 @@
+package gosui
+
+import (
+	"image"
+	"time"
+)
+
+// FrameStats holds timing statistics of the frames rendered by Scene.Frame
+type FrameStats struct {
+	Frames   int           // Number of frames rendered
+	Duration time.Duration // Time taken by the last frame, from the update to the end of drawing
+	Average  time.Duration // Moving average of Duration
+	Interval time.Duration // Time between the starts of the last two frames
+}
+
+// FPS returns the frame rate given by the last interval between frames
+func (st FrameStats) FPS() float64 {
+	if st.Interval <= 0 {
+		return 0
+	}
+	return float64(time.Second) / float64(st.Interval)
+}
+
+// Post queues fn to run on the UI loop at the start of the next frame, waking it up
+// if it's waiting for events. It's the only method of Scene that can be called
+// from other goroutines.
+func (s *Scene) Post(fn func()) {
+	s.mu.Lock()
+	s.posted = append(s.posted, fn)
+	wake := s.wake
+	s.mu.Unlock()
+	if wake != nil {
+		wake()
+	}
+}
+
+// SetWakeFunc registers the function Post calls to interrupt the wait of the
+// window's loop for events, it must be safe to call from any goroutine
+func (s *Scene) SetWakeFunc(fn func()) {
+	s.mu.Lock()
+	s.wake = fn
+	s.mu.Unlock()
+}
+
+// RequestAnimationFrame queues fn to run once at the start of the next frame,
+// with the time of that frame
+func (s *Scene) RequestAnimationFrame(fn func(now time.Time)) {
+	s.frameFuncs = append(s.frameFuncs, fn)
+}
+
+// Idle reports whether the scene has nothing to do until the next input event
+// or timer: nothing to redraw, no animation running, no queued function and no
+// timer due. Windows wait for events until NextTimer when it's idle.
+func (s *Scene) Idle() bool {
+	s.mu.Lock()
+	posted := len(s.posted)
+	s.mu.Unlock()
+	return posted == 0 && len(s.frameFuncs) == 0 && !s.Animating() && !s.NeedsRedraw() &&
+		!s.timerDue(s.clock.Now())
+}
+
+// runQueued runs the posted functions, the timers then the frame callbacks
+func (s *Scene) runQueued(now time.Time) {
+	s.mu.Lock()
+	posted := s.posted
+	s.posted = nil
+	s.mu.Unlock()
+	for _, fn := range posted {
+		fn()
+	}
+	s.runTimers(now)
+	frameFuncs := s.frameFuncs
+	s.frameFuncs = nil
+	for _, fn := range frameFuncs {
+		fn(now)
+	}
+}
+
+// Frame updates the scene then redraws its invalidated areas along with the
+// areas in again. Windows pass the areas drawn in the previous frame, which
+// the back buffer they now draw on lacks.
+// It returns the areas invalidated during this frame, nil if nothing was drawn.
+func (s *Scene) Frame(backend RenderBackend, again []image.Rectangle) []image.Rectangle {
+	start := s.clock.Now()
+	s.Update()
+	// The ring invalidates its areas when the focused element moved, they
+	// are part of this frame
+	s.updateFocusRing()
+	if !s.NeedsRedraw() && len(again) == 0 {
+		return nil
+	}
+	fresh := append([]image.Rectangle{}, s.dirty...)
+	for _, r := range again {
+		s.InvalidateArea(r)
+	}
+	s.RedrawDirty(backend)
+	st := &s.stats
+	if st.Frames > 0 {
+		st.Interval = start.Sub(s.lastFrame)
+	}
+	s.lastFrame = start
+	st.Frames++
+	st.Duration = s.clock.Now().Sub(start)
+	if st.Frames == 1 {
+		st.Average = st.Duration
+	} else {
+		st.Average = (7*st.Average + st.Duration) / 8
+	}
+	return fresh
+}
+
+// Stats returns the timing statistics of the frames rendered so far
+func (s *Scene) Stats() FrameStats {
+	return s.stats
+}
@@ -0,0 +1,91 @@
+package gosui
+
+import (
+	"image"
+	"sync"
+	"time"
+
+	chk "launchpad.net/gocheck"
+)
+
+func (s *MySuite) TestFrame(c *chk.C) {
+	sc := NewScene()
+	clock := NewFakeClock(time.Unix(0, 0))
+	sc.SetClock(clock)
+	b := &DummyBackend{}
+	r := NewRectElement(sc.Root(), MakeRect(0, 0, 10, 10))
+	c.Check(sc.Idle(), chk.Equals, true)
+	c.Check(sc.Frame(b, nil), chk.IsNil)
+
+	Invalidate(r)
+	c.Check(sc.Idle(), chk.Equals, false)
+	drawn := sc.Frame(b, nil)
+	c.Check(drawn, chk.DeepEquals, []image.Rectangle{r.Area})
+	c.Check(b.c, chk.Equals, 1)
+	// The areas of the previous frame are drawn again, but aren't returned
+	c.Check(sc.Frame(b, drawn), chk.DeepEquals, []image.Rectangle{})
+	c.Check(b.c, chk.Equals, 2)
+	c.Check(sc.Idle(), chk.Equals, true)
+
+	var frames []time.Time
+	sc.RequestAnimationFrame(func(now time.Time) {
+		frames = append(frames, now)
+		MoveTo(r, image.Point{5, 0}, 0).Seek(0)
+	})
+	c.Check(sc.Idle(), chk.Equals, false)
+	clock.Advance(16 * time.Millisecond)
+	c.Check(sc.Frame(b, nil), chk.DeepEquals, []image.Rectangle{MakeRect(0, 0, 15, 10)})
+	c.Check(frames, chk.DeepEquals, []time.Time{clock.Now()})
+	clock.Advance(16 * time.Millisecond)
+	c.Check(sc.Frame(b, nil), chk.IsNil)
+	c.Check(len(frames), chk.Equals, 1)
+
+	st := sc.Stats()
+	c.Check(st.Frames, chk.Equals, 3)
+	c.Check(st.Interval, chk.Equals, 16*time.Millisecond)
+	c.Check(st.FPS(), chk.Equals, 62.5)
+}
+
+func (s *MySuite) TestPost(c *chk.C) {
+	sc := NewScene()
+	woken := make(chan bool, 10)
+	sc.SetWakeFunc(func() { woken <- true })
+	var ran []int
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			sc.Post(func() { ran = append(ran, len(ran)) })
+			wg.Done()
+		}()
+	}
+	wg.Wait()
+	c.Check(len(woken), chk.Equals, 3)
+	c.Check(sc.Idle(), chk.Equals, false)
+	c.Check(ran, chk.IsNil)
+	sc.Update()
+	c.Check(ran, chk.DeepEquals, []int{0, 1, 2})
+	c.Check(sc.Idle(), chk.Equals, true)
+}
+
+func (s *MySuite) TestFrameFocusRing(c *chk.C) {
+	sc := NewScene()
+	b := &DummyBackend{}
+	e := newFocusable(sc.Root(), MakeRect(0, 0, 10, 10))
+	sc.HandleKey(&KeyEvent{Key: KeyTab})
+	sc.Frame(b, nil)
+
+	// The areas of the ring that followed the element are returned for the other buffer
+	e.MoveBy(20, 0)
+	drawn := sc.Frame(b, nil)
+	covered := func(r image.Rectangle) bool {
+		for _, d := range drawn {
+			if r.In(d) {
+				return true
+			}
+		}
+		return false
+	}
+	c.Check(covered(MakeRect(-3, -3, 13, 13)), chk.Equals, true)
+	c.Check(covered(MakeRect(17, -3, 33, 13)), chk.Equals, true)
+}
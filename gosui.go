@@ -4,7 +4,7 @@ import (
 	"container/list"
 	"image"
 	"image/color"
-	"log"
+	"math"
 	"sort"
 )
 
@@ -51,9 +51,11 @@ func NoStroke(fillColor Color) (p Paint) {
 	return p
 }
 
-// The render interface for renderable things
-type shape interface {
-	render(IElement, DrawBackend)
+// Shape is the appearance of a ConcreteElement, drawn within its area.
+// Shapes other than RectShape and TextShape can be encoded once registered
+// with RegisterShape.
+type Shape interface {
+	Render(e *ConcreteElement, backend DrawBackend)
 }
 
 // RectShape holds information specific to displayed rectangles (rounded).
@@ -68,11 +70,20 @@ type EventHandler interface{}
 type Element struct {
 	parent  *AbstractElement
 	treeLev int // The number that represents this element's depth in the tree, the root element has tier=0
+	layer   int // Elements of a higher layer are always in front, whatever their z-index
 	Area    image.Rectangle
 	zIndex  float32
 	Paint
-	cData   map[string]interface{}
-	Handler EventHandler
+	transparency float64 // 1 - opacity, so that elements are opaque by default
+	hidden       bool
+	disabled     bool
+	typ          string // Type name for stylesheets, see TypeOf
+	id           string
+	classes      []string
+	data         []interface{} // Indexed by the slots of DataKeys
+	marks        []uint64      // Indexed by the slots of Markers
+	bindings     []func()      // Cancel functions of the bindings, see Bind
+	Handler      EventHandler
 }
 
 // IElement is the common interface for AbstractElement and ConcreteElement
@@ -80,6 +91,7 @@ type IElement interface {
 	BaseElement() *Element // Returns the Element subcomponent
 	IsConcrete() bool
 	AllConcreteDescns() *list.List // Get all concrete descendants
+	MoveBy(dx, dy int)             // Move the element and its descendants
 }
 
 // ConcreteElement is the type of Element that can have a shape (apperance)
@@ -87,7 +99,7 @@ type IElement interface {
 // It cannot have children.
 type ConcreteElement struct {
 	Element
-	shape shape
+	shape Shape
 }
 
 // AbstractElement is for grouping ConcreteElement's.
@@ -95,6 +107,8 @@ type ConcreteElement struct {
 type AbstractElement struct {
 	Element
 	children []IElement
+	scene    *Scene    // Only set on the root element of a Scene
+	idx      treeIndex // Only set on roots, see FindByID
 }
 
 type FontStyle struct {
@@ -115,31 +129,38 @@ type Font struct {
 }
 
 type TextShape struct {
-	Content  string
-	Font     Font
-	Editable bool
-	origin   image.Point
+	Content   string
+	Font      Font
+	Editable  bool
+	origin    image.Point
+	caret     int // Rune index of the caret, only drawn when showCaret is set
+	anchor    int // Rune index of the other end of the selection, caret if there's none
+	showCaret bool
+	layout    *textLayout
 }
 
 func (e *ConcreteElement) TextShape() *TextShape {
 	return e.shape.(*TextShape)
 }
 
-func (e *ConcreteElement) UpdateSize(w, h int) {
-	e.Area.Max = image.Point{e.Area.Min.X + w, e.Area.Min.Y + h}
+// IsText reports whether the element's shape is a TextShape
+func (e *ConcreteElement) IsText() bool {
+	_, ok := e.shape.(*TextShape)
+	return ok
 }
 
-// SetData is used by something to add data to the element
-// for its own purpose, like for an algorithm
-func (e *Element) SetData(key string, data interface{}) {
-	if e.cData == nil {
-		e.cData = make(map[string]interface{})
-	}
-	e.cData[key] = data
+// Origin returns the starting point of the text's baseline
+func (s *TextShape) Origin() image.Point {
+	return s.origin
 }
 
-func (e *Element) GetData(key string) interface{} {
-	return e.cData[key]
+// SetOrigin moves the text so that its baseline starts at p
+func (s *TextShape) SetOrigin(p image.Point) {
+	s.origin = p
+}
+
+func (e *ConcreteElement) UpdateSize(w, h int) {
+	e.Area.Max = image.Point{e.Area.Min.X + w, e.Area.Min.Y + h}
 }
 
 // RectShape method is a helper that casts element's shape to a RectShape and return it
@@ -175,16 +196,24 @@ func (e *ConcreteElement) IsConcrete() bool {
 	return true
 }
 
-func (r *RectShape) render(ei IElement, backend DrawBackend) {
-	e := ei.(*ConcreteElement)
+// Render draws the rectangle
+func (r *RectShape) Render(e *ConcreteElement, backend DrawBackend) {
 	backend.DrawRect(e.Area, r.cornerRadiis, e.Paint)
 }
 
-func (s *TextShape) render(ei IElement, backend DrawBackend) {
-	e := ei.(*ConcreteElement)
+// Render draws the text and updates the area of the element to its size
+func (s *TextShape) Render(e *ConcreteElement, backend DrawBackend) {
+	if s.showCaret {
+		for _, r := range s.selectionRects() {
+			backend.DrawRect(r, [4]int{}, NoStroke(SelectionColor))
+		}
+	}
 	w, h := backend.DrawText(s.origin, s, e.Paint)
 	e.Area.Min = image.Point{s.origin.X, s.origin.Y - h}
 	e.UpdateSize(w, h)
+	if s.showCaret {
+		backend.DrawRect(s.caretRect(), [4]int{}, NoStroke(e.FillColor))
+	}
 }
 
 // X method returns element's top-left x coordinate
@@ -201,6 +230,9 @@ func (e *Element) H() int { return e.Area.Dy() }
 
 // IsBehind checks whether the element is behind the other element
 func (e *ConcreteElement) IsBehind(e2 *ConcreteElement) bool {
+	if e.layer != e2.layer {
+		return e.layer < e2.layer
+	}
 	if e.zIndex == e2.zIndex {
 		return e.treeLev < e2.treeLev
 	}
@@ -217,14 +249,42 @@ func (e *ConcreteElement) BaseElement() *Element {
 	return &e.Element
 }
 
-func (e *AbstractElement) fetchConcreteDescns(li *list.List) *list.List {
+func (e *AbstractElement) fetchConcreteDescns(li *list.List, visibleOnly bool) *list.List {
 	for _, oi := range e.children {
+		if visibleOnly && oi.BaseElement().hidden {
+			continue
+		}
 		if o, isConcrete := oi.(*ConcreteElement); isConcrete {
 			li.PushBack(o)
 			continue
 		}
 		o := oi.(*AbstractElement)
-		o.fetchConcreteDescns(li)
+		o.fetchConcreteDescns(li, visibleOnly)
+	}
+	return li
+}
+
+// visibleConcreteDescns returns the concrete descendants of e that aren't hidden
+// by e or its descendants, e itself included
+func visibleConcreteDescns(e IElement) *list.List {
+	b := e.BaseElement()
+	if b.hidden {
+		return list.New()
+	}
+	if a, ok := e.(*AbstractElement); ok {
+		return a.fetchConcreteDescns(list.New(), true)
+	}
+	// Types embedding an AbstractElement, like widgets
+	li := e.AllConcreteDescns()
+	for o := li.Front(); o != nil; {
+		next := o.Next()
+		for c := &o.Value.(*ConcreteElement).Element; c != b && c != nil; c = c.parentElement() {
+			if c.hidden {
+				li.Remove(o)
+				break
+			}
+		}
+		o = next
 	}
 	return li
 }
@@ -232,7 +292,7 @@ func (e *AbstractElement) fetchConcreteDescns(li *list.List) *list.List {
 // AllConcreteDescns fetches and returns a list.List of all the element's descendants
 func (e *AbstractElement) AllConcreteDescns() (li *list.List) {
 	li = list.New()
-	li = e.fetchConcreteDescns(li)
+	li = e.fetchConcreteDescns(li, false)
 	return li
 }
 
@@ -243,65 +303,55 @@ func (e *ConcreteElement) AllConcreteDescns() (li *list.List) {
 	return li
 }
 
-// Data for algorithm purposes
-type algData struct {
-	addedToRedraw bool
+// OverlappedAlgorithm encapsulates the algorithm to get overlapping elements, used for lazy redrawing
+type OverlappedAlgorithm struct {
+	added *Marker // Marks the elements added to the redraw list
 }
 
-// OverlappedAlgorithm encapsulates the algorithm to get overlapping elements, used for lazy redrawing
-type OverlappedAlgorithm struct{}
+// NewOverlappedAlgorithm starts a run of the algorithm marking the elements it
+// adds with added, which is reset: elements added to redraw lists by previous
+// runs can be added again
+func NewOverlappedAlgorithm(added *Marker) OverlappedAlgorithm {
+	added.Reset()
+	return OverlappedAlgorithm{added}
+}
 
-// InitOverlappedAlgorithm initializes the algorithm.
-// All changes by the algorithm are reset.
-func InitOverlappedAlgorithm(root *AbstractElement) (alg OverlappedAlgorithm) {
-	l := root.AllConcreteDescns()
-	for o := l.Front(); o != nil; o = o.Next() {
-		e := o.Value.(*ConcreteElement)
-		e.BaseElement().SetData("addedToRedraw", false)
+var redrawMarkerKey = NewDataKey("redraw marker")
+
+// redrawMarker returns the marker of the redraw lists of the tree of root,
+// so that the trees of different scenes don't share one
+func redrawMarker(root *AbstractElement) *Marker {
+	if m, ok := root.Data(redrawMarkerKey).(*Marker); ok {
+		return m
 	}
-	return alg
+	m := NewMarker()
+	root.SetData(redrawMarkerKey, m)
+	return m
 }
 
 func (alg OverlappedAlgorithm) addToRedrawList(e *ConcreteElement, li *list.List) {
-	e.BaseElement().SetData("addedToRedraw", true)
+	alg.added.Mark(&e.Element)
 	li.PushBack(e)
 }
 
 func (alg OverlappedAlgorithm) hasAdded(e *Element) bool {
-	v := e.GetData("addedToRedraw")
-	return v != nil && v.(bool)
+	return alg.added.Marked(e)
 }
 
-func (alg OverlappedAlgorithm) fetchOverlappingConcreteElems(target IElement, e *AbstractElement, root *AbstractElement, li *list.List) *list.List {
+func (alg OverlappedAlgorithm) fetchOverlappingConcreteElems(area image.Rectangle, e *AbstractElement, li *list.List) *list.List {
 	for _, oi := range e.children {
 		o := oi.BaseElement()
-		t := target.BaseElement()
-		if t.Area.Overlaps(o.Area) && (o != t) && (!alg.hasAdded(o)) {
+		if !o.hidden && area.Overlaps(o.Area) && (!alg.hasAdded(o)) {
 			if oi.IsConcrete() {
 				alg.addToRedrawList(oi.(*ConcreteElement), li)
 			} else {
-				li = alg.fetchOverlappingConcreteElems(target, oi.(*AbstractElement), root, li)
+				li = alg.fetchOverlappingConcreteElems(area, oi.(*AbstractElement), li)
 			}
 		}
 	}
 	return li
 }
 
-type InputHandler struct {
-	e *ConcreteElement
-}
-
-func (h InputHandler) OnMouseEvent(evt *MouseEvent) bool {
-	log.Printf("Mouse clicked on %v at %v\n", h.e.Area, evt.Pos)
-	return true
-}
-
-func NewTextInputElement(parent *AbstractElement, x, y int, font Font) *ConcreteElement {
-	te := NewTextElement(parent, x, y, font, true)
-	te.Handler = InputHandler{te}
-	return te
-}
-
 func NewTextElement(parent *AbstractElement, x, y int, font Font, editable bool) *ConcreteElement {
 	e := new(ConcreteElement)
 	parent.AddChild(e)
@@ -313,6 +363,20 @@ func NewTextElement(parent *AbstractElement, x, y int, font Font, editable bool)
 	return e
 }
 
+// NewShapeElement creates a concrete element drawn by a shape
+func NewShapeElement(parent *AbstractElement, area image.Rectangle, s Shape) *ConcreteElement {
+	e := new(ConcreteElement)
+	parent.AddChild(e)
+	e.shape = s
+	e.Area = area
+	return e
+}
+
+// Shape returns the shape of the element
+func (e *ConcreteElement) Shape() Shape {
+	return e.shape
+}
+
 // NewRectElement creates a new rsectangle concrete element
 func NewRectElement(parent *AbstractElement, area image.Rectangle) *ConcreteElement {
 	e := new(ConcreteElement)
@@ -338,6 +402,12 @@ func (r *RectShape) SetCornerRadiis(conf RectCornersRad) {
 	r.cornerRadiis[2], r.cornerRadiis[3] = conf.BotLeft, conf.BotRight
 }
 
+// CornerRadiis returns the radius of each corner
+func (r *RectShape) CornerRadiis() RectCornersRad {
+	c := r.cornerRadiis
+	return RectCornersRad{c[0], c[1], c[2], c[3]}
+}
+
 // NewRootElement creates and returns the root element
 func NewRootElement() (r *AbstractElement) {
 	r = new(AbstractElement)
@@ -356,18 +426,112 @@ func NewAbstractElement(parent *AbstractElement, area image.Rectangle) (r *Abstr
 	return r
 }
 
-// AddChild makes an element the child of an AbstractElement
+// AddChild makes an element the last child of an AbstractElement
 func (e *AbstractElement) AddChild(child IElement) {
-	e.children = append(e.children, child)
+	e.InsertChild(len(e.children), child)
+}
+
+// InsertChild makes an element the child of an AbstractElement at index i of
+// its children, i being clamped to their bounds
+func (e *AbstractElement) InsertChild(i int, child IElement) {
+	if i < 0 {
+		i = 0
+	} else if i > len(e.children) {
+		i = len(e.children)
+	}
+	e.children = append(e.children, nil)
+	copy(e.children[i+1:], e.children[i:])
+	e.children[i] = child
 	c := child.BaseElement()
 	c.parent = e
-	c.treeLev = e.treeLev + 1
 	c.zIndex = 0
+	setTreeLev(child, e.treeLev+1, e.layer)
+	if a, ok := child.(*AbstractElement); ok {
+		a.idx = nil
+	}
+	if idx := e.treeIndex(); idx != nil {
+		idx.addTree(child, true)
+	}
+}
+
+// setTreeLev updates the depth and layer of an element and its descendants
+func setTreeLev(e IElement, lev, layer int) {
+	b := e.BaseElement()
+	b.treeLev, b.layer = lev, layer
+	if a, ok := e.(*AbstractElement); ok {
+		for _, c := range a.children {
+			setTreeLev(c, lev+1, layer)
+		}
+	}
+}
+
+// RemoveChild detaches a child from the element, it does nothing if child
+// isn't a child of e. The child and its descendants lose the focus, the hover
+// and the mouse grab.
+func (e *AbstractElement) RemoveChild(child IElement) {
+	for i, c := range e.children {
+		if c == child {
+			loseFocus(child)
+			if idx := e.treeIndex(); idx != nil {
+				idx.addTree(child, false)
+			}
+			e.children = append(e.children[:i], e.children[i+1:]...)
+			child.BaseElement().parent = nil
+			return
+		}
+	}
+}
+
+// Children returns the children of the element
+func (e *AbstractElement) Children() []IElement {
+	return e.children
+}
+
+// Parent returns the parent of the element, nil for a root
+func (e *Element) Parent() *AbstractElement {
+	return e.parent
+}
+
+// resizeHandler is implemented by handlers of elements that lay out their
+// children when they are resized
+type resizeHandler interface {
+	OnResize(area image.Rectangle)
+}
+
+// Resize moves the element so that its top-left corner is at area.Min, then
+// gives it area. Handlers of AbstractElements implementing
+// OnResize(image.Rectangle) are notified so that they can lay out their children.
+func Resize(e IElement, area image.Rectangle) {
+	Invalidate(e)
+	b := e.BaseElement()
+	d := area.Min.Sub(b.Area.Min)
+	e.MoveBy(d.X, d.Y)
+	b.Area = area
+	if h, ok := b.Handler.(resizeHandler); ok {
+		h.OnResize(area)
+	}
+	Invalidate(e)
+}
+
+// MoveBy moves the element and all its descendants
+func (e *AbstractElement) MoveBy(dx, dy int) {
+	e.Area = e.Area.Add(image.Point{dx, dy})
+	for _, c := range e.children {
+		c.MoveBy(dx, dy)
+	}
+}
+
+// MoveBy moves the element
+func (e *ConcreteElement) MoveBy(dx, dy int) {
+	e.Area = e.Area.Add(image.Point{dx, dy})
+	if ts, ok := e.shape.(*TextShape); ok {
+		ts.origin = ts.origin.Add(image.Point{dx, dy})
+	}
 }
 
-// Draw the element and all its descendants
+// Draw the element and all its visible descendants
 func (e *AbstractElement) Draw(backend DrawBackend) {
-	l := makeDrawPriorityList(e.AllConcreteDescns())
+	l := makeDrawPriorityList(visibleConcreteDescns(e))
 	sort.Sort(l)
 	for _, o := range l {
 		o.Draw(backend)
@@ -376,7 +540,175 @@ func (e *AbstractElement) Draw(backend DrawBackend) {
 
 // Draw the element
 func (e *ConcreteElement) Draw(backend DrawBackend) {
-	e.shape.render(e, backend)
+	o := e.Opacity()
+	for p := e.parent; p != nil; p = p.parent {
+		o *= p.Opacity()
+	}
+	if o >= 1 {
+		e.shape.Render(e, backend)
+		return
+	}
+	e.shape.Render(e, fadedBackend{backend, o})
+}
+
+// fadedBackend passes faded copies of the paints to the backend, for elements
+// that aren't opaque
+type fadedBackend struct {
+	DrawBackend
+	opacity float64
+}
+
+func (b fadedBackend) fade(p Paint) Paint {
+	p.FillColor.A = uint8(float64(p.FillColor.A) * b.opacity)
+	p.StrokeColor.A = uint8(float64(p.StrokeColor.A) * b.opacity)
+	return p
+}
+
+func (b fadedBackend) DrawRect(r image.Rectangle, radii [4]int, p Paint) {
+	b.DrawBackend.DrawRect(r, radii, b.fade(p))
+}
+
+func (b fadedBackend) DrawText(pos image.Point, text *TextShape, p Paint) (int, int) {
+	return b.DrawBackend.DrawText(pos, text, b.fade(p))
+}
+
+// Opacity returns the opacity of the element, from 0 (invisible) to 1.
+// The opacity of an AbstractElement applies to all its descendants.
+func (e *Element) Opacity() float64 {
+	return 1 - e.transparency
+}
+
+// SetOpacity changes the opacity of the element, it's clamped between 0 and 1
+func (e *Element) SetOpacity(o float64) {
+	e.transparency = 1 - math.Max(0, math.Min(1, o))
+}
+
+// Visible reports whether the element has not been hidden with SetVisible,
+// it may still be hidden by an ancestor
+func (e *Element) Visible() bool {
+	return !e.hidden
+}
+
+// VisibleInTree reports whether the element and all its ancestors are visible
+func (e *Element) VisibleInTree() bool {
+	for ; e != nil; e = e.parentElement() {
+		if e.hidden {
+			return false
+		}
+	}
+	return true
+}
+
+// Enabled reports whether the element has not been disabled with SetEnabled,
+// it may still be disabled by an ancestor
+func (e *Element) Enabled() bool {
+	return !e.disabled
+}
+
+// EnabledInTree reports whether the element and all its ancestors are enabled
+func (e *Element) EnabledInTree() bool {
+	for ; e != nil; e = e.parentElement() {
+		if e.disabled {
+			return false
+		}
+	}
+	return true
+}
+
+func (e *Element) parentElement() *Element {
+	if e.parent == nil {
+		return nil
+	}
+	return &e.parent.Element
+}
+
+// SetVisible shows or hides the element and its descendants. Hidden elements
+// keep their place in the tree but aren't drawn, hit or focused.
+func SetVisible(e IElement, visible bool) {
+	b := e.BaseElement()
+	if b.hidden == !visible {
+		return
+	}
+	if !visible {
+		Invalidate(e)
+		b.hidden = true
+		loseFocus(e)
+		return
+	}
+	b.hidden = false
+	Invalidate(e)
+}
+
+// SetEnabled enables or disables the element and its descendants.
+// Disabled elements are drawn but ignore events and can't be focused.
+func SetEnabled(e IElement, enabled bool) {
+	b := e.BaseElement()
+	if b.disabled == !enabled {
+		return
+	}
+	b.disabled = !enabled
+	if !enabled {
+		loseFocus(e)
+	}
+	notifyEnabled(e)
+	Invalidate(e)
+}
+
+// notifyEnabled tells the handlers of the element and its descendants that
+// they may have been enabled or disabled
+func notifyEnabled(e IElement) {
+	b := e.BaseElement()
+	if h, ok := b.Handler.(enableHandler); ok {
+		h.OnEnabledChange(b.EnabledInTree())
+	}
+	if a, ok := e.(*AbstractElement); ok {
+		for _, c := range a.children {
+			notifyEnabled(c)
+		}
+	}
+}
+
+// loseFocus removes the focus, the hover and the mouse grab from the element
+// and its descendants
+func loseFocus(e IElement) {
+	s := SceneOf(e)
+	if s == nil {
+		return
+	}
+	if s.focused != nil && Within(s.focused, e) {
+		s.Focus(nil)
+	}
+	if s.grab != nil && Within(s.grab, e) {
+		s.grab = nil
+	}
+	for i, o := range s.hovered {
+		if !Within(o, e) {
+			continue
+		}
+		left := s.hovered[i:]
+		s.hovered = s.hovered[:i:i]
+		for j := len(left) - 1; j >= 0; j-- {
+			if h, ok := left[j].BaseElement().Handler.(hoverHandler); ok {
+				h.OnMouseLeave()
+			}
+		}
+		break
+	}
+	if s.tip.owner != nil && Within(s.tip.owner, e) {
+		s.hideTooltip()
+		s.tip.owner = nil
+	}
+}
+
+// Within reports whether e is anc or one of its descendants
+func Within(e, anc IElement) bool {
+	b := anc.BaseElement()
+	for c := e.BaseElement(); c != nil; c = c.parentElement() {
+		if c == b {
+			return true
+		}
+	}
+	return false
 }
 
 type DrawPriorityList [](*ConcreteElement)
@@ -397,15 +729,37 @@ func (l DrawPriorityList) Less(i, j int) bool {
 
 // Redraw the element
 func Redraw(e IElement, backend RenderBackend, root *AbstractElement) {
-	alg := InitOverlappedAlgorithm(root)
+	alg := NewOverlappedAlgorithm(redrawMarker(root))
 	itemsToRedraw := list.New()
-	d := e.AllConcreteDescns()
+	if !e.BaseElement().VisibleInTree() {
+		return
+	}
+	d := visibleConcreteDescns(e)
 	for o := d.Front(); o != nil; o = o.Next() {
 		alg.addToRedrawList(o.Value.(*ConcreteElement), itemsToRedraw)
 	}
-	alg.fetchOverlappingConcreteElems(e, root, root, itemsToRedraw)
+	alg.fetchOverlappingConcreteElems(e.BaseElement().Area, root, itemsToRedraw)
 	l := makeDrawPriorityList(itemsToRedraw)
 	sort.Sort(l)
 
 	backend.DrawElementsInArea(l, e.BaseElement().Area)
 }
+
+// RedrawArea redraws all elements that overlap the area
+func RedrawArea(area image.Rectangle, backend RenderBackend, root *AbstractElement) {
+	redrawArea(area, backend, root, nil)
+}
+
+// redrawArea redraws the elements of root that overlap the area, along with
+// extra if it's not nil: an element drawn over the tree without being part of it
+func redrawArea(area image.Rectangle, backend RenderBackend, root *AbstractElement, extra *ConcreteElement) {
+	alg := NewOverlappedAlgorithm(redrawMarker(root))
+	itemsToRedraw := alg.fetchOverlappingConcreteElems(area, root, list.New())
+	if extra != nil && extra.Area.Overlaps(area) {
+		itemsToRedraw.PushBack(extra)
+	}
+	l := makeDrawPriorityList(itemsToRedraw)
+	sort.Sort(l)
+
+	backend.DrawElementsInArea(l, area)
+}
@@ -27,6 +27,11 @@ func (b *DummyBackend) DrawRect(rect image.Rectangle, radiis [4]int, paint Paint
 	// fmt.Print("*")
 }
 
+func (b *DummyBackend) DrawText(pos image.Point, text *TextShape, paint Paint) (int, int) {
+	b.c += 1
+	return len(text.Content) * text.Font.Size / 2, text.Font.Size
+}
+
 func (b *DummyBackend) Init(w, h int) {}
 
 func (b *DummyBackend) DrawElementsInArea(l DrawPriorityList, area image.Rectangle) {
@@ -70,7 +75,7 @@ func BenchmarkDisplayEngine(t *testing.B) {
 	l := make([](*AbstractElement), 0)
 	l = append(l, root)
 	for i := 1; i < 1000; i += 1 {
-		p := NewAbstractElement(l[random(0, len(l)-1)])
+		p := NewAbstractElement(l[random(0, len(l)-1)], MakeRectWH(random(0, 500), random(0, 500), random(0, 500), random(0, 500)))
 		NewRectElement(p,
 			MakeRectWH(random(0, 500), random(0, 500), random(0, 500), random(0, 500)))
 		l = append(l, p)
@@ -83,3 +88,24 @@ func BenchmarkDisplayEngine(t *testing.B) {
 	}
 	fmt.Printf("\n %v objects drawn\n", (backend.c-1)/2)
 }
+
+func (s *MySuite) TestTextInput(c *chk.C) {
+	sc := NewScene()
+	te := NewTextInputElement(sc.Root(), 0, 20, Font{"Arial", 10, Regular})
+	in := te.Input()
+	var changes []string
+	in.OnChange = func(t string) { changes = append(changes, t) }
+	in.Filter = func(r rune) bool { return r != 'x' }
+	sc.Focus(te)
+	for _, r := range "abxc" {
+		sc.HandleChar(&CharEvent{Char: r})
+	}
+	c.Check(in.Text(), chk.Equals, "abc")
+	sc.HandleKey(&KeyEvent{Key: KeyLeft})
+	sc.HandleKey(&KeyEvent{Key: KeyBackspace})
+	sc.HandleKey(&KeyEvent{Key: KeyHome})
+	sc.HandleKey(&KeyEvent{Key: KeyDelete})
+	c.Check(in.Text(), chk.Equals, "c")
+	c.Check(in.Caret(), chk.Equals, 0)
+	c.Check(changes, chk.DeepEquals, []string{"a", "ab", "abc", "ac", "c"})
+}
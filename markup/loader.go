@@ -0,0 +1,537 @@
+package markup
+
+import (
+	"fmt"
+	"image"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+
+	gs "github.com/phaikawl/gosui"
+)
+
+// TypeFunc creates the element of a node. It reads the attributes it
+// supports from the context, which records errors in their values.
+// It returns nil if the element can't be created, after recording why with Errorf.
+type TypeFunc func(c *Context) gs.IElement
+
+// Loader builds element trees from markup, with the element types and the
+// named handlers it knows
+type Loader struct {
+	types    map[string]TypeFunc
+	handlers map[string]interface{}
+}
+
+// NewLoader creates a loader knowing the built-in element types
+func NewLoader() *Loader {
+	l := &Loader{types: make(map[string]TypeFunc), handlers: make(map[string]interface{})}
+	for name, fn := range builtinTypes {
+		l.types[name] = fn
+	}
+	return l
+}
+
+// RegisterType makes an element type available to markup, replacing any type of the same name
+func (l *Loader) RegisterType(name string, fn TypeFunc) {
+	l.types[name] = fn
+}
+
+// RegisterHandler names a handler so that markup can refer to it. It can be a
+// gs.EventHandler for the handler attribute or a function for event attributes
+// like onclick, its type must match the one of what it's bound to.
+func (l *Loader) RegisterHandler(name string, h interface{}) {
+	l.handlers[name] = h
+}
+
+// View is a tree of elements built from markup
+type View struct {
+	Root gs.IElement
+	ids  map[string]gs.IElement
+}
+
+// ByID returns the element created for the node with the id attribute, nil if there's none
+func (v *View) ByID(id string) gs.IElement {
+	return v.ids[id]
+}
+
+// LoadFile parses a .xml or .json file and builds its elements as a child of parent
+func (l *Loader) LoadFile(parent *gs.AbstractElement, filename string) (*View, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	var n *Node
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".xml":
+		n, err = ParseXML(data)
+	case ".json":
+		n, err = ParseJSON(data)
+	default:
+		return nil, fmt.Errorf("%s: unknown markup format", filename)
+	}
+	if err == nil {
+		var v *View
+		if v, err = l.Load(parent, n); err == nil {
+			return v, nil
+		}
+	}
+	// Errors are given the file name
+	switch e := err.(type) {
+	case *Error:
+		e.File = filename
+	case ErrorList:
+		for _, ee := range e {
+			ee.File = filename
+		}
+	}
+	return nil, err
+}
+
+// LoadXML parses an XML document and builds its elements as a child of parent
+func (l *Loader) LoadXML(parent *gs.AbstractElement, data []byte) (*View, error) {
+	n, err := ParseXML(data)
+	if err != nil {
+		return nil, err
+	}
+	return l.Load(parent, n)
+}
+
+// LoadJSON parses a JSON document and builds its elements as a child of parent
+func (l *Loader) LoadJSON(parent *gs.AbstractElement, data []byte) (*View, error) {
+	n, err := ParseJSON(data)
+	if err != nil {
+		return nil, err
+	}
+	return l.Load(parent, n)
+}
+
+// Load builds the elements of a node tree as a child of parent. If any error
+// is found, nothing is added to parent and an ErrorList of all of them is returned.
+func (l *Loader) Load(parent *gs.AbstractElement, n *Node) (*View, error) {
+	b := &builder{loader: l, ids: make(map[string]gs.IElement)}
+	root := b.build(n, parent, parent.Area.Min, parent.Area, false)
+	if err := b.errs.err(); err != nil {
+		if root != nil {
+			parent.RemoveChild(root)
+		}
+		return nil, err
+	}
+	if s := gs.SceneOf(parent); s != nil && s.Stylesheet() != nil {
+		s.Restyle(root)
+	}
+	gs.Invalidate(root)
+	return &View{Root: root, ids: b.ids}, nil
+}
+
+type builder struct {
+	loader *Loader
+	errs   ErrorList
+	ids    map[string]gs.IElement
+}
+
+func (b *builder) errorf(pos Position, format string, args ...interface{}) {
+	b.errs = append(b.errs, &Error{Pos: pos, Msg: fmt.Sprintf(format, args...)})
+}
+
+// build creates the element of a node and its descendants. origin is what the
+// coordinates of the node are relative to and area is the one the parent gives it.
+// In a cell of a layout, the area of the node only gives its size.
+func (b *builder) build(n *Node, parent *gs.AbstractElement, origin image.Point, area image.Rectangle, cell bool) gs.IElement {
+	fn := b.loader.types[n.Type]
+	if fn == nil {
+		b.errorf(n.Pos, "unknown element type %q", n.Type)
+		return nil
+	}
+	c := &Context{Node: n, Parent: parent, Origin: origin, Area: area, b: b, used: make(map[string]bool)}
+	if c.Has("size") {
+		v := c.ints("size", 2)
+		c.Area = gs.MakeRectWH(origin.X, origin.Y, v[0], v[1])
+	}
+	if c.Has("area") {
+		r := c.ints("area", 4)
+		if !cell {
+			c.Area = gs.MakeRectWH(origin.X+r[0], origin.Y+r[1], r[2], r[3])
+		}
+	}
+	if cell {
+		c.Area = area
+	}
+	e := fn(c)
+	if e == nil {
+		return nil
+	}
+	if classes := c.String("class", ""); classes != "" {
+		e.BaseElement().AddClass(strings.Fields(classes)...)
+	}
+	if id := c.String("id", ""); id != "" {
+		if _, dup := b.ids[id]; dup {
+			c.Errorf("id", "duplicate id %q", id)
+		}
+		b.ids[id] = e
+		e.BaseElement().SetID(id)
+	}
+	if c.Has("tabindex") {
+		gs.SetTabIndex(e, c.Int("tabindex", 0))
+	}
+	if scope := c.String("focus-scope", ""); scope != "" {
+		if sc, ok := focusScopes[scope]; ok {
+			gs.SetFocusScope(e, sc)
+		} else {
+			c.Errorf("focus-scope", "unknown focus scope %q", scope)
+		}
+	}
+	if len(n.Children) > 0 {
+		if c.Body == nil {
+			c.Errorf("", "%s elements can't have children", n.Type)
+		} else {
+			b.buildChildren(c)
+		}
+	}
+	// Applied last, the z-index of an AbstractElement shifts its descendants
+	if z, ok := e.(interface {
+		SetZIndex(float32)
+	}); ok && c.Has("z") {
+		z.SetZIndex(float32(c.Float("z", 0)))
+	}
+	if c.Has("opacity") {
+		e.BaseElement().SetOpacity(c.Float("opacity", 1))
+	}
+	for _, name := range n.AttrNames() {
+		if !c.used[name] {
+			c.Errorf(name, "unknown attribute %q for %s", name, n.Type)
+		}
+	}
+	return e
+}
+
+var focusScopes = map[string]gs.FocusScope{
+	"none":  gs.ScopeNone,
+	"group": gs.ScopeGroup,
+	"cycle": gs.ScopeCycle,
+	"once":  gs.ScopeOnce,
+}
+
+// buildChildren builds the children of a node, laying them out in a row or a
+// column if the node has the layout attribute
+func (b *builder) buildChildren(c *Context) {
+	body := c.Body
+	layout := c.String("layout", "")
+	spacing := c.Int("spacing", 0)
+	inner := body.Area.Inset(c.Int("padding", 0))
+	if layout == "" {
+		for _, cn := range c.Node.Children {
+			b.build(cn, body, inner.Min, inner, false)
+		}
+		return
+	}
+	if layout != "row" && layout != "column" {
+		c.Errorf("layout", "layout must be row or column, not %q", layout)
+		return
+	}
+	pos := inner.Min
+	for _, cn := range c.Node.Children {
+		size, ok := nodeSize(cn)
+		if !ok {
+			b.errorf(cn.Pos, "children of a %s need a size or an area", layout)
+			continue
+		}
+		b.build(cn, body, pos, image.Rectangle{pos, pos.Add(size)}, true)
+		if layout == "row" {
+			pos.X += size.X + spacing
+		} else {
+			pos.Y += size.Y + spacing
+		}
+	}
+}
+
+// nodeSize returns the size of a node given by its size or area attribute.
+// Invalid values are reported when the node is built.
+func nodeSize(n *Node) (image.Point, bool) {
+	a, ok := n.Attrs["size"]
+	want := 2
+	if !ok {
+		if a, ok = n.Attrs["area"]; !ok {
+			return image.Point{}, false
+		}
+		want = 4
+	}
+	v, err := parseInts(a.Value, want)
+	if err != nil {
+		return image.Point{}, true
+	}
+	return image.Point{v[want-2], v[want-1]}, true
+}
+
+// Context is what a TypeFunc builds an element from
+type Context struct {
+	Node   *Node
+	Parent *gs.AbstractElement // Parent of the new element
+	Origin image.Point         // Positions of the node are relative to it
+	// Area of the element, given by the area or size attribute, the layout
+	// of the parent or the area of the parent
+	Area image.Rectangle
+	// Body is where the children of the node are added, types accepting
+	// children must set it
+	Body *gs.AbstractElement
+
+	b    *builder
+	used map[string]bool
+}
+
+// Has reports whether the node has the attribute
+func (c *Context) Has(name string) bool {
+	_, ok := c.Node.Attrs[name]
+	return ok
+}
+
+// attr returns the value of an attribute and marks it as used
+func (c *Context) attr(name string) (Attr, bool) {
+	c.used[name] = true
+	a, ok := c.Node.Attrs[name]
+	return a, ok
+}
+
+// Errorf records an error about an attribute, or about the node if name is empty
+func (c *Context) Errorf(name string, format string, args ...interface{}) {
+	pos := c.Node.Pos
+	if a, ok := c.Node.Attrs[name]; ok {
+		pos = a.Pos
+	}
+	c.b.errorf(pos, format, args...)
+}
+
+// String returns the value of an attribute, def if it's missing
+func (c *Context) String(name, def string) string {
+	if a, ok := c.attr(name); ok {
+		return a.Value
+	}
+	return def
+}
+
+// Int returns the value of an integer attribute, def if it's missing or invalid
+func (c *Context) Int(name string, def int) int {
+	a, ok := c.attr(name)
+	if !ok {
+		return def
+	}
+	v, err := strconv.Atoi(strings.TrimSpace(a.Value))
+	if err != nil {
+		c.Errorf(name, "%s must be an integer, not %q", name, a.Value)
+		return def
+	}
+	return v
+}
+
+// Float returns the value of a number attribute, def if it's missing or invalid
+func (c *Context) Float(name string, def float64) float64 {
+	a, ok := c.attr(name)
+	if !ok {
+		return def
+	}
+	v, err := strconv.ParseFloat(strings.TrimSpace(a.Value), 64)
+	if err != nil {
+		c.Errorf(name, "%s must be a number, not %q", name, a.Value)
+		return def
+	}
+	return v
+}
+
+// Bool returns the value of a true or false attribute, def if it's missing or invalid
+func (c *Context) Bool(name string, def bool) bool {
+	a, ok := c.attr(name)
+	if !ok {
+		return def
+	}
+	v, err := strconv.ParseBool(strings.TrimSpace(a.Value))
+	if err != nil {
+		c.Errorf(name, "%s must be true or false, not %q", name, a.Value)
+		return def
+	}
+	return v
+}
+
+func parseInts(s string, n int) ([]int, error) {
+	f := strings.Fields(s)
+	if len(f) != n {
+		return nil, fmt.Errorf("expected %d integers", n)
+	}
+	v := make([]int, n)
+	for i, s := range f {
+		var err error
+		if v[i], err = strconv.Atoi(s); err != nil {
+			return nil, err
+		}
+	}
+	return v, nil
+}
+
+// ints returns n integers separated by spaces, zeros if the attribute is missing or invalid
+func (c *Context) ints(name string, n int) []int {
+	a, ok := c.attr(name)
+	if !ok {
+		return make([]int, n)
+	}
+	v, err := parseInts(a.Value, n)
+	if err != nil {
+		c.Errorf(name, "%s must be %d integers separated by spaces, not %q", name, n, a.Value)
+		return make([]int, n)
+	}
+	return v
+}
+
+// Point returns the value of an "x y" attribute relative to the origin, def if it's missing or invalid
+func (c *Context) Point(name string, def image.Point) image.Point {
+	if !c.Has(name) {
+		c.used[name] = true
+		return def
+	}
+	v := c.ints(name, 2)
+	return c.Origin.Add(image.Point{v[0], v[1]})
+}
+
+// Color returns the value of a color attribute written #rgb, #rrggbb, #rrggbbaa
+// or none, def if it's missing or invalid
+func (c *Context) Color(name string, def gs.Color) gs.Color {
+	a, ok := c.attr(name)
+	if !ok {
+		return def
+	}
+	col, err := gs.ParseColor(a.Value)
+	if err != nil {
+		c.Errorf(name, "%v", err)
+		return def
+	}
+	return col
+}
+
+// Font returns the value of a font attribute written "Family size [bold] [italic]",
+// the family can be left out to keep the one of def
+func (c *Context) Font(name string, def gs.Font) gs.Font {
+	a, ok := c.attr(name)
+	if !ok {
+		return def
+	}
+	f, err := gs.ParseFont(a.Value, def)
+	if err != nil {
+		c.Errorf(name, "%v", err)
+		return def
+	}
+	return f
+}
+
+// Handler binds a named handler to what ptr points to, like &button.OnClick.
+// Nothing is done if the attribute is missing.
+func (c *Context) Handler(name string, ptr interface{}) {
+	a, ok := c.attr(name)
+	if !ok {
+		return
+	}
+	h := c.b.loader.handlers[a.Value]
+	if h == nil {
+		c.Errorf(name, "unknown handler %q", a.Value)
+		return
+	}
+	dst := reflect.ValueOf(ptr).Elem()
+	hv := reflect.ValueOf(h)
+	if !hv.Type().AssignableTo(dst.Type()) {
+		c.Errorf(name, "handler %q is a %v, %s needs a %v", a.Value, hv.Type(), name, dst.Type())
+		return
+	}
+	dst.Set(hv)
+}
+
+// LiveView is a view loaded from a file, which can be rebuilt in place when the file changes
+type LiveView struct {
+	*View
+	loader   *Loader
+	parent   *gs.AbstractElement
+	filename string
+
+	// OnReload is called with the new view after each successful reload
+	OnReload func(v *View)
+}
+
+// LoadLive loads a file like LoadFile, keeping what's needed to reload it
+func (l *Loader) LoadLive(parent *gs.AbstractElement, filename string) (*LiveView, error) {
+	v, err := l.LoadFile(parent, filename)
+	if err != nil {
+		return nil, err
+	}
+	return &LiveView{View: v, loader: l, parent: parent, filename: filename}, nil
+}
+
+// Filename returns the file the view is loaded from
+func (lv *LiveView) Filename() string {
+	return lv.filename
+}
+
+// scroller is implemented by elements that scroll by rows, like data grids and tree views
+type scroller interface {
+	FirstVisible() int
+	ScrollTo(i int)
+}
+
+// Reload loads the file again and replaces the elements of the view with the new ones.
+// The focus and the scroll positions are kept for the elements whose id is
+// still there. If the file has errors, the view is left alone.
+func (lv *LiveView) Reload() error {
+	old := lv.View
+	v, err := lv.loader.LoadFile(lv.parent, lv.filename)
+	if err != nil {
+		return err
+	}
+	for id, e := range old.ids {
+		if s, ok := e.(scroller); ok {
+			if ns, ok := v.ids[id].(scroller); ok {
+				ns.ScrollTo(s.FirstVisible())
+			}
+		}
+	}
+	var focus gs.IElement
+	s := gs.SceneOf(lv.parent)
+	if s != nil && s.Focused() != nil && gs.Within(s.Focused(), old.Root) {
+		if id := old.idOf(s.Focused()); id != "" && v.ids[id] != nil {
+			focus = findBase(lv.parent, v.ids[id].BaseElement())
+		}
+	}
+	gs.Invalidate(old.Root)
+	if p := old.Root.BaseElement().Parent(); p != nil {
+		p.RemoveChild(old.Root)
+	}
+	lv.View = v
+	if focus != nil {
+		s.Focus(focus)
+	}
+	if lv.OnReload != nil {
+		lv.OnReload(v)
+	}
+	return nil
+}
+
+// idOf returns the id of the node that created e, "" if it had none
+func (v *View) idOf(e gs.IElement) string {
+	for id, ie := range v.ids {
+		if ie.BaseElement() == e.BaseElement() {
+			return id
+		}
+	}
+	return ""
+}
+
+// findBase returns the element of the tree whose Element is b. It's how the
+// AbstractElement of a widget is found from the widget.
+func findBase(root gs.IElement, b *gs.Element) gs.IElement {
+	if root.BaseElement() == b {
+		return root
+	}
+	if a, ok := root.(*gs.AbstractElement); ok {
+		for _, c := range a.Children() {
+			if f := findBase(c, b); f != nil {
+				return f
+			}
+		}
+	}
+	return nil
+}
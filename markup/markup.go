@@ -0,0 +1,311 @@
+// Package markup builds gosui element trees from XML or JSON descriptions.
+//
+// A document describes one root node. In XML each tag is a node whose name is
+// the element type, attributes are given as XML attributes and the text of a
+// node becomes its "text" attribute:
+//
+//	<group area="20 20 300 300">
+//		<rect area="0 0 120 400" fill="#78001e64" corners="10 30 5 0"/>
+//		<input pos="30 70" font="Arial 18 bold italic" z="1000">Hello world!</input>
+//		<button area="330 0 120 32" onclick="save">Save</button>
+//	</group>
+//
+// In JSON each node is an object, its type is given by "type" and its
+// children by "children", the other keys are attributes:
+//
+//	{"type": "group", "area": "20 20 300 300", "children": [
+//		{"type": "rect", "area": "0 0 120 400", "fill": "#78001e64"}
+//	]}
+//
+// Positions and areas are relative to the top-left corner of the parent.
+// The class attribute gives the element style classes, separated by spaces,
+// and the id attribute its id, found with View.ByID or the queries of elements.
+// The tabindex attribute sets the place of the element in the keyboard
+// navigation and focus-scope makes it a focus scope: "group", "cycle" or "once".
+package markup
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Position is a location in a markup file, lines and columns start at 1
+type Position struct {
+	Line, Column int
+}
+
+func (p Position) String() string {
+	return fmt.Sprintf("%d:%d", p.Line, p.Column)
+}
+
+// Attr is the value of an attribute, with where it was written
+type Attr struct {
+	Value string
+	Pos   Position
+}
+
+// Node is an element described in markup
+type Node struct {
+	Type     string
+	Attrs    map[string]Attr
+	Children []*Node
+	Pos      Position
+}
+
+// AttrNames returns the names of the attributes of the node, sorted
+func (n *Node) AttrNames() []string {
+	names := make([]string, 0, len(n.Attrs))
+	for name := range n.Attrs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Error is a problem found at a position of a markup file
+type Error struct {
+	File string
+	Pos  Position
+	Msg  string
+}
+
+func (e *Error) Error() string {
+	if e.File == "" {
+		return fmt.Sprintf("%v: %s", e.Pos, e.Msg)
+	}
+	return fmt.Sprintf("%s:%v: %s", e.File, e.Pos, e.Msg)
+}
+
+// ErrorList holds all the errors found in a document, in the order they were found
+type ErrorList []*Error
+
+func (l ErrorList) Error() string {
+	msgs := make([]string, len(l))
+	for i, e := range l {
+		msgs[i] = e.Error()
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// err returns the list as an error, nil if it's empty
+func (l ErrorList) err() error {
+	if len(l) == 0 {
+		return nil
+	}
+	return l
+}
+
+// lineIndex converts byte offsets of a file to positions
+type lineIndex []int // Offsets of the line starts
+
+func newLineIndex(data []byte) lineIndex {
+	idx := lineIndex{0}
+	for i, b := range data {
+		if b == '\n' {
+			idx = append(idx, i+1)
+		}
+	}
+	return idx
+}
+
+func (idx lineIndex) pos(offset int) Position {
+	l := sort.Search(len(idx), func(i int) bool { return idx[i] > offset }) - 1
+	return Position{l + 1, offset - idx[l] + 1}
+}
+
+// ParseXML parses an XML document
+func ParseXML(data []byte) (*Node, error) {
+	idx := newLineIndex(data)
+	d := xml.NewDecoder(bytes.NewReader(data))
+	var root *Node
+	var stack []*Node
+	var text bytes.Buffer
+	for {
+		start := int(d.InputOffset())
+		tok, err := d.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			msg := err.Error()
+			if se, ok := err.(*xml.SyntaxError); ok {
+				msg = se.Msg
+			}
+			return nil, &Error{Pos: idx.pos(int(d.InputOffset())), Msg: msg}
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if len(stack) == 0 && root != nil {
+				return nil, &Error{Pos: idx.pos(start), Msg: "more than one root element"}
+			}
+			n := &Node{Type: t.Name.Local, Attrs: make(map[string]Attr), Pos: idx.pos(start)}
+			tag := data[start:d.InputOffset()]
+			for _, a := range t.Attr {
+				n.Attrs[a.Name.Local] = Attr{a.Value, idx.pos(start + attrOffset(tag, a.Name.Local))}
+			}
+			if len(stack) > 0 {
+				p := stack[len(stack)-1]
+				p.Children = append(p.Children, n)
+			} else {
+				root = n
+			}
+			stack = append(stack, n)
+			text.Reset()
+		case xml.CharData:
+			if len(stack) > 0 {
+				text.Write(t)
+			}
+		case xml.EndElement:
+			n := stack[len(stack)-1]
+			if s := strings.TrimSpace(text.String()); s != "" && len(n.Children) == 0 {
+				n.Attrs["text"] = Attr{s, n.Pos}
+			}
+			text.Reset()
+			stack = stack[:len(stack)-1]
+		}
+	}
+	if root == nil {
+		return nil, &Error{Pos: idx.pos(len(data)), Msg: "no root element"}
+	}
+	return root, nil
+}
+
+// attrOffset finds where an attribute is written in a start tag, 0 if it can't be found
+func attrOffset(tag []byte, name string) int {
+	for i := 0; i < len(tag); {
+		j := bytes.Index(tag[i:], []byte(name))
+		if j < 0 {
+			break
+		}
+		i += j
+		rest := bytes.TrimLeft(tag[i+len(name):], " \t\r\n")
+		if isSpace(tag[i-1]) && len(rest) > 0 && rest[0] == '=' {
+			return i
+		}
+		i += len(name)
+	}
+	return 0
+}
+
+func isSpace(b byte) bool {
+	return b == ' ' || b == '\t' || b == '\r' || b == '\n'
+}
+
+// ParseJSON parses a JSON document
+func ParseJSON(data []byte) (*Node, error) {
+	p := &jsonParser{d: json.NewDecoder(bytes.NewReader(data)), idx: newLineIndex(data), data: data}
+	p.d.UseNumber()
+	n, err := p.node()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := p.d.Token(); err != io.EOF {
+		return nil, p.errorf(p.offset(), "more than one root node")
+	}
+	return n, nil
+}
+
+type jsonParser struct {
+	d    *json.Decoder
+	idx  lineIndex
+	data []byte
+}
+
+// offset returns the offset of the next token
+func (p *jsonParser) offset() int {
+	o := int(p.d.InputOffset())
+	for o < len(p.data) && (isSpace(p.data[o]) || p.data[o] == ':' || p.data[o] == ',') {
+		o++
+	}
+	return o
+}
+
+func (p *jsonParser) errorf(offset int, format string, args ...interface{}) *Error {
+	return &Error{Pos: p.idx.pos(offset), Msg: fmt.Sprintf(format, args...)}
+}
+
+func (p *jsonParser) token() (json.Token, int, error) {
+	o := p.offset()
+	tok, err := p.d.Token()
+	if err == io.EOF {
+		return nil, o, p.errorf(o, "unexpected end of document")
+	}
+	if err != nil {
+		return nil, o, p.errorf(o, "%v", err)
+	}
+	return tok, o, nil
+}
+
+func (p *jsonParser) node() (*Node, error) {
+	tok, o, err := p.token()
+	if err != nil {
+		return nil, err
+	}
+	if tok != json.Delim('{') {
+		return nil, p.errorf(o, "expected a node object")
+	}
+	n := &Node{Attrs: make(map[string]Attr), Pos: p.idx.pos(o)}
+	for p.d.More() {
+		tok, ko, err := p.token()
+		if err != nil {
+			return nil, err
+		}
+		key := tok.(string)
+		if key == "children" {
+			if err := p.children(n); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		tok, vo, err := p.token()
+		if err != nil {
+			return nil, err
+		}
+		var v string
+		switch t := tok.(type) {
+		case string:
+			v = t
+		case json.Number:
+			v = t.String()
+		case bool:
+			v = strconv.FormatBool(t)
+		default:
+			return nil, p.errorf(vo, "value of %q must be a string, a number or a boolean", key)
+		}
+		if key == "type" {
+			n.Type = v
+		} else {
+			n.Attrs[key] = Attr{v, p.idx.pos(ko)}
+		}
+	}
+	p.d.Token() // '}'
+	if n.Type == "" {
+		return nil, p.errorf(o, "node without a type")
+	}
+	return n, nil
+}
+
+func (p *jsonParser) children(n *Node) error {
+	tok, o, err := p.token()
+	if err != nil {
+		return err
+	}
+	if tok != json.Delim('[') {
+		return p.errorf(o, "children must be an array of nodes")
+	}
+	for p.d.More() {
+		c, err := p.node()
+		if err != nil {
+			return err
+		}
+		n.Children = append(n.Children, c)
+	}
+	p.d.Token() // ']'
+	return nil
+}
@@ -0,0 +1,196 @@
+package markup
+
+import (
+	"image"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	gs "github.com/phaikawl/gosui"
+	"github.com/phaikawl/gosui/widgets"
+	chk "launchpad.net/gocheck"
+)
+
+func Test(t *testing.T) { chk.TestingT(t) }
+
+type MarkupSuite struct{}
+
+var _ = chk.Suite(&MarkupSuite{})
+
+const screenXML = `<?xml version="1.0"?>
+<group area="20 20 300 300" id="box" focus-scope="cycle">
+	<rect id="bg" area="0 0 120 40" fill="#78001e64" stroke="#0a0" stroke-width="2" corners="10 30 5 0"/>
+	<text id="title" pos="10 30" font="DejaVu Sans 18 bold italic" z="5">Hello world!</text>
+	<button id="save" area="150 0 100 30" class="primary wide" tabindex="2" onclick="save">Save</button>
+</group>`
+
+func (s *MarkupSuite) TestLoadXML(c *chk.C) {
+	sc := gs.NewScene()
+	l := NewLoader()
+	saved := 0
+	l.RegisterHandler("save", func() { saved++ })
+	n := len(sc.Root().Children())
+	v, err := l.LoadXML(sc.Root(), []byte(screenXML))
+	c.Assert(err, chk.IsNil)
+	c.Check(sc.Root().Children()[n:], chk.DeepEquals, []gs.IElement{v.Root})
+	c.Check(v.ByID("box").BaseElement().Area, chk.Equals, gs.MakeRectWH(20, 20, 300, 300))
+
+	bg := v.ByID("bg").(*gs.ConcreteElement)
+	c.Check(bg.Area, chk.Equals, gs.MakeRectWH(20, 20, 120, 40))
+	c.Check(bg.Paint, chk.Equals, gs.Paint{
+		FillColor: gs.Color{R: 0x78, G: 0, B: 0x1e, A: 0x64}, StrokeColor: gs.Color{R: 0, G: 0xaa, B: 0, A: 0xff}, StrokeWidth: 2})
+	c.Check(bg.RectShape().CornerRadiis(), chk.Equals, gs.RectCornersRad{TopLeft: 10, TopRight: 30, BotLeft: 5, BotRight: 0})
+
+	title := v.ByID("title").(*gs.ConcreteElement)
+	ts := title.TextShape()
+	c.Check(ts.Content, chk.Equals, "Hello world!")
+	c.Check(ts.Origin(), chk.Equals, image.Point{30, 50})
+	c.Check(ts.Font, chk.Equals, gs.Font{Family: "DejaVu Sans", Size: 18, Style: gs.BoldItalic})
+	c.Check(title.ZIndex(), chk.Equals, float32(5))
+
+	save := v.ByID("save").(*widgets.Button)
+	c.Check(save.Text(), chk.Equals, "Save")
+	c.Check(save.Classes(), chk.DeepEquals, []string{"primary", "wide"})
+	c.Check(sc.Root().FindByID("save"), chk.Equals, gs.IElement(save.AbstractElement))
+	c.Check(gs.TabIndex(save), chk.Equals, 2)
+	c.Check(gs.FocusScopeOf(v.ByID("box")), chk.Equals, gs.ScopeCycle)
+	save.Click()
+	c.Check(saved, chk.Equals, 1)
+	c.Check(v.ByID("nothing"), chk.IsNil)
+}
+
+func (s *MarkupSuite) TestLoadJSON(c *chk.C) {
+	sc := gs.NewScene()
+	sc.Root().Area = gs.MakeRectWH(0, 0, 400, 300)
+	l := NewLoader()
+	var checked []bool
+	l.RegisterHandler("check", func(on bool) { checked = append(checked, on) })
+	v, err := l.LoadJSON(sc.Root(), []byte(`{
+		"type": "group", "layout": "column", "padding": 10, "spacing": 5,
+		"children": [
+			{"type": "checkbox", "id": "a", "size": "100 20", "text": "A", "checked": true, "onchange": "check"},
+			{"type": "slider", "id": "b", "area": "50 50 200 30", "min": 10, "max": 20, "value": 15},
+			{"type": "rect", "id": "c", "size": "10 10", "opacity": 0.5}
+		]
+	}`))
+	c.Assert(err, chk.IsNil)
+	c.Check(v.Root.BaseElement().Area, chk.Equals, gs.MakeRectWH(0, 0, 400, 300))
+
+	a := v.ByID("a").(*widgets.Checkbox)
+	c.Check(a.Area, chk.Equals, gs.MakeRectWH(10, 10, 100, 20))
+	c.Check(a.Checked(), chk.Equals, true)
+	sc.HandleMouse(&gs.MouseEvent{Pos: image.Point{15, 15}, Action: gs.EventPress})
+	sc.HandleMouse(&gs.MouseEvent{Pos: image.Point{15, 15}, Action: gs.EventRelease})
+	c.Check(checked, chk.DeepEquals, []bool{false})
+
+	// In a layout, the area only gives the size
+	b := v.ByID("b").(*widgets.Slider)
+	c.Check(b.Area, chk.Equals, gs.MakeRectWH(10, 35, 200, 30))
+	c.Check(b.Value(), chk.Equals, 15.0)
+
+	rc := v.ByID("c").BaseElement()
+	c.Check(rc.Area, chk.Equals, gs.MakeRectWH(10, 70, 10, 10))
+	c.Check(rc.Opacity(), chk.Equals, 0.5)
+}
+
+func (s *MarkupSuite) TestErrors(c *chk.C) {
+	sc := gs.NewScene()
+	l := NewLoader()
+	l.RegisterHandler("save", func() {})
+	n := len(sc.Root().Children())
+	_, err := l.LoadXML(sc.Root(), []byte(`<group>
+	<rect id="r" fill="red" area="0 0 10"/>
+	<box/>
+	<button id="r" onclick="missing" color="#fff"/>
+	<toggle onchange="save"/>
+	<rect><text/></rect>
+	<group focus-scope="all"/>
+</group>`))
+	c.Assert(err, chk.NotNil)
+	var msgs []string
+	for _, e := range err.(ErrorList) {
+		msgs = append(msgs, e.Error())
+	}
+	c.Check(msgs, chk.DeepEquals, []string{
+		`2:26: area must be 4 integers separated by spaces, not "0 0 10"`,
+		`2:15: invalid color "red"`,
+		`3:2: unknown element type "box"`,
+		`4:17: unknown handler "missing"`,
+		`4:10: duplicate id "r"`,
+		`4:35: unknown attribute "color" for button`,
+		`5:10: handler "save" is a func(), onchange needs a func(bool)`,
+		`6:2: rect elements can't have children`,
+		`7:9: unknown focus scope "all"`,
+	})
+	// Nothing is added when there are errors
+	c.Check(sc.Root().Children(), chk.HasLen, n)
+
+	_, err = l.LoadXML(sc.Root(), []byte("<group>\n  <rect>\n</group>"))
+	c.Check(err, chk.ErrorMatches, "3:9: element <rect> closed by </group>")
+	_, err = l.LoadJSON(sc.Root(), []byte("{\"type\": \"group\",\n  \"children\": [\n    {\"area\": \"0 0 1 1\"}]}"))
+	c.Check(err, chk.ErrorMatches, "3:5: node without a type")
+	_, err = l.LoadJSON(sc.Root(), []byte("{\"type\": \"group\",\n  \"area\": [1, 2]}"))
+	c.Check(err, chk.ErrorMatches, `2:11: value of "area" must be a string, a number or a boolean`)
+}
+
+// scrollBox is an element type scrolling by rows
+type scrollBox struct {
+	*gs.AbstractElement
+	first int
+}
+
+func (b *scrollBox) FirstVisible() int { return b.first }
+func (b *scrollBox) ScrollTo(i int)    { b.first = i }
+
+func (s *MarkupSuite) TestReload(c *chk.C) {
+	dir, err := ioutil.TempDir("", "markup")
+	c.Assert(err, chk.IsNil)
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "screen.xml")
+	write := func(content string) {
+		c.Assert(ioutil.WriteFile(file, []byte(content), 0644), chk.IsNil)
+	}
+
+	sc := gs.NewScene()
+	l := NewLoader()
+	l.RegisterType("list", func(c *Context) gs.IElement {
+		return &scrollBox{AbstractElement: gs.NewAbstractElement(c.Parent, c.Area)}
+	})
+	write(`<group>
+	<button id="ok" area="0 0 80 30">OK</button>
+	<list id="items" area="0 40 80 200"/>
+</group>`)
+	lv, err := l.LoadLive(sc.Root(), file)
+	c.Assert(err, chk.IsNil)
+	n := len(sc.Root().Children())
+	lv.ByID("ok").(*widgets.Button).Focus()
+	lv.ByID("items").(*scrollBox).ScrollTo(7)
+	old := lv.Root
+	var reloaded *View
+	lv.OnReload = func(v *View) { reloaded = v }
+
+	write(`<group>
+	<button id="ok" area="10 0 80 30">Fine</button>
+	<list id="items" area="0 40 80 200"/>
+</group>`)
+	c.Assert(lv.Reload(), chk.IsNil)
+	c.Check(reloaded, chk.Equals, lv.View)
+	c.Check(lv.Root, chk.Not(chk.Equals), old)
+	c.Check(old.BaseElement().Parent(), chk.IsNil)
+	c.Check(sc.Root().Children(), chk.HasLen, n)
+	ok := lv.ByID("ok").(*widgets.Button)
+	c.Check(ok.Text(), chk.Equals, "Fine")
+	c.Check(ok.Area, chk.Equals, gs.MakeRectWH(10, 0, 80, 30))
+	c.Check(sc.Focused(), chk.Equals, gs.IElement(ok.AbstractElement))
+	c.Check(lv.ByID("items").(*scrollBox).first, chk.Equals, 7)
+
+	// The view is kept when the file has errors
+	reloaded = nil
+	write(`<group><button id="ok" onclick="nothing"/></group>`)
+	err = lv.Reload()
+	c.Check(err, chk.ErrorMatches, `.*screen.xml:1:24: unknown handler "nothing"`)
+	c.Check(lv.ByID("ok"), chk.Equals, gs.IElement(ok))
+	c.Check(reloaded, chk.IsNil)
+	c.Check(sc.Root().Children(), chk.HasLen, n)
+}
@@ -0,0 +1,141 @@
+package markup
+
+import (
+	"image"
+
+	gs "github.com/phaikawl/gosui"
+	"github.com/phaikawl/gosui/widgets"
+)
+
+var builtinTypes = map[string]TypeFunc{
+	"group":    groupType,
+	"rect":     rectType,
+	"text":     textType,
+	"input":    inputType,
+	"button":   buttonType,
+	"checkbox": checkboxType,
+	"toggle":   toggleType,
+	"slider":   sliderType,
+	"progress": progressType,
+}
+
+// paint reads the fill, stroke and stroke-width attributes
+func paint(c *Context, def gs.Paint) gs.Paint {
+	return gs.Paint{
+		FillColor:   c.Color("fill", def.FillColor),
+		StrokeColor: c.Color("stroke", def.StrokeColor),
+		StrokeWidth: c.Int("stroke-width", def.StrokeWidth),
+	}
+}
+
+// group is an AbstractElement holding the children of the node
+func groupType(c *Context) gs.IElement {
+	e := gs.NewAbstractElement(c.Parent, c.Area)
+	c.Handler("handler", &e.Handler)
+	c.Body = e
+	return e
+}
+
+// rect is a rectangle, corners gives the radius of all its corners or
+// of each of them: top-left, top-right, bottom-left and bottom-right
+func rectType(c *Context) gs.IElement {
+	e := gs.NewRectElement(c.Parent, c.Area)
+	e.Paint = paint(c, gs.Paint{})
+	if a, ok := c.attr("corners"); ok {
+		if v, err := parseInts(a.Value, 1); err == nil {
+			e.RectShape().SetAllCornerRadiusTo(v[0])
+		} else if v, err := parseInts(a.Value, 4); err == nil {
+			e.RectShape().SetCornerRadiis(gs.RectCornersRad{TopLeft: v[0], TopRight: v[1], BotLeft: v[2], BotRight: v[3]})
+		} else {
+			c.Errorf("corners", "corners must be 1 or 4 integers separated by spaces, not %q", a.Value)
+		}
+	}
+	c.Handler("handler", &e.Handler)
+	return e
+}
+
+// baseline returns the start of the baseline of a text, given by pos or at
+// the left of the area, vertically centered
+func baseline(c *Context, font gs.Font) image.Point {
+	_, h := gs.MeasureText("", font)
+	def := image.Point{c.Area.Min.X, c.Area.Min.Y + (c.Area.Dy()+h)/2}
+	return c.Point("pos", def)
+}
+
+func textType(c *Context) gs.IElement {
+	font := c.Font("font", widgets.DefaultFont)
+	pos := baseline(c, font)
+	e := gs.NewTextElement(c.Parent, pos.X, pos.Y, font, false)
+	e.TextShape().Content = c.String("text", "")
+	e.Paint = paint(c, gs.NoStroke(widgets.DefaultStyle.Text))
+	c.Handler("handler", &e.Handler)
+	return e
+}
+
+// input is an editable text, with the onchange and oncommit events
+func inputType(c *Context) gs.IElement {
+	font := c.Font("font", widgets.DefaultFont)
+	pos := baseline(c, font)
+	e := gs.NewTextInputElement(c.Parent, pos.X, pos.Y, font)
+	e.TextShape().Content = c.String("text", "")
+	e.Paint = paint(c, gs.NoStroke(widgets.DefaultStyle.Text))
+	in := e.Input()
+	c.Handler("onchange", &in.OnChange)
+	c.Handler("oncommit", &in.OnCommit)
+	return e
+}
+
+func buttonType(c *Context) gs.IElement {
+	b := widgets.NewButton(c.Parent, c.Area, c.String("text", ""))
+	b.SetEnabled(c.Bool("enabled", true))
+	c.Handler("onclick", &b.OnClick)
+	return b
+}
+
+func checkboxType(c *Context) gs.IElement {
+	b := widgets.NewCheckbox(c.Parent, c.Area, c.String("text", ""))
+	b.SetChecked(c.Bool("checked", false))
+	b.SetEnabled(c.Bool("enabled", true))
+	c.Handler("onchange", &b.OnChange)
+	return b
+}
+
+func toggleType(c *Context) gs.IElement {
+	t := widgets.NewToggle(c.Parent, c.Area, c.String("text", ""))
+	t.SetOn(c.Bool("on", false))
+	t.SetEnabled(c.Bool("enabled", true))
+	c.Handler("onchange", &t.OnChange)
+	return t
+}
+
+// orientation reads the orient attribute, horizontal or vertical
+func orientation(c *Context) widgets.Orientation {
+	switch o := c.String("orient", "horizontal"); o {
+	case "horizontal":
+		return widgets.Horizontal
+	case "vertical":
+		return widgets.Vertical
+	default:
+		c.Errorf("orient", "orient must be horizontal or vertical, not %q", o)
+		return widgets.Horizontal
+	}
+}
+
+func sliderType(c *Context) gs.IElement {
+	min, max := c.Float("min", 0), c.Float("max", 1)
+	s := widgets.NewSlider(c.Parent, c.Area, orientation(c), min, max)
+	if c.Has("step") {
+		s.SetStep(c.Float("step", 0))
+	}
+	s.SetValue(c.Float("value", min))
+	s.SetEnabled(c.Bool("enabled", true))
+	c.Handler("onchange", &s.OnChange)
+	return s
+}
+
+func progressType(c *Context) gs.IElement {
+	p := widgets.NewProgressBar(c.Parent, c.Area, orientation(c))
+	p.SetValue(c.Float("value", 0))
+	p.SetIndeterminate(c.Bool("indeterminate", false))
+	return p
+}
@@ -2,9 +2,14 @@ package native
 
 import (
 	"fmt"
+	"log"
 	"os"
 	"path"
+	"path/filepath"
 	"runtime"
+	"time"
+
+	"github.com/phaikawl/gosui/fonts"
 )
 
 var (
@@ -17,17 +22,43 @@ func LocalDir(relPath string) string {
 }
 
 func GetFile(filename string) string {
+	if file, ok := findFile(filename); ok {
+		return file
+	}
+	panic(fmt.Sprintf(`Cannot find asset file %v \n
+		Searched in %v\n`, filename, assetDirs))
+}
+
+//findFile looks for a file in the asset directories
+func findFile(filename string) (string, bool) {
 	for _, dir := range assetDirs {
 		file := path.Join(dir, filename)
 		if _, err := os.Stat(file); os.IsNotExist(err) {
 			continue
 		}
-		return file
+		return file, true
 	}
-	panic(fmt.Sprintf(`Cannot find asset file %v \n
-		Searched in %v\n`, filename, assetDirs))
+	return "", false
 }
 
+//assetsModTime returns the latest modification time of the files in the asset directories
+func assetsModTime() (t time.Time) {
+	for _, dir := range assetDirs {
+		filepath.Walk(dir, func(_ string, info os.FileInfo, err error) error {
+			if err == nil && info.ModTime().After(t) {
+				t = info.ModTime()
+			}
+			return nil
+		})
+	}
+	return t
+}
+
+//AddAssetDir adds a directory where asset files are searched, the font files
+//it contains are loaded in fonts.Default
 func AddAssetDir(dir string) {
 	assetDirs = append(assetDirs, dir)
+	if err := fonts.Default.AddDir(dir); err != nil {
+		log.Println(err)
+	}
 }
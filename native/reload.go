@@ -0,0 +1,78 @@
+package native
+
+import (
+	"log"
+	"os"
+	"time"
+
+	gs "github.com/phaikawl/gosui"
+	"github.com/phaikawl/gosui/markup"
+)
+
+//HotReloadInterval is how often the files of markup views are checked for changes
+var HotReloadInterval = 500 * time.Millisecond
+
+type watchedView struct {
+	*markup.LiveView
+	modTime time.Time
+}
+
+func modTime(file string) time.Time {
+	info, err := os.Stat(file)
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
+
+//LoadMarkup builds the elements of a markup file as a child of parent.
+//The file is looked for in the asset directories first.
+//With hot reload on, the elements are rebuilt when the file or an asset changes.
+func (wn *Window) LoadMarkup(l *markup.Loader, parent *gs.AbstractElement, filename string) (*markup.LiveView, error) {
+	file, ok := findFile(filename)
+	if !ok {
+		file = filename
+	}
+	lv, err := l.LoadLive(parent, file)
+	if err != nil {
+		return nil, err
+	}
+	wn.views = append(wn.views, &watchedView{lv, modTime(file)})
+	return lv, nil
+}
+
+//SetHotReload turns on or off the rebuilding of markup views when their
+//files or the files of the asset directories change.
+//Errors in changed files are logged and the views are left as they were.
+func (wn *Window) SetHotReload(on bool) {
+	if on == (wn.reloader != 0) {
+		return
+	}
+	if !on {
+		wn.scene.ClearTimer(wn.reloader)
+		wn.reloader = 0
+		return
+	}
+	wn.assetsTime = assetsModTime()
+	for _, v := range wn.views {
+		v.modTime = modTime(v.Filename())
+	}
+	wn.reloader = wn.scene.SetInterval(HotReloadInterval, wn.checkFiles)
+}
+
+//checkFiles reloads the views whose file changed, or all of them if an asset changed
+func (wn *Window) checkFiles() {
+	at := assetsModTime()
+	assetsChanged := at.After(wn.assetsTime)
+	wn.assetsTime = at
+	for _, v := range wn.views {
+		mt := modTime(v.Filename())
+		if !assetsChanged && !mt.After(v.modTime) {
+			continue
+		}
+		v.modTime = mt
+		if err := v.Reload(); err != nil {
+			log.Print(err)
+		}
+	}
+}
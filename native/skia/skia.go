@@ -11,6 +11,7 @@ import (
 
 	"github.com/go-gl/gl"
 	gs "github.com/phaikawl/gosui"
+	"github.com/phaikawl/gosui/fonts"
 )
 
 func toSkColor(c gs.Color) C.Color {
@@ -18,9 +19,10 @@ func toSkColor(c gs.Color) C.Color {
 }
 
 type Backend struct {
-	w, h    int
-	r       C.SkiaRenderer
-	saveCnt C.int
+	w, h      int
+	r         C.SkiaRenderer
+	saveCnt   C.int
+	typefaces map[*fonts.Face]C.Typeface
 }
 
 func (b *Backend) Init(w, h int) {
@@ -72,18 +74,85 @@ func toCfStyle(fs gs.FontStyle) (cfs C.FontStyle) {
 	return cfs
 }
 
+//DrawText draws the visual form of the text, shaped and reordered, in runs of
+//the faces of fonts.Default that have its characters. Skia draws each run with
+//the typeface of its face, synthesizing the part of the style the face lacks.
+//Skia looks the family up among the installed fonts when no face matches.
 func (b *Backend) DrawText(pos image.Point, text *gs.TextShape, paint gs.Paint) (int, int) {
-	byteCont := []byte(text.Content)
-	ff := C.CString(text.Font.Family)
+	if len(text.Content) == 0 {
+		return 0, text.Font.Size
+	}
+	content := text.Visual()
+	runs := fonts.Default.Runs(content, text.Font)
+	if runs == nil {
+		return b.drawFamily(pos, content, text.Font, paint)
+	}
+	w, h := 0, 0
+	for _, r := range runs {
+		rw, rh := b.drawRun(pos.Add(image.Point{w, 0}), content[r.Start:r.End], r, text.Font.Size, paint)
+		w += rw
+		if rh > h {
+			h = rh
+		}
+	}
+	return w, h
+}
+
+//typeface returns the skia typeface of the face, loaded from its file, or from
+//its data when it was given as bytes, the first time it's drawn
+func (b *Backend) typeface(face *fonts.Face) C.Typeface {
+	if tf, ok := b.typefaces[face]; ok {
+		return tf
+	}
+	var tf C.Typeface
+	if face.Path != "" {
+		path := C.CString(face.Path)
+		tf = C.TypefaceFromFile(path, C.int(face.Index))
+		C.free(unsafe.Pointer(path))
+	} else if data := face.Data(); len(data) > 0 {
+		//Skia copies the data
+		tf = C.TypefaceFromData(unsafe.Pointer(&data[0]), C.int(len(data)), C.int(face.Index))
+	}
+	if b.typefaces == nil {
+		b.typefaces = make(map[*fonts.Face]C.Typeface)
+	}
+	b.typefaces[face] = tf
+	return tf
+}
+
+func (b *Backend) drawRun(pos image.Point, content string, run fonts.Run, size int, paint gs.Paint) (int, int) {
+	tf := b.typeface(run.Face)
+	if tf == nil {
+		//Skia couldn't load the face, let it find the family
+		font := gs.Font{Family: run.Face.Family, Size: size, Style: gs.FontStyle{Bold: run.Face.Weight >= 600, Italic: run.Face.Italic}}
+		return b.drawFamily(pos, content, font, paint)
+	}
+	byteCont := []byte(content)
+	cpaint := toCPaint(paint)
+	cpaint.textSize = C.int(size)
+	csize := C.DrawTextTypeface(b.r, cpaint, toCPoint(pos),
+		unsafe.Pointer(&byteCont[0]), C.int(len(byteCont)), tf, toCfStyle(run.Synthetic))
+	return int(csize.x), int(csize.y)
+}
+
+func (b *Backend) drawFamily(pos image.Point, content string, font gs.Font, paint gs.Paint) (int, int) {
+	byteCont := []byte(content)
+	ff := C.CString(font.Family)
 	cpaint := toCPaint(paint)
-	cpaint.textSize = C.int(text.Font.Size)
+	cpaint.textSize = C.int(font.Size)
 	defer C.free(unsafe.Pointer(ff))
 	csize := C.DrawText(b.r, cpaint, toCPoint(pos),
-		unsafe.Pointer(&byteCont[0]), C.int(len(byteCont)), ff, toCfStyle(text.Font.Style))
+		unsafe.Pointer(&byteCont[0]), C.int(len(byteCont)), ff, toCfStyle(font.Style))
 	return int(csize.x), int(csize.y)
 }
 
 func (b *Backend) Die() {
+	for _, tf := range b.typefaces {
+		if tf != nil {
+			C.UnrefTypeface(tf)
+		}
+	}
+	b.typefaces = nil
 	C.Die(b.r)
 }
 
@@ -10,6 +10,7 @@ import (
 	"github.com/go-gl/gl"
 	glfw "github.com/go-gl/glfw3"
 	gs "github.com/phaikawl/gosui"
+	"github.com/phaikawl/gosui/fonts"
 )
 
 //RenderBackend does the real work of drawing and updating graphics
@@ -26,12 +27,24 @@ type Window struct {
 	glw *glfw.Window
 	b   RenderBackend
 
-	root *gs.AbstractElement
+	scene *gs.Scene
+	//Signalled when a function is posted to the scene
+	wake chan struct{}
+
+	//Markup views rebuilt by hot reload
+	views      []*watchedView
+	reloader   gs.TimerID //Checks the files, 0 when hot reload is off
+	assetsTime time.Time
 }
 
 //RootElement gets the root element
 func (wn *Window) RootElement() *gs.AbstractElement {
-	return wn.root
+	return wn.scene.Root()
+}
+
+//Scene returns the scene holding the window's elements and input state
+func (wn *Window) Scene() *gs.Scene {
+	return wn.scene
 }
 
 func errorCallback(err glfw.ErrorCode, desc string) {
@@ -53,9 +66,96 @@ func NewWindow(b RenderBackend, w, h int, title string) *Window {
 
 	window.MakeContextCurrent()
 	b.Init(w, h)
+	gs.Measurer = fonts.Default
 	setupGL(w, h)
 
-	return &Window{window, b, gs.NewRootElement()}
+	scene := gs.NewScene()
+	scene.SetViewport(gs.MakeRectWH(0, 0, w, h))
+	return &Window{glw: window, b: b, scene: scene, wake: make(chan struct{}, 1)}
+}
+
+//AddAccelerator registers a function to call when the shortcut is pressed,
+//whatever has the focus
+func (wn *Window) AddAccelerator(sc gs.Shortcut, fn func()) {
+	wn.scene.AddAccelerator(sc, fn)
+}
+
+//SetTimeout calls fn once on the UI goroutine after d, between frames
+func (wn *Window) SetTimeout(d time.Duration, fn func()) gs.TimerID {
+	return wn.scene.SetTimeout(d, fn)
+}
+
+//SetInterval calls fn on the UI goroutine every d, until the timer is cleared
+func (wn *Window) SetInterval(d time.Duration, fn func()) gs.TimerID {
+	return wn.scene.SetInterval(d, fn)
+}
+
+//ClearTimer cancels a timer created by SetTimeout or SetInterval
+func (wn *Window) ClearTimer(id gs.TimerID) {
+	wn.scene.ClearTimer(id)
+}
+
+//wakeUp interrupts wait, it's called from any goroutine by Post
+func (wn *Window) wakeUp() {
+	select {
+	case wn.wake <- struct{}{}:
+	default:
+	}
+}
+
+const (
+	//tickInterval is the time between the ticks of animations when no frame is presented
+	tickInterval = time.Second / 60
+	//pollInterval is how often input is checked while the loop waits
+	pollInterval = 10 * time.Millisecond
+)
+
+//wait handles the pending events then sleeps for d, until a function is
+//posted or until an event needs a redraw. GLFW 3.0 can't interrupt
+//WaitEvents from another goroutine, so the events are polled while sleeping.
+func (wn *Window) wait(d time.Duration) {
+	for {
+		glfw.PollEvents()
+		if d <= 0 || wn.scene.NeedsRedraw() || wn.glw.ShouldClose() {
+			return
+		}
+		step := d
+		if step > pollInterval {
+			step = pollInterval
+		}
+		select {
+		case <-wn.wake:
+			return
+		case <-time.After(step):
+		}
+		d -= step
+	}
+}
+
+//untilNextTick returns how long the loop can wait for events before the
+//next animation tick or timer, with no limit when the scene is idle
+func (wn *Window) untilNextTick() time.Duration {
+	s := wn.scene
+	if s.NeedsRedraw() {
+		return 0
+	}
+	d := time.Duration(math.MaxInt64)
+	switch {
+	case s.Animating():
+		d = tickInterval
+	case !s.Idle():
+		//Something is queued or a timer is due
+		d = 0
+	}
+	if at, ok := s.NextTimer(); ok {
+		if until := at.Sub(s.Clock().Now()); until < d {
+			d = until
+		}
+	}
+	if d < 0 {
+		d = 0
+	}
+	return d
 }
 
 //Size of the window
@@ -70,14 +170,76 @@ func toBool(m glfw.ModifierKey) bool {
 	return true
 }
 
+func toModifiers(mod glfw.ModifierKey) gs.Modifiers {
+	return gs.Modifiers{
+		Control: toBool(glfw.ModControl & mod),
+		Shift:   toBool(glfw.ModShift & mod),
+		Alt:     toBool(glfw.ModAlt & mod),
+		Super:   toBool(glfw.ModSuper & mod),
+	}
+}
+
+func toAction(action glfw.Action) gs.EventAction {
+	switch action {
+	case glfw.Release:
+		return gs.EventRelease
+	case glfw.Repeat:
+		return gs.EventRepeat
+	}
+	return gs.EventPress
+}
+
+var specialKeys = map[glfw.Key]gs.Key{
+	glfw.KeyEscape:    gs.KeyEscape,
+	glfw.KeyEnter:     gs.KeyEnter,
+	glfw.KeyKpEnter:   gs.KeyEnter,
+	glfw.KeyTab:       gs.KeyTab,
+	glfw.KeyBackspace: gs.KeyBackspace,
+	glfw.KeyInsert:    gs.KeyInsert,
+	glfw.KeyDelete:    gs.KeyDelete,
+	glfw.KeyRight:     gs.KeyRight,
+	glfw.KeyLeft:      gs.KeyLeft,
+	glfw.KeyDown:      gs.KeyDown,
+	glfw.KeyUp:        gs.KeyUp,
+	glfw.KeyPageUp:    gs.KeyPageUp,
+	glfw.KeyPageDown:  gs.KeyPageDown,
+	glfw.KeyHome:      gs.KeyHome,
+	glfw.KeyEnd:       gs.KeyEnd,
+}
+
+func toKey(key glfw.Key) gs.Key {
+	if k, ok := specialKeys[key]; ok {
+		return k
+	}
+	switch {
+	case key >= glfw.KeyF1 && key <= glfw.KeyF12:
+		return gs.KeyF1 + gs.Key(key-glfw.KeyF1)
+	case key >= glfw.KeySpace && key < glfw.KeyEscape:
+		// Printable keys have the same codes
+		return gs.Key(key)
+	}
+	return gs.KeyUnknown
+}
+
+//wheelSteps rounds a scroll offset away from zero, so that small
+//touchpad movements aren't lost
+func wheelSteps(off float64) int {
+	if off < 0 {
+		return int(math.Floor(off))
+	}
+	return int(math.Ceil(off))
+}
+
+func cursorPos(w *glfw.Window) image.Point {
+	x, y := w.GetCursorPosition()
+	return image.Point{int(math.Floor(x)), int(math.Floor(y))}
+}
+
 //Loop is the main loop for the window, everything happens there
 //Call this method to get the window running
 func (wn *Window) Start() {
 	b := wn.b
 
-	needUpdate := false
-	continuousRedraw := true
-
 	wn.glw.SetMouseButtonCallback(func(w *glfw.Window,
 		button glfw.MouseButton, action glfw.Action, mod glfw.ModifierKey) {
 
@@ -94,55 +256,76 @@ func (wn *Window) Start() {
 			return
 		}
 
-		var act gs.EventAction
-		switch action {
-		case glfw.Press:
-			act = gs.EventPress
-		case glfw.Release:
-			act = gs.EventRelease
-		case glfw.Repeat:
-			act = gs.EventRepeat
-		}
-		x, y := w.GetCursorPosition()
-		go gs.HandleMouse(&gs.MouseEvent{
+		wn.scene.HandleMouse(&gs.MouseEvent{
 			Button: btn,
+			Pos:    cursorPos(w),
+			Mod:    toModifiers(mod),
+			Action: toAction(action),
+		})
+	})
+
+	wn.glw.SetCursorPositionCallback(func(w *glfw.Window, x, y float64) {
+		wn.scene.HandleMouse(&gs.MouseEvent{
 			Pos:    image.Point{int(math.Floor(x)), int(math.Floor(y))},
-			Mod: gs.Modifiers{
-				Control: toBool(glfw.ModControl | mod),
-				Shift:   toBool(glfw.ModShift | mod),
-				Alt:     toBool(glfw.ModAlt | mod),
-				Super:   toBool(glfw.ModSuper | mod),
-			},
-			Action: act,
-		}, wn.root)
+			Action: gs.EventMove,
+		})
+	})
+
+	wn.glw.SetScrollCallback(func(w *glfw.Window, xoff, yoff float64) {
+		wn.scene.HandleMouse(&gs.MouseEvent{
+			Pos:    cursorPos(w),
+			Action: gs.EventScroll,
+			Scroll: image.Point{wheelSteps(xoff), wheelSteps(yoff)},
+		})
+	})
+
+	wn.glw.SetKeyCallback(func(w *glfw.Window,
+		key glfw.Key, scancode int, action glfw.Action, mod glfw.ModifierKey) {
+
+		wn.scene.HandleKey(&gs.KeyEvent{
+			Key:    toKey(key),
+			Mod:    toModifiers(mod),
+			Action: toAction(action),
+		})
+	})
+
+	wn.glw.SetCharacterCallback(func(w *glfw.Window, char uint) {
+		wn.scene.HandleChar(&gs.CharEvent{Char: rune(char)})
 	})
 
 	defer glfw.Terminate()
 	defer wn.b.Die()
 
-	//Will redraw the window again and again in a short while
-	//Dirty way to ensure that a we don't get a buggy black window
-	time.AfterFunc(500*time.Millisecond, func() {
-		continuousRedraw = false
-	})
+	wn.scene.SetWakeFunc(wn.wakeUp)
+	//Present at most once per vertical refresh
+	glfw.SwapInterval(1)
 
 	w, h := wn.Size()
+	wn.scene.InvalidateArea(gs.MakeRectWH(0, 0, w, h))
+	//Areas drawn in the previous frame, the back buffer we draw on next
+	//still shows what was there before
+	var prev []image.Rectangle
 	for !wn.glw.ShouldClose() {
+		if prev != nil && !(wn.scene.Idle() && len(prev) == 0) {
+			//Swapping the buffers waited for the vertical refresh
+			glfw.PollEvents()
+		} else {
+			//Nothing was presented so vsync doesn't pace the loop: sleep
+			//until something happens, the next animation tick or timer
+			wn.wait(wn.untilNextTick())
+		}
 		cw, ch := wn.Size()
 		if cw != w || ch != h {
 			w, h = cw, ch
 			b.UpdateViewportSize(cw, ch)
-			needUpdate = true
+			wn.scene.SetViewport(gs.MakeRectWH(0, 0, cw, ch))
+			wn.scene.InvalidateArea(gs.MakeRectWH(0, 0, cw, ch))
 		}
-		if needUpdate || continuousRedraw {
-			wn.glw.SwapBuffers()
-			gs.Redraw(wn.root, b, wn.root)
-			needUpdate = false
+		prev = wn.scene.Frame(b, prev)
+		if prev != nil {
 			b.Flush()
 			wn.glw.SwapBuffers()
 		}
-
-		glfw.PollEvents()
 	}
 }
 
@@ -160,6 +343,11 @@ func setupGL(w, h int) {
 	gl.Hint(gl.LINE_SMOOTH_HINT|gl.LINE_SMOOTH_HINT, gl.NICEST)
 }
 
-func GetFontFilename(family string) {
-
+//GetFontFilename returns the file of the regular face of a font family loaded
+//in fonts.Default, "" if there's none
+func GetFontFilename(family string) string {
+	if f, _ := fonts.Default.Match(gs.Font{Family: family}); f != nil {
+		return f.Path
+	}
+	return ""
 }
@@ -0,0 +1,256 @@
+package gosui
+
+import "image"
+
+// Placement tells on which side of its anchor a popup is shown
+type Placement int
+
+const (
+	PlaceBelow Placement = iota
+	PlaceAbove
+	PlaceRight
+	PlaceLeft
+)
+
+// Popup is a floating element shown in the overlay layer of a Scene,
+// in front of all normal content whatever its z-index.
+// Popups opened later are in front of earlier ones.
+// Dropdown lists, menus and tooltips are built on it.
+//
+// Its content is created as its descendants, positioned relative to (0, 0);
+// the whole popup is moved when shown.
+type Popup struct {
+	*AbstractElement
+	scene     *Scene
+	open      bool
+	pos       image.Point // Current offset of the content
+	prevFocus IElement    // Focused element when the popup was shown
+	backdrop  *ConcreteElement
+
+	// Anchor is the element the popup belongs to, clicks on it don't close
+	// the popup so that it can toggle it itself
+	Anchor IElement
+	// KeepOpen disables closing the popup on outside clicks and Escape
+	KeepOpen bool
+	// Modal popups block the input to everything beneath them and keep the
+	// focus inside them. They are shown over a backdrop of BackdropColor
+	// and, like KeepOpen ones, are only closed by calling Close.
+	Modal         bool
+	BackdropColor Color
+	// OnClose is called when the popup has been closed
+	OnClose func()
+}
+
+// NewPopup creates a closed popup for the scene
+func (s *Scene) NewPopup() *Popup {
+	p := &Popup{scene: s, BackdropColor: Color{0, 0, 0, 100}}
+	p.AbstractElement = new(AbstractElement)
+	return p
+}
+
+// Popups returns the open popups, from the bottom to the top
+func (s *Scene) Popups() []*Popup {
+	return s.popups
+}
+
+// TopPopup returns the topmost open popup, or nil
+func (s *Scene) TopPopup() *Popup {
+	if len(s.popups) == 0 {
+		return nil
+	}
+	return s.popups[len(s.popups)-1]
+}
+
+// TopModal returns the topmost open modal popup, or nil
+func (s *Scene) TopModal() *Popup {
+	for i := len(s.popups) - 1; i >= 0; i-- {
+		if s.popups[i].Modal {
+			return s.popups[i]
+		}
+	}
+	return nil
+}
+
+func (s *Scene) popupIndex(p *Popup) int {
+	for i, o := range s.popups {
+		if o == p {
+			return i
+		}
+	}
+	return -1
+}
+
+// popupAt returns the topmost popup containing the point,
+// popups beneath a modal one aren't considered
+func (s *Scene) popupAt(pt image.Point) *Popup {
+	for i := len(s.popups) - 1; i >= 0; i-- {
+		if pt.In(s.popups[i].Area) {
+			return s.popups[i]
+		}
+		if s.popups[i].Modal {
+			break
+		}
+	}
+	return nil
+}
+
+// blockedByModal reports whether the element is beneath the top modal popup
+func (s *Scene) blockedByModal(e IElement) bool {
+	m := s.TopModal()
+	if m == nil {
+		return false
+	}
+	for _, p := range s.popups[s.popupIndex(m):] {
+		if Within(e, p.AbstractElement) {
+			return false
+		}
+	}
+	return true
+}
+
+// closePopupsOutside closes the popups above the one that contains the point
+func (s *Scene) closePopupsOutside(pt image.Point) {
+	for p := s.TopPopup(); p != nil && !pt.In(p.Area) && !p.KeepOpen && !p.Modal; p = s.TopPopup() {
+		if p.Anchor != nil && pt.In(Bounds(p.Anchor)) {
+			return
+		}
+		p.Close()
+	}
+}
+
+// Scene returns the scene the popup belongs to
+func (p *Popup) Scene() *Scene {
+	return p.scene
+}
+
+// IsOpen reports whether the popup is shown
+func (p *Popup) IsOpen() bool {
+	return p.open
+}
+
+// Position returns the offset the content of the popup is currently moved by.
+// Elements added to a popup that has already been shown must be offset by it.
+func (p *Popup) Position() image.Point {
+	return p.pos
+}
+
+// Size returns the size of the popup's content
+func (p *Popup) Size() image.Point {
+	return Bounds(p.AbstractElement).Size()
+}
+
+// ShowAt shows the popup with its top-left corner at pt,
+// moved if needed to stay inside the viewport
+func (p *Popup) ShowAt(pt image.Point) {
+	p.show(fitIn(image.Rectangle{pt, pt.Add(p.Size())}, p.scene.viewport).Min)
+}
+
+// ShowNear shows the popup next to the anchor area, on the side given by
+// placement if there's room for it, on the opposite side otherwise.
+// It's moved along that side if needed to stay inside the viewport.
+func (p *Popup) ShowNear(anchor image.Rectangle, placement Placement) {
+	sz, vp := p.Size(), p.scene.viewport
+	below := image.Point{anchor.Min.X, anchor.Max.Y}
+	above := image.Point{anchor.Min.X, anchor.Min.Y - sz.Y}
+	right := image.Point{anchor.Max.X, anchor.Min.Y}
+	left := image.Point{anchor.Min.X - sz.X, anchor.Min.Y}
+	var pt image.Point
+	switch placement {
+	case PlaceBelow:
+		pt = below
+		if below.Y+sz.Y > vp.Max.Y && above.Y >= vp.Min.Y {
+			pt = above
+		}
+	case PlaceAbove:
+		pt = above
+		if above.Y < vp.Min.Y && below.Y+sz.Y <= vp.Max.Y {
+			pt = below
+		}
+	case PlaceRight:
+		pt = right
+		if right.X+sz.X > vp.Max.X && left.X >= vp.Min.X {
+			pt = left
+		}
+	case PlaceLeft:
+		pt = left
+		if left.X < vp.Min.X && right.X+sz.X <= vp.Max.X {
+			pt = right
+		}
+	}
+	p.ShowAt(pt)
+}
+
+// fitIn moves r so that it is inside area, as long as it's small enough
+func fitIn(r, area image.Rectangle) image.Rectangle {
+	var d image.Point
+	if r.Max.X > area.Max.X {
+		d.X = area.Max.X - r.Max.X
+	}
+	if r.Min.X+d.X < area.Min.X {
+		d.X = area.Min.X - r.Min.X
+	}
+	if r.Max.Y > area.Max.Y {
+		d.Y = area.Max.Y - r.Max.Y
+	}
+	if r.Min.Y+d.Y < area.Min.Y {
+		d.Y = area.Min.Y - r.Min.Y
+	}
+	return r.Add(d)
+}
+
+func (p *Popup) show(pt image.Point) {
+	s := p.scene
+	if p.open {
+		Invalidate(p.AbstractElement)
+	} else {
+		s.popups = append(s.popups, p)
+		// Each popup gets two layers, the lower one for its backdrop
+		if p.Modal {
+			p.backdrop = NewRectElement(s.overlay, s.viewport)
+			p.backdrop.Paint = NoStroke(p.BackdropColor)
+			setTreeLev(p.backdrop, p.backdrop.treeLev, 2*len(s.popups)-1)
+			Invalidate(p.backdrop)
+		}
+		s.overlay.AddChild(p.AbstractElement)
+		setTreeLev(p.AbstractElement, p.treeLev, 2*len(s.popups))
+		p.prevFocus = s.focused
+		p.open = true
+	}
+	d := pt.Sub(p.pos)
+	p.MoveBy(d.X, d.Y)
+	p.pos = pt
+	p.Area = Bounds(p.AbstractElement)
+	Invalidate(p.AbstractElement)
+}
+
+// Close hides the popup and the ones opened after it.
+// If the focus was inside it, it goes back to where it was when the popup was shown.
+func (p *Popup) Close() {
+	if !p.open {
+		return
+	}
+	s := p.scene
+	i := 0
+	for s.popups[i] != p {
+		i++
+	}
+	for len(s.popups) > i+1 {
+		s.TopPopup().Close()
+	}
+	s.popups = s.popups[:i]
+	refocus := s.focused != nil && Within(s.focused, p.AbstractElement)
+	Invalidate(p.AbstractElement)
+	s.overlay.RemoveChild(p.AbstractElement)
+	if p.backdrop != nil {
+		Invalidate(p.backdrop)
+		s.overlay.RemoveChild(p.backdrop)
+		p.backdrop = nil
+	}
+	p.open = false
+	if refocus {
+		s.Focus(p.prevFocus)
+	}
+	if p.OnClose != nil {
+		p.OnClose()
+	}
+}
@@ -0,0 +1,189 @@
+package gosui
+
+import (
+	"image"
+	"time"
+
+	chk "launchpad.net/gocheck"
+)
+
+func (s *MySuite) TestPopupPlacement(c *chk.C) {
+	sc := NewScene()
+	sc.SetViewport(MakeRect(0, 0, 200, 200))
+	p := sc.NewPopup()
+	NewRectElement(p.AbstractElement, MakeRect(0, 0, 50, 80))
+
+	p.ShowNear(MakeRect(10, 10, 60, 30), PlaceBelow)
+	c.Check(p.Area, chk.Equals, MakeRect(10, 30, 60, 110))
+	// Not enough room below, goes above
+	p.ShowNear(MakeRect(10, 150, 60, 170), PlaceBelow)
+	c.Check(p.Area, chk.Equals, MakeRect(10, 70, 60, 150))
+	// Not enough room on the right, goes left
+	p.ShowNear(MakeRect(160, 10, 190, 30), PlaceRight)
+	c.Check(p.Area, chk.Equals, MakeRect(110, 10, 160, 90))
+	p.ShowAt(image.Point{180, 190})
+	c.Check(p.Area, chk.Equals, MakeRect(150, 120, 200, 200))
+	c.Check(sc.Popups(), chk.DeepEquals, []*Popup{p})
+}
+
+func (s *MySuite) TestPopupInFront(c *chk.C) {
+	sc := NewScene()
+	bg := NewRectElement(sc.Root(), MakeRect(0, 0, 100, 100))
+	bg.SetZIndex(1000)
+	bgH := &recHandler{}
+	bg.Handler = bgH
+	p := sc.NewPopup()
+	r := NewRectElement(p.AbstractElement, MakeRect(0, 0, 20, 20))
+	rH := &recHandler{}
+	r.Handler = rH
+	p.ShowAt(image.Point{10, 10})
+
+	c.Check(sc.ElementAt(image.Point{15, 15}), chk.Equals, r)
+	sc.HandleMouse(&MouseEvent{Pos: image.Point{15, 15}, Action: EventPress})
+	c.Check(bgH.events, chk.HasLen, 0)
+	c.Check(rH.events, chk.DeepEquals, []string{"enter", "mouse"})
+}
+
+func (s *MySuite) TestPopupClosing(c *chk.C) {
+	sc := NewScene()
+	anchor := NewRectElement(sc.Root(), MakeRect(0, 0, 10, 10))
+	closed := 0
+	newPopup := func(x int) *Popup {
+		p := sc.NewPopup()
+		NewRectElement(p.AbstractElement, MakeRect(0, 0, 20, 20))
+		p.OnClose = func() { closed++ }
+		p.ShowAt(image.Point{x, 50})
+		return p
+	}
+	p1, p2 := newPopup(0), newPopup(100)
+	p1.Anchor = anchor
+	// Clicking in p1 closes p2 only
+	sc.HandleMouse(&MouseEvent{Pos: image.Point{5, 55}, Action: EventPress})
+	c.Check(p1.IsOpen(), chk.Equals, true)
+	c.Check(p2.IsOpen(), chk.Equals, false)
+	// Clicking the anchor doesn't close
+	sc.HandleMouse(&MouseEvent{Pos: image.Point{5, 5}, Action: EventPress})
+	c.Check(p1.IsOpen(), chk.Equals, true)
+	sc.HandleKey(&KeyEvent{Key: KeyEscape})
+	c.Check(p1.IsOpen(), chk.Equals, false)
+	c.Check(closed, chk.Equals, 2)
+
+	// Closing a popup closes the ones above it
+	p1.ShowAt(image.Point{0, 50})
+	p2.ShowAt(image.Point{100, 50})
+	p1.Close()
+	c.Check(sc.Popups(), chk.HasLen, 0)
+	c.Check(closed, chk.Equals, 4)
+}
+
+func (s *MySuite) TestModalPopup(c *chk.C) {
+	sc := NewScene()
+	sc.SetViewport(MakeRect(0, 0, 200, 200))
+	under := NewRectElement(sc.Root(), MakeRect(0, 0, 200, 200))
+	underH := &recHandler{focus: true}
+	under.Handler = underH
+	sc.Focus(under)
+	sc.AddAccelerator(Shortcut{Key: KeyF1}, func() { c.Error("accelerator fired under a modal popup") })
+
+	p := sc.NewPopup()
+	p.Modal = true
+	a := NewRectElement(p.AbstractElement, MakeRect(0, 0, 20, 20))
+	a.Handler = &recHandler{focus: true}
+	b := NewRectElement(p.AbstractElement, MakeRect(20, 0, 40, 20))
+	b.Handler = &recHandler{focus: true}
+	p.ShowAt(image.Point{50, 50})
+	underH.events = nil
+
+	// Clicks outside are blocked and don't close it
+	sc.HandleMouse(&MouseEvent{Pos: image.Point{5, 5}, Action: EventPress})
+	c.Check(p.IsOpen(), chk.Equals, true)
+	c.Check(underH.events, chk.HasLen, 0)
+	c.Check(sc.ElementAt(image.Point{5, 5}), chk.Equals, p.backdrop)
+
+	sc.Focus(under)
+	c.Check(sc.Focused(), chk.Equals, IElement(under))
+	sc.HandleKey(&KeyEvent{Key: KeyTab})
+	sc.HandleKey(&KeyEvent{Key: KeyTab})
+	c.Check(sc.Focused(), chk.Equals, IElement(b))
+	sc.HandleKey(&KeyEvent{Key: KeyTab})
+	c.Check(sc.Focused(), chk.Equals, IElement(a))
+	sc.Focus(b)
+	sc.Focus(under)
+	c.Check(sc.Focused(), chk.Equals, IElement(b))
+	sc.HandleKey(&KeyEvent{Key: KeyEscape})
+	sc.HandleKey(&KeyEvent{Key: KeyF1})
+	c.Check(p.IsOpen(), chk.Equals, true)
+
+	p.Close()
+	c.Check(sc.Focused(), chk.Equals, IElement(under))
+	c.Check(p.backdrop, chk.IsNil)
+}
+
+func (s *MySuite) TestTooltip(c *chk.C) {
+	sc := NewScene()
+	sc.SetViewport(MakeRect(0, 0, 400, 300))
+	e := NewAbstractElement(sc.Root(), MakeRect(0, 0, 400, 300))
+	NewRectElement(e, MakeRect(300, 250, 400, 300))
+	NewRectElement(sc.Root(), MakeRect(0, 0, 50, 50))
+	built := 0
+	sc.SetTooltip(e, func(p *Popup) {
+		built++
+		NewRectElement(p.AbstractElement, MakeRect(0, 0, 80, 20))
+	})
+	move := func(x, y int) {
+		sc.HandleMouse(&MouseEvent{Pos: image.Point{x, y}, Action: EventMove})
+	}
+	t0 := time.Unix(0, 0)
+	clock := NewFakeClock(t0)
+	sc.SetClock(clock)
+	tick := func(d time.Duration) {
+		clock.Advance(t0.Add(d).Sub(clock.Now()))
+		sc.Update()
+	}
+
+	move(390, 290)
+	tick(0)
+	tick(TooltipDelay / 2)
+	c.Check(sc.Tooltip(), chk.IsNil)
+	tick(TooltipDelay)
+	c.Check(built, chk.Equals, 1)
+	p := sc.Tooltip()
+	c.Check(p.IsOpen(), chk.Equals, true)
+	// Kept inside the viewport without covering the cursor
+	c.Check(p.Area, chk.Equals, MakeRect(320, 266, 400, 286))
+
+	// Moving inside the element keeps it, leaving hides it
+	move(305, 295)
+	c.Check(sc.Tooltip(), chk.Equals, p)
+	move(10, 10)
+	c.Check(p.IsOpen(), chk.Equals, false)
+	_, pending := sc.NextTimer()
+	c.Check(pending, chk.Equals, false)
+
+	// Leaving before the delay cancels it
+	tick(time.Second)
+	move(310, 260)
+	tick(time.Second + TooltipDelay/2)
+	move(10, 10)
+	tick(time.Second + TooltipDelay)
+	c.Check(sc.Tooltip(), chk.IsNil)
+
+	// Clicking hides it until the element is left
+	tick(3 * time.Second)
+	move(310, 260)
+	tick(3*time.Second + TooltipDelay)
+	c.Check(sc.Tooltip(), chk.NotNil)
+	sc.HandleMouse(&MouseEvent{Pos: image.Point{310, 260}, Action: EventPress})
+	c.Check(sc.Tooltip(), chk.IsNil)
+	move(311, 260)
+	tick(4 * time.Second)
+	tick(4*time.Second + TooltipDelay)
+	c.Check(sc.Tooltip(), chk.IsNil)
+	c.Check(built, chk.Equals, 2)
+
+	sc.SetTooltip(e, nil)
+	move(10, 10)
+	move(310, 260)
+	_, pending = sc.NextTimer()
+	c.Check(pending, chk.Equals, false)
+}
@@ -0,0 +1,290 @@
+package gosui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// treeIndex maps "#id" and ".class" keys to the elements of a tree having them.
+// It's kept by the root of the tree once it has been queried, and updated as
+// elements are added, removed or change their id or classes.
+type treeIndex map[string][]IElement
+
+func (idx treeIndex) add(key string, e IElement) {
+	idx[key] = append(idx[key], e)
+}
+
+func (idx treeIndex) remove(key string, e *Element) {
+	l := idx[key]
+	for i, x := range l {
+		if x.BaseElement() == e {
+			l = append(l[:i], l[i+1:]...)
+			break
+		}
+	}
+	if len(l) == 0 {
+		delete(idx, key)
+	} else {
+		idx[key] = l
+	}
+}
+
+func indexKeys(b *Element) []string {
+	keys := make([]string, 0, len(b.classes)+1)
+	if b.id != "" {
+		keys = append(keys, "#"+b.id)
+	}
+	for _, c := range b.classes {
+		keys = append(keys, "."+c)
+	}
+	return keys
+}
+
+// addTree adds e and its descendants to the index, or removes them
+func (idx treeIndex) addTree(e IElement, add bool) {
+	b := e.BaseElement()
+	for _, k := range indexKeys(b) {
+		if add {
+			idx.add(k, e)
+		} else {
+			idx.remove(k, b)
+		}
+	}
+	if a, ok := e.(*AbstractElement); ok {
+		for _, c := range a.children {
+			idx.addTree(c, add)
+		}
+	}
+}
+
+// treeIndex returns the index of the tree of e, nil if it hasn't been built
+func (e *AbstractElement) treeIndex() treeIndex {
+	for e.parent != nil {
+		e = e.parent
+	}
+	return e.idx
+}
+
+// updateIndex adds or removes the element under key in the index of its tree
+func (e *Element) updateIndex(key string, add bool) {
+	if e.parent == nil {
+		return
+	}
+	idx := e.parent.treeIndex()
+	if idx == nil {
+		return
+	}
+	if !add {
+		idx.remove(key, e)
+		return
+	}
+	for _, c := range e.parent.children {
+		if c.BaseElement() == e {
+			idx.add(key, c)
+		}
+	}
+}
+
+// ID returns the id of the element, "" if it has none
+func (e *Element) ID() string {
+	return e.id
+}
+
+// SetID sets the id of the element, matched by FindByID and by #id in
+// queries and stylesheets. Ids are meant to be unique in a tree.
+func (e *Element) SetID(id string) {
+	if e.id != "" {
+		e.updateIndex("#"+e.id, false)
+	}
+	e.id = id
+	if id != "" {
+		e.updateIndex("#"+id, true)
+	}
+}
+
+// index returns the index of the tree of e, building it if needed
+func (e *AbstractElement) index() treeIndex {
+	root := e
+	for root.parent != nil {
+		root = root.parent
+	}
+	if root.idx == nil {
+		root.idx = make(treeIndex)
+		for _, c := range root.children {
+			root.idx.addTree(c, true)
+		}
+	}
+	return root.idx
+}
+
+// inside reports whether c is a descendant of e, and not e itself
+func inside(c IElement, e *AbstractElement) bool {
+	return c != IElement(e) && Within(c, e)
+}
+
+// FindByID returns the descendant of e with the id, nil if there's none
+func (e *AbstractElement) FindByID(id string) IElement {
+	for _, c := range e.index()["#"+id] {
+		if inside(c, e) {
+			return c
+		}
+	}
+	return nil
+}
+
+// Query is a parsed query, see ParseQuery
+type Query [][]selector // Alternatives, each a list of selectors for an element and its ancestors
+
+// ParseQuery parses a comma separated list of selectors like in stylesheets,
+// without pseudo-classes. A selector can be a list of selectors separated by
+// spaces to match descendants, like "dialog #ok" or "tabview .page button".
+func ParseQuery(src string) (Query, error) {
+	var q Query
+	for _, alt := range strings.Split(src, ",") {
+		fields := strings.Fields(alt)
+		if len(fields) == 0 {
+			return nil, fmt.Errorf("empty selector in query %q", src)
+		}
+		var sels []selector
+		for _, f := range fields {
+			sel, err := parseSelector(f)
+			if err != nil {
+				return nil, err
+			}
+			if sel.state != (StyleState{}) {
+				return nil, fmt.Errorf("pseudo-class in query %q", src)
+			}
+			sels = append(sels, sel)
+		}
+		q = append(q, sels)
+	}
+	return q, nil
+}
+
+// MustParseQuery is like ParseQuery but panics on errors
+func MustParseQuery(src string) Query {
+	q, err := ParseQuery(src)
+	if err != nil {
+		panic(err)
+	}
+	return q
+}
+
+// QueryAll returns the descendants of e matching the query, in tree order.
+// It panics if the query is invalid, use ParseQuery for queries that aren't
+// written in the code.
+func (e *AbstractElement) QueryAll(query string) []IElement {
+	return MustParseQuery(query).All(e)
+}
+
+// All returns the descendants of e matching the query, in tree order
+func (q Query) All(e *AbstractElement) []IElement {
+	var found []IElement
+	seen := make(map[*Element]bool)
+	for _, sels := range q {
+		for _, c := range candidates(e, &sels[len(sels)-1]) {
+			if b := c.BaseElement(); !seen[b] && matchesPath(c, sels, e) {
+				seen[b] = true
+				found = append(found, c)
+			}
+		}
+	}
+	sortTreeOrder(found)
+	return found
+}
+
+// candidates returns the descendants of e that may match sel, from the index
+// if sel has an id or classes
+func candidates(e *AbstractElement, sel *selector) (l []IElement) {
+	switch {
+	case sel.id != "":
+		l = e.index()["#"+sel.id]
+	case len(sel.classes) > 0:
+		l = e.index()["."+sel.classes[0]]
+	default:
+		var walk func(a *AbstractElement)
+		walk = func(a *AbstractElement) {
+			for _, c := range a.children {
+				l = append(l, c)
+				if ca, ok := c.(*AbstractElement); ok {
+					walk(ca)
+				}
+			}
+		}
+		walk(e)
+		return l
+	}
+	var in []IElement
+	for _, c := range l {
+		if inside(c, e) {
+			in = append(in, c)
+		}
+	}
+	return in
+}
+
+// matchesPath reports whether e matches the last selector and its ancestors,
+// up to scope included, match the others in order
+func matchesPath(e IElement, sels []selector, scope *AbstractElement) bool {
+	last := len(sels) - 1
+	if !sels[last].matches(e, StyleState{}) {
+		return false
+	}
+	i := last - 1
+	for p := e.BaseElement().parent; i >= 0 && p != nil; p = p.parent {
+		if sels[i].matches(p, StyleState{}) {
+			i--
+		}
+		if p == scope {
+			break
+		}
+	}
+	return i < 0
+}
+
+// treeOrder sorts elements of a tree in depth-first order, by their paths from the root
+type treeOrder struct {
+	elems []IElement
+	paths [][]int
+}
+
+func (l treeOrder) Len() int { return len(l.elems) }
+func (l treeOrder) Swap(i, j int) {
+	l.elems[i], l.elems[j] = l.elems[j], l.elems[i]
+	l.paths[i], l.paths[j] = l.paths[j], l.paths[i]
+}
+func (l treeOrder) Less(i, j int) bool {
+	pi, pj := l.paths[i], l.paths[j]
+	for k := 0; k < len(pi) && k < len(pj); k++ {
+		if pi[k] != pj[k] {
+			return pi[k] < pj[k]
+		}
+	}
+	return len(pi) < len(pj)
+}
+
+func sortTreeOrder(elems []IElement) {
+	l := treeOrder{elems, make([][]int, len(elems))}
+	for i, e := range elems {
+		l.paths[i] = treePath(e)
+	}
+	sort.Sort(l)
+}
+
+// treePath returns the indexes of e and its ancestors among their siblings, from the root
+func treePath(e IElement) []int {
+	var path []int
+	for b := e.BaseElement(); b.parent != nil; b = &b.parent.Element {
+		for i, c := range b.parent.children {
+			if c.BaseElement() == b {
+				path = append(path, i)
+				break
+			}
+		}
+	}
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+	return path
+}
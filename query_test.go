@@ -0,0 +1,51 @@
+package gosui
+
+import (
+	chk "launchpad.net/gocheck"
+)
+
+func (s *MySuite) TestQueries(c *chk.C) {
+	sc := NewScene()
+	root := sc.Root()
+	dialog := NewAbstractElement(root, MakeRect(0, 0, 100, 100))
+	dialog.SetType("dialog")
+	dialog.SetID("confirm")
+	ok := NewRectElement(dialog, MakeRect(0, 0, 10, 10))
+	ok.SetID("ok")
+	ok.AddClass("button", "primary")
+	cancel := NewRectElement(dialog, MakeRect(20, 0, 30, 10))
+	cancel.AddClass("button")
+	other := NewAbstractElement(root, MakeRect(0, 0, 100, 100))
+	help := NewRectElement(other, MakeRect(0, 0, 10, 10))
+	help.AddClass("button")
+
+	c.Check(root.FindByID("ok"), chk.Equals, IElement(ok))
+	c.Check(dialog.FindByID("ok"), chk.Equals, IElement(ok))
+	c.Check(other.FindByID("ok"), chk.IsNil)
+	c.Check(dialog.FindByID("confirm"), chk.IsNil)
+	c.Check(root.QueryAll(".button"), chk.DeepEquals, []IElement{ok, cancel, help})
+	c.Check(root.QueryAll("dialog .button"), chk.DeepEquals, []IElement{ok, cancel})
+	c.Check(root.QueryAll("#confirm rect.primary, group rect"), chk.DeepEquals, []IElement{ok, help})
+	c.Check(other.QueryAll("rect"), chk.DeepEquals, []IElement{help})
+	c.Check(root.QueryAll("dialog"), chk.DeepEquals, []IElement{dialog})
+
+	// The index follows changes of ids, classes and of the tree
+	ok.SetID("yes")
+	cancel.RemoveClass("button")
+	dialog.RemoveChild(help)
+	other.RemoveChild(help)
+	dialog.AddChild(help)
+	c.Check(root.FindByID("ok"), chk.IsNil)
+	c.Check(root.FindByID("yes"), chk.Equals, IElement(ok))
+	c.Check(root.QueryAll("dialog .button"), chk.DeepEquals, []IElement{ok, help})
+	root.RemoveChild(dialog)
+	c.Check(root.QueryAll(".button"), chk.HasLen, 0)
+	c.Check(dialog.QueryAll(".button"), chk.DeepEquals, []IElement{ok, help})
+	root.AddChild(dialog)
+	c.Check(root.FindByID("yes"), chk.Equals, IElement(ok))
+
+	_, err := ParseQuery("button:hover")
+	c.Check(err, chk.ErrorMatches, `pseudo-class in query "button:hover"`)
+	_, err = ParseQuery("a,,b")
+	c.Check(err, chk.ErrorMatches, `empty selector in query "a,,b"`)
+}
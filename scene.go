@@ -0,0 +1,467 @@
+package gosui
+
+import (
+	"image"
+	"sync"
+	"time"
+)
+
+// Scene holds the element tree of a window along with the state that is
+// shared by all of its elements: keyboard focus, hovered elements, mouse grab
+// and the areas waiting to be redrawn.
+// Window implementations feed their input events to it.
+type Scene struct {
+	root     *AbstractElement
+	overlay  *AbstractElement // Parent of the open popups
+	popups   []*Popup         // Open popups, from the bottom to the top
+	viewport image.Rectangle
+	focused  IElement
+	hovered  []IElement // Path from the root to the topmost element under the cursor
+	grab     IElement
+	dirty    []image.Rectangle
+	accels   map[Shortcut]func()
+	ctxMenus map[IElement]func(image.Point)
+	tooltips map[*Element]func(*Popup) // Keyed by base element, so that widgets embedding an element work
+	tip      tooltip
+	tickers  []func(time.Duration) bool
+	lastTick time.Time
+	clock    Clock
+	sheet    *Stylesheet
+
+	access      AccessAdapter
+	accessTree  *AccessNode
+	accessDirty bool // Whether the scene changed since accessTree was built
+
+	keyboard     bool // Whether the last input came from the keyboard
+	focusVisible bool // Whether the focus was given by the keyboard
+	ringStyle    FocusRing
+	ring         *ConcreteElement // Focus ring drawn over the tree, nil if hidden
+
+	mu         sync.Mutex // Guards posted and wake
+	posted     []func()
+	wake       func()
+	frameFuncs []func(time.Time)
+	stats      FrameStats
+	lastFrame  time.Time
+	timers     timerHeap
+	timerIDs   map[TimerID]*timer
+	lastTimer  TimerID
+}
+
+// NewScene creates a Scene with a new root element
+func NewScene() *Scene {
+	s := new(Scene)
+	s.root = NewRootElement()
+	s.root.scene = s
+	s.viewport = s.root.Area
+	s.overlay = NewAbstractElement(s.root, s.root.Area)
+	s.accels = make(map[Shortcut]func())
+	s.ctxMenus = make(map[IElement]func(image.Point))
+	s.tooltips = make(map[*Element]func(*Popup))
+	s.clock = SystemClock
+	s.ringStyle = DefaultFocusRing
+	s.timerIDs = make(map[TimerID]*timer)
+	return s
+}
+
+// Clock returns the clock used to run the animations of the scene
+func (s *Scene) Clock() Clock {
+	return s.clock
+}
+
+// SetClock changes the clock of the scene, tests use a FakeClock
+func (s *Scene) SetClock(c Clock) {
+	s.clock = c
+}
+
+// Update runs the posted functions, the timers that are due, the frame callbacks
+// and the animation functions with the time of the scene's clock. Frame calls it.
+func (s *Scene) Update() {
+	now := s.clock.Now()
+	s.runQueued(now)
+	s.Tick(now)
+}
+
+// Animate registers fn to be called on every frame with the time elapsed
+// since the previous frame, until it returns false
+func (s *Scene) Animate(fn func(dt time.Duration) bool) {
+	s.tickers = append(s.tickers, fn)
+}
+
+// Animating reports whether some animation function is registered
+func (s *Scene) Animating() bool {
+	return len(s.tickers) > 0
+}
+
+// Tick runs the animation functions as if the time was now
+func (s *Scene) Tick(now time.Time) {
+	var dt time.Duration
+	if !s.lastTick.IsZero() {
+		dt = now.Sub(s.lastTick)
+	}
+	s.lastTick = now
+	tickers := s.tickers
+	s.tickers = nil
+	for _, fn := range tickers {
+		if fn(dt) {
+			s.tickers = append(s.tickers, fn)
+		}
+	}
+	if len(s.tickers) == 0 {
+		// The next animation starts with no elapsed time
+		s.lastTick = time.Time{}
+	}
+}
+
+// AddAccelerator registers a function to call when the shortcut is pressed,
+// whichever element has the focus. It replaces any function previously
+// registered for the same shortcut.
+func (s *Scene) AddAccelerator(sc Shortcut, fn func()) {
+	s.accels[sc] = fn
+}
+
+// RemoveAccelerator unregisters the shortcut
+func (s *Scene) RemoveAccelerator(sc Shortcut) {
+	delete(s.accels, sc)
+}
+
+// SetContextMenu registers a function that opens a context menu at the given
+// point when the element is right-clicked. A nil open unregisters it.
+// Right clicks go to the innermost element under the cursor that has one.
+func (s *Scene) SetContextMenu(e IElement, open func(image.Point)) {
+	if open == nil {
+		delete(s.ctxMenus, e)
+		return
+	}
+	s.ctxMenus[e] = open
+}
+
+// Viewport returns the visible area of the scene
+func (s *Scene) Viewport() image.Rectangle {
+	return s.viewport
+}
+
+// SetViewport sets the visible area of the scene, windows call it when resized
+func (s *Scene) SetViewport(area image.Rectangle) {
+	s.viewport = area
+	for _, p := range s.popups {
+		if p.backdrop != nil {
+			p.backdrop.Area = area
+		}
+	}
+}
+
+// Root returns the root element of the scene
+func (s *Scene) Root() *AbstractElement {
+	return s.root
+}
+
+// SceneOf returns the Scene the element is attached to, or nil if its root
+// doesn't belong to a Scene
+func SceneOf(e IElement) *Scene {
+	for p := e.BaseElement().parent; p != nil; p = p.parent {
+		e = p
+	}
+	if root, ok := e.(*AbstractElement); ok {
+		return root.scene
+	}
+	return nil
+}
+
+// Invalidate marks the area covered by the element to be redrawn.
+// It does nothing if the element is not in a Scene.
+func Invalidate(e IElement) {
+	if s := SceneOf(e); s != nil {
+		s.InvalidateArea(Bounds(e))
+	}
+}
+
+// Bounds returns the smallest rectangle containing all visible concrete descendants of the element
+func Bounds(e IElement) (r image.Rectangle) {
+	li := visibleConcreteDescns(e)
+	for o := li.Front(); o != nil; o = o.Next() {
+		r = r.Union(o.Value.(*ConcreteElement).Area)
+	}
+	return r
+}
+
+// InvalidateArea marks an area of the scene to be redrawn
+func (s *Scene) InvalidateArea(area image.Rectangle) {
+	s.accessDirty = true
+	if area.Empty() {
+		return
+	}
+	for i, d := range s.dirty {
+		if d.Overlaps(area) {
+			s.dirty = append(s.dirty[:i], s.dirty[i+1:]...)
+			s.InvalidateArea(d.Union(area))
+			return
+		}
+	}
+	s.dirty = append(s.dirty, area)
+}
+
+// NeedsRedraw reports whether some area has been invalidated since the last RedrawDirty
+func (s *Scene) NeedsRedraw() bool {
+	return len(s.dirty) > 0
+}
+
+// RedrawDirty redraws all invalidated areas, and the focus ring
+func (s *Scene) RedrawDirty(backend RenderBackend) {
+	// The focused element may have moved
+	s.updateFocusRing()
+	dirty := s.dirty
+	s.dirty = nil
+	for _, area := range dirty {
+		redrawArea(area, backend, s.root, s.ring)
+	}
+	s.updateAccess()
+}
+
+// ElementAt returns the topmost concrete element that contains the point
+func (s *Scene) ElementAt(pt image.Point) *ConcreteElement {
+	return elementAt(pt, s.root)
+}
+
+func elementAt(pt image.Point, root *AbstractElement) (top *ConcreteElement) {
+	li := visibleConcreteDescns(root)
+	for o := li.Front(); o != nil; o = o.Next() {
+		e := o.Value.(*ConcreteElement)
+		if pt.In(e.Area) && (top == nil || top.IsBehind(e)) {
+			top = e
+		}
+	}
+	return top
+}
+
+// pathTo returns the element and its ancestors, from the root down
+func pathTo(e IElement) (path []IElement) {
+	for e != nil {
+		path = append(path, e)
+		p := e.BaseElement().parent
+		if p == nil {
+			break
+		}
+		e = p
+	}
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+	return path
+}
+
+func contains(l []IElement, e IElement) bool {
+	for _, o := range l {
+		if o == e {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Scene) updateHover(pt image.Point) {
+	var path []IElement
+	if top := s.ElementAt(pt); top != nil {
+		path = pathTo(top)
+		// Disabled elements and their descendants aren't hovered
+		for i, o := range path {
+			if o.BaseElement().disabled {
+				path = path[:i]
+				break
+			}
+		}
+	}
+	for i := len(s.hovered) - 1; i >= 0; i-- {
+		o := s.hovered[i]
+		if contains(path, o) {
+			continue
+		}
+		if h, ok := o.BaseElement().Handler.(hoverHandler); ok {
+			h.OnMouseLeave()
+		}
+	}
+	old := s.hovered
+	s.hovered = path
+	for _, o := range path {
+		if contains(old, o) {
+			continue
+		}
+		if h, ok := o.BaseElement().Handler.(hoverHandler); ok {
+			h.OnMouseEnter()
+		}
+	}
+}
+
+// GrabMouse makes all mouse events go to the element until the next mouse release,
+// wherever the cursor is
+func (s *Scene) GrabMouse(e IElement) {
+	s.grab = e
+}
+
+// HandleMouse dispatches a mouse event to the elements of the scene
+func (s *Scene) HandleMouse(evt *MouseEvent) {
+	s.updateHover(evt.Pos)
+	s.updateTooltip(evt)
+	if g := s.grab; g != nil {
+		if evt.Action == EventRelease {
+			s.grab = nil
+		}
+		if h, ok := g.BaseElement().Handler.(mouseHandler); ok {
+			h.OnMouseEvent(evt)
+		}
+		return
+	}
+	if evt.Action == EventPress {
+		s.keyboard = false
+		s.closePopupsOutside(evt.Pos)
+	}
+	p := s.popupAt(evt.Pos)
+	if p == nil && s.TopModal() != nil {
+		// Everything beneath a modal popup is blocked
+		return
+	}
+	if evt.Action == EventPress {
+		s.focusAt(evt.Pos)
+		if evt.Button == MouseButtonRight && s.openContextMenu(evt.Pos) {
+			return
+		}
+	}
+	if p != nil {
+		dispatchMouse(evt, p.AbstractElement)
+		return
+	}
+	for _, child := range s.root.children {
+		if child != IElement(s.overlay) {
+			dispatchMouse(evt, child)
+		}
+	}
+}
+
+func (s *Scene) openContextMenu(pt image.Point) bool {
+	for i := len(s.hovered) - 1; i >= 0; i-- {
+		if open, ok := s.ctxMenus[s.hovered[i]]; ok {
+			open(pt)
+			return true
+		}
+	}
+	return false
+}
+
+// focusAt gives the focus to the innermost focusable element under the point.
+// Clicks on popups without focusable elements leave the focus where it is,
+// so that the owner of a list keeps it.
+func (s *Scene) focusAt(pt image.Point) {
+	for i := len(s.hovered) - 1; i >= 0; i-- {
+		if isFocusable(s.hovered[i]) {
+			s.Focus(s.hovered[i])
+			return
+		}
+	}
+	if s.popupAt(pt) == nil {
+		s.Focus(nil)
+	}
+}
+
+func isFocusable(e IElement) bool {
+	b := e.BaseElement()
+	f, ok := b.Handler.(focusable)
+	return ok && f.Focusable() && b.VisibleInTree() && b.EnabledInTree()
+}
+
+// Focused returns the element that has the keyboard focus, or nil
+func (s *Scene) Focused() IElement {
+	return s.focused
+}
+
+// Focus gives the keyboard focus to the element, nil removes the focus.
+// While a modal popup is open, elements beneath it can't get the focus,
+// neither can hidden or disabled elements.
+func (s *Scene) Focus(e IElement) {
+	if e == s.focused || (e != nil && !s.canFocus(e)) {
+		return
+	}
+	old := s.focused
+	s.focused = e
+	s.focusVisible = s.keyboard
+	if e != nil {
+		rememberFocus(e)
+	}
+	if old != nil {
+		if h, ok := old.BaseElement().Handler.(focusHandler); ok {
+			h.OnBlur()
+		}
+	}
+	if e != nil {
+		if h, ok := e.BaseElement().Handler.(focusHandler); ok {
+			h.OnFocus()
+		}
+	}
+	s.updateFocusRing()
+	s.accessDirty = true
+	s.accessFocus()
+}
+
+// canFocus reports whether the element can be given the focus
+func (s *Scene) canFocus(e IElement) bool {
+	b := e.BaseElement()
+	return !s.blockedByModal(e) && b.VisibleInTree() && b.EnabledInTree()
+}
+
+// HandleKey dispatches a key event to the focused element and its ancestors,
+// unless it triggers an accelerator. Accelerators are disabled while a modal
+// popup is open.
+// An unhandled Tab or arrow key moves the focus, an unhandled Escape closes
+// the top popup.
+func (s *Scene) HandleKey(evt *KeyEvent) {
+	if evt.Action != EventRelease {
+		s.keyboard = true
+	}
+	if evt.Action == EventPress {
+		s.hideTooltip()
+		s.tip.clicked = true
+	}
+	if fn, ok := s.accels[Shortcut{evt.Key, evt.Mod}]; ok && s.TopModal() == nil {
+		if evt.Action == EventPress {
+			fn()
+		}
+		return
+	}
+	for _, o := range reversed(pathTo(s.focused)) {
+		if h, ok := o.BaseElement().Handler.(keyHandler); ok && h.OnKeyEvent(evt) {
+			return
+		}
+	}
+	if evt.Action == EventRelease {
+		return
+	}
+	switch evt.Key {
+	case KeyTab:
+		s.FocusNext(evt.Mod.Shift)
+	case KeyLeft, KeyRight, KeyUp, KeyDown:
+		if evt.Mod == (Modifiers{}) {
+			s.FocusToward(evt.Key)
+		}
+	case KeyEscape:
+		if p := s.TopPopup(); p != nil && !p.KeepOpen && !p.Modal {
+			p.Close()
+		}
+	}
+}
+
+// HandleChar sends typed text to the focused element
+func (s *Scene) HandleChar(evt *CharEvent) {
+	if s.focused == nil {
+		return
+	}
+	if h, ok := s.focused.BaseElement().Handler.(charHandler); ok {
+		h.OnCharEvent(evt)
+	}
+}
+
+func reversed(l []IElement) []IElement {
+	r := make([]IElement, len(l))
+	for i, o := range l {
+		r[len(l)-1-i] = o
+	}
+	return r
+}
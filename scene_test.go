@@ -0,0 +1,256 @@
+package gosui
+
+import (
+	"image"
+	"time"
+
+	chk "launchpad.net/gocheck"
+)
+
+type recHandler struct {
+	events []string
+	focus  bool
+}
+
+func (h *recHandler) OnMouseEvent(evt *MouseEvent) bool {
+	h.events = append(h.events, "mouse")
+	return true
+}
+
+func (h *recHandler) OnKeyEvent(evt *KeyEvent) bool {
+	h.events = append(h.events, "key")
+	return evt.Key == KeyEnter
+}
+
+func (h *recHandler) OnMouseEnter() { h.events = append(h.events, "enter") }
+func (h *recHandler) OnMouseLeave() { h.events = append(h.events, "leave") }
+func (h *recHandler) OnFocus()      { h.events = append(h.events, "focus") }
+func (h *recHandler) OnBlur()       { h.events = append(h.events, "blur") }
+func (h *recHandler) Focusable() bool {
+	return h.focus
+}
+
+func (s *MySuite) TestSceneFocusNext(c *chk.C) {
+	sc := NewScene()
+	a := NewRectElement(sc.Root(), MakeRect(0, 0, 10, 10))
+	a.Handler = &recHandler{focus: true}
+	box := NewAbstractElement(sc.Root(), MakeRect(0, 0, 100, 100))
+	NewRectElement(box, MakeRect(20, 20, 30, 30))
+	b := NewRectElement(box, MakeRect(40, 40, 50, 50))
+	b.Handler = &recHandler{focus: true}
+
+	sc.HandleKey(&KeyEvent{Key: KeyTab})
+	c.Check(sc.Focused(), chk.Equals, IElement(a))
+	sc.HandleKey(&KeyEvent{Key: KeyTab})
+	c.Check(sc.Focused(), chk.Equals, IElement(b))
+	sc.HandleKey(&KeyEvent{Key: KeyTab})
+	c.Check(sc.Focused(), chk.Equals, IElement(a))
+	sc.HandleKey(&KeyEvent{Key: KeyTab, Mod: Modifiers{Shift: true}})
+	c.Check(sc.Focused(), chk.Equals, IElement(b))
+	c.Check(a.Handler.(*recHandler).events, chk.DeepEquals,
+		[]string{"focus", "key", "blur", "focus", "key", "blur"})
+}
+
+func (s *MySuite) TestSceneKeyBubbles(c *chk.C) {
+	sc := NewScene()
+	box := NewAbstractElement(sc.Root(), MakeRect(0, 0, 100, 100))
+	boxH := &recHandler{}
+	box.Handler = boxH
+	e := NewRectElement(box, MakeRect(0, 0, 10, 10))
+	eH := &recHandler{focus: true}
+	e.Handler = eH
+	sc.Focus(e)
+
+	sc.HandleKey(&KeyEvent{Key: KeyA})
+	c.Check(eH.events, chk.DeepEquals, []string{"focus", "key"})
+	c.Check(boxH.events, chk.DeepEquals, []string{"key"})
+	sc.HandleKey(&KeyEvent{Key: KeyEnter})
+	c.Check(boxH.events, chk.DeepEquals, []string{"key"})
+}
+
+func (s *MySuite) TestSceneHoverAndGrab(c *chk.C) {
+	sc := NewScene()
+	bg := NewRectElement(sc.Root(), MakeRect(0, 0, 100, 100))
+	bgH := &recHandler{}
+	bg.Handler = bgH
+	top := NewRectElement(sc.Root(), MakeRect(10, 10, 20, 20))
+	top.SetZIndex(1)
+	topH := &recHandler{focus: true}
+	top.Handler = topH
+
+	sc.HandleMouse(&MouseEvent{Pos: image.Point{50, 50}, Action: EventMove})
+	sc.HandleMouse(&MouseEvent{Pos: image.Point{15, 15}, Action: EventMove})
+	c.Check(bgH.events, chk.DeepEquals, []string{"enter", "mouse", "leave", "mouse"})
+	c.Check(topH.events, chk.DeepEquals, []string{"enter", "mouse"})
+
+	topH.events = nil
+	sc.HandleMouse(&MouseEvent{Pos: image.Point{15, 15}, Action: EventPress})
+	c.Check(sc.Focused(), chk.Equals, IElement(top))
+	sc.GrabMouse(top)
+	sc.HandleMouse(&MouseEvent{Pos: image.Point{500, 500}, Action: EventRelease})
+	sc.HandleMouse(&MouseEvent{Pos: image.Point{500, 500}, Action: EventMove})
+	c.Check(topH.events, chk.DeepEquals, []string{"focus", "mouse", "leave", "mouse"})
+}
+
+func (s *MySuite) TestSceneInvalidate(c *chk.C) {
+	sc := NewScene()
+	r1 := NewRectElement(sc.Root(), MakeRect(0, 0, 100, 100))
+	NewRectElement(sc.Root(), MakeRect(50, 50, 120, 120))
+	NewRectElement(sc.Root(), MakeRect(200, 200, 300, 300))
+	c.Check(sc.NeedsRedraw(), chk.Equals, false)
+	Invalidate(r1)
+	c.Check(sc.NeedsRedraw(), chk.Equals, true)
+	backend := new(DummyBackend)
+	sc.RedrawDirty(backend)
+	c.Check(backend.c, chk.Equals, 2)
+	c.Check(sc.NeedsRedraw(), chk.Equals, false)
+}
+
+func (s *MySuite) TestAccelerators(c *chk.C) {
+	sc := NewScene()
+	e := NewRectElement(sc.Root(), MakeRect(0, 0, 10, 10))
+	eH := &recHandler{focus: true}
+	e.Handler = eH
+	sc.Focus(e)
+	saves := 0
+	save := Shortcut{KeyA + 'S' - 'A', Modifiers{Control: true}}
+	sc.AddAccelerator(save, func() { saves++ })
+	sc.HandleKey(&KeyEvent{Key: save.Key, Mod: save.Mod, Action: EventPress})
+	sc.HandleKey(&KeyEvent{Key: save.Key, Mod: save.Mod, Action: EventRelease})
+	sc.HandleKey(&KeyEvent{Key: save.Key, Action: EventPress})
+	c.Check(saves, chk.Equals, 1)
+	c.Check(eH.events, chk.DeepEquals, []string{"focus", "key"})
+	c.Check(save.String(), chk.Equals, "Ctrl+S")
+	c.Check(Shortcut{KeyF1 + 9, Modifiers{Shift: true, Alt: true}}.String(), chk.Equals, "Alt+Shift+F10")
+}
+
+func (s *MySuite) TestContextMenu(c *chk.C) {
+	sc := NewScene()
+	box := NewAbstractElement(sc.Root(), MakeRect(0, 0, 100, 100))
+	NewRectElement(box, MakeRect(0, 0, 100, 100))
+	inner := NewRectElement(box, MakeRect(0, 0, 50, 50))
+	inner.SetZIndex(1)
+	var opened []image.Point
+	sc.SetContextMenu(box, func(pt image.Point) { opened = append(opened, pt) })
+	sc.HandleMouse(&MouseEvent{Pos: image.Point{10, 10}, Button: MouseButtonRight, Action: EventPress})
+	sc.HandleMouse(&MouseEvent{Pos: image.Point{10, 10}, Button: MouseButtonLeft, Action: EventPress})
+	sc.SetContextMenu(inner, func(pt image.Point) {})
+	sc.HandleMouse(&MouseEvent{Pos: image.Point{10, 10}, Button: MouseButtonRight, Action: EventPress})
+	sc.HandleMouse(&MouseEvent{Pos: image.Point{70, 70}, Button: MouseButtonRight, Action: EventPress})
+	c.Check(opened, chk.DeepEquals, []image.Point{{10, 10}, {70, 70}})
+}
+
+func (s *MySuite) TestAnimate(c *chk.C) {
+	sc := NewScene()
+	var steps []time.Duration
+	sc.Animate(func(dt time.Duration) bool {
+		steps = append(steps, dt)
+		return len(steps) < 3
+	})
+	c.Check(sc.Animating(), chk.Equals, true)
+	t0 := time.Unix(100, 0)
+	for i := 0; i < 4; i++ {
+		sc.Tick(t0.Add(time.Duration(i) * 10 * time.Millisecond))
+	}
+	c.Check(steps, chk.DeepEquals, []time.Duration{0, 10 * time.Millisecond, 10 * time.Millisecond})
+	c.Check(sc.Animating(), chk.Equals, false)
+
+	// An animation started after a pause doesn't get the pause as its first step
+	sc.Animate(func(dt time.Duration) bool {
+		steps = append(steps, dt)
+		return false
+	})
+	sc.Tick(t0.Add(time.Hour))
+	c.Check(steps[3], chk.Equals, time.Duration(0))
+}
+
+func (s *MySuite) TestVisibility(c *chk.C) {
+	sc := NewScene()
+	box := NewAbstractElement(sc.Root(), MakeRect(0, 0, 100, 100))
+	under := NewRectElement(sc.Root(), MakeRect(0, 0, 100, 100))
+	r := NewRectElement(box, MakeRect(10, 10, 40, 40))
+	r.SetZIndex(1)
+	NewRectElement(box, MakeRect(50, 50, 60, 60))
+	h := &recHandler{focus: true}
+	r.Handler = h
+	sc.Focus(r)
+
+	SetVisible(box, false)
+	c.Check(box.Visible(), chk.Equals, false)
+	c.Check(r.Visible(), chk.Equals, true)
+	c.Check(r.VisibleInTree(), chk.Equals, false)
+	c.Check(sc.Focused(), chk.IsNil)
+	c.Check(sc.dirty, chk.DeepEquals, []image.Rectangle{MakeRect(10, 10, 60, 60)})
+	c.Check(sc.ElementAt(image.Point{20, 20}), chk.Equals, under)
+	c.Check(Bounds(box), chk.Equals, image.Rectangle{})
+	backend := new(DummyBackend)
+	sc.RedrawDirty(backend)
+	c.Check(backend.c, chk.Equals, 1)
+	sc.Root().Draw(backend)
+	c.Check(backend.c, chk.Equals, 2)
+
+	sc.HandleMouse(&MouseEvent{Pos: image.Point{20, 20}, Action: EventPress})
+	c.Check(h.events, chk.DeepEquals, []string{"focus", "blur"})
+	sc.Focus(r)
+	c.Check(sc.Focused(), chk.IsNil)
+
+	SetVisible(box, true)
+	SetVisible(box, true)
+	c.Check(sc.dirty, chk.DeepEquals, []image.Rectangle{MakeRect(10, 10, 60, 60)})
+	c.Check(sc.ElementAt(image.Point{20, 20}), chk.Equals, r)
+}
+
+func (s *MySuite) TestRemoveChild(c *chk.C) {
+	sc := NewScene()
+	box := NewAbstractElement(sc.Root(), MakeRect(0, 0, 100, 100))
+	boxH := new(recHandler)
+	box.Handler = boxH
+	r := NewRectElement(box, MakeRect(10, 10, 40, 40))
+	h := &recHandler{focus: true}
+	r.Handler = h
+	sc.HandleMouse(&MouseEvent{Pos: image.Point{20, 20}, Action: EventPress})
+	sc.GrabMouse(r)
+	c.Check(sc.Focused(), chk.Equals, IElement(r))
+
+	// Detached elements are left, blurred and lose the grab
+	sc.Root().RemoveChild(box)
+	c.Check(sc.Focused(), chk.IsNil)
+	c.Check(sc.hovered, chk.HasLen, 1)
+	c.Check(sc.grab, chk.IsNil)
+	c.Check(h.events, chk.DeepEquals, []string{"enter", "focus", "mouse", "blur", "leave"})
+	c.Check(boxH.events[len(boxH.events)-1], chk.Equals, "leave")
+}
+
+func (s *MySuite) TestEnabled(c *chk.C) {
+	sc := NewScene()
+	box := NewAbstractElement(sc.Root(), MakeRect(0, 0, 100, 100))
+	boxH := new(recHandler)
+	box.Handler = boxH
+	r := NewRectElement(box, MakeRect(10, 10, 40, 40))
+	h := &recHandler{focus: true}
+	r.Handler = h
+
+	SetEnabled(box, false)
+	c.Check(r.Enabled(), chk.Equals, true)
+	c.Check(r.EnabledInTree(), chk.Equals, false)
+	// Disabled elements may look different, so they are redrawn
+	c.Check(sc.dirty, chk.DeepEquals, []image.Rectangle{r.Area})
+	sc.RedrawDirty(&DummyBackend{})
+	SetEnabled(box, false)
+	c.Check(sc.NeedsRedraw(), chk.Equals, false)
+	sc.HandleMouse(&MouseEvent{Pos: image.Point{20, 20}, Action: EventPress})
+	sc.FocusNext(false)
+	c.Check(sc.Focused(), chk.IsNil)
+	c.Check(h.events, chk.HasLen, 0)
+	c.Check(boxH.events, chk.HasLen, 0)
+	c.Check(sc.ElementAt(image.Point{20, 20}), chk.Equals, r)
+
+	SetEnabled(box, true)
+	sc.HandleMouse(&MouseEvent{Pos: image.Point{20, 20}, Action: EventMove})
+	c.Check(boxH.events, chk.DeepEquals, []string{"enter", "mouse"})
+	c.Check(h.events, chk.DeepEquals, []string{"enter", "mouse"})
+	SetEnabled(r, false)
+	sc.HandleMouse(&MouseEvent{Pos: image.Point{20, 20}, Action: EventMove})
+	c.Check(boxH.events, chk.DeepEquals, []string{"enter", "mouse", "mouse"})
+	c.Check(h.events, chk.DeepEquals, []string{"enter", "mouse", "leave"})
+}
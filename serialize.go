@@ -0,0 +1,505 @@
+package gosui
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	"io"
+	"math"
+	"reflect"
+)
+
+// Kinds of nodes, in both formats
+const (
+	kindGroup  = "group"
+	kindRect   = "rect"
+	kindText   = "text"
+	kindCustom = "custom" // Only used in the binary format, followed by the name of the shape
+)
+
+var (
+	shapeTypes = make(map[string]reflect.Type)
+	shapeNames = make(map[reflect.Type]string)
+)
+
+// RegisterShape makes a type of shape encodable under name. The shape is
+// encoded as JSON, so its type must be marshalable with encoding/json.
+// s is only used for its type, which is usually a pointer to a struct.
+func RegisterShape(name string, s Shape) {
+	t := reflect.TypeOf(s)
+	if _, dup := shapeTypes[name]; dup || name == kindGroup || name == kindRect || name == kindText || name == kindCustom {
+		panic(fmt.Sprintf("gosui: shape name %q already registered", name))
+	}
+	shapeTypes[name] = t
+	shapeNames[t] = name
+}
+
+// newShape creates a shape of a registered type from its JSON encoding
+func newShape(name string, data []byte) (Shape, error) {
+	t, ok := shapeTypes[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown shape %q", name)
+	}
+	var v reflect.Value
+	if t.Kind() == reflect.Ptr {
+		v = reflect.New(t.Elem())
+	} else {
+		v = reflect.New(t)
+	}
+	if err := json.Unmarshal(data, v.Interface()); err != nil {
+		return nil, fmt.Errorf("shape %q: %v", name, err)
+	}
+	if t.Kind() != reflect.Ptr {
+		v = v.Elem()
+	}
+	return v.Interface().(Shape), nil
+}
+
+// node is an element as encoded in JSON, the binary format has the same fields
+type node struct {
+	Kind        string          `json:"kind"`
+	Type        string          `json:"type,omitempty"`
+	ID          string          `json:"id,omitempty"`
+	Classes     []string        `json:"classes,omitempty"`
+	Area        [4]int          `json:"area"`
+	Z           float32         `json:"z,omitempty"`
+	Opacity     *float64        `json:"opacity,omitempty"`
+	Hidden      bool            `json:"hidden,omitempty"`
+	Disabled    bool            `json:"disabled,omitempty"`
+	Fill        string          `json:"fill,omitempty"`
+	Stroke      string          `json:"stroke,omitempty"`
+	StrokeWidth int             `json:"strokeWidth,omitempty"`
+	Corners     *[4]int         `json:"corners,omitempty"`
+	Text        *textNode       `json:"text,omitempty"`
+	Shape       json.RawMessage `json:"shape,omitempty"` // Registered shapes
+	Children    []*node         `json:"children,omitempty"`
+}
+
+type textNode struct {
+	Content  string `json:"content"`
+	Font     Font   `json:"font"`
+	Origin   [2]int `json:"origin"`
+	Editable bool   `json:"editable,omitempty"`
+}
+
+func formatColor(c Color) string {
+	if c == (Color{}) {
+		return ""
+	}
+	return fmt.Sprintf("#%02x%02x%02x%02x", c.R, c.G, c.B, c.A)
+}
+
+func parseNodeColor(s string) (Color, error) {
+	if s == "" {
+		return Color{}, nil
+	}
+	return ParseColor(s)
+}
+
+// toNode converts e and its descendants, leaving out the overlay of scenes
+func toNode(e IElement) (*node, error) {
+	b := e.BaseElement()
+	n := &node{
+		Type:        b.typ,
+		ID:          b.id,
+		Classes:     b.classes,
+		Area:        [4]int{b.Area.Min.X, b.Area.Min.Y, b.Area.Max.X, b.Area.Max.Y},
+		Hidden:      b.hidden,
+		Disabled:    b.disabled,
+		Fill:        formatColor(b.FillColor),
+		Stroke:      formatColor(b.StrokeColor),
+		StrokeWidth: b.StrokeWidth,
+	}
+	if b.transparency != 0 {
+		o := b.Opacity()
+		n.Opacity = &o
+	}
+	c, ok := e.(*ConcreteElement)
+	if !ok {
+		n.Kind = kindGroup
+		a, ok := e.(*AbstractElement)
+		if !ok {
+			return nil, fmt.Errorf("can't encode elements of type %T", e)
+		}
+		for _, child := range a.children {
+			if a.scene != nil && child == IElement(a.scene.overlay) {
+				continue
+			}
+			cn, err := toNode(child)
+			if err != nil {
+				return nil, err
+			}
+			n.Children = append(n.Children, cn)
+		}
+		return n, nil
+	}
+	n.Z = c.zIndex
+	switch sh := c.shape.(type) {
+	case *RectShape:
+		n.Kind = kindRect
+		if sh.cornerRadiis != ([4]int{}) {
+			corners := sh.cornerRadiis
+			n.Corners = &corners
+		}
+	case *TextShape:
+		n.Kind = kindText
+		n.Text = &textNode{sh.Content, sh.Font, [2]int{sh.origin.X, sh.origin.Y}, sh.Editable}
+	default:
+		name, ok := shapeNames[reflect.TypeOf(sh)]
+		if !ok {
+			return nil, fmt.Errorf("shape type %T isn't registered", sh)
+		}
+		data, err := json.Marshal(sh)
+		if err != nil {
+			return nil, fmt.Errorf("shape %q: %v", name, err)
+		}
+		n.Kind, n.Shape = name, data
+	}
+	return n, nil
+}
+
+// build creates the elements of the node as a child of parent, which can be nil
+func (n *node) build(parent *AbstractElement) (IElement, error) {
+	area := MakeRect(n.Area[0], n.Area[1], n.Area[2], n.Area[3])
+	var e IElement
+	switch n.Kind {
+	case kindGroup:
+		e = new(AbstractElement)
+	case kindRect:
+		c := &ConcreteElement{shape: new(RectShape)}
+		if n.Corners != nil {
+			c.RectShape().cornerRadiis = *n.Corners
+		}
+		e = c
+	case kindText:
+		if n.Text == nil {
+			return nil, errors.New("text element without text")
+		}
+		t := n.Text
+		c := &ConcreteElement{shape: &TextShape{Content: t.Content, Font: t.Font, Editable: t.Editable, origin: image.Point{t.Origin[0], t.Origin[1]}}}
+		if t.Editable {
+			c.Handler = &InputHandler{e: c}
+		}
+		e = c
+	default:
+		sh, err := newShape(n.Kind, n.Shape)
+		if err != nil {
+			return nil, err
+		}
+		e = &ConcreteElement{shape: sh}
+	}
+	if parent != nil {
+		parent.AddChild(e)
+	}
+	b := e.BaseElement()
+	b.Area = area
+	b.typ, b.hidden, b.disabled = n.Type, n.Hidden, n.Disabled
+	b.SetID(n.ID)
+	b.AddClass(n.Classes...)
+	if n.Opacity != nil {
+		b.SetOpacity(*n.Opacity)
+	}
+	var err error
+	if b.FillColor, err = parseNodeColor(n.Fill); err != nil {
+		return nil, err
+	}
+	if b.StrokeColor, err = parseNodeColor(n.Stroke); err != nil {
+		return nil, err
+	}
+	b.StrokeWidth = n.StrokeWidth
+	if c, ok := e.(*ConcreteElement); ok {
+		c.zIndex = n.Z
+		if len(n.Children) > 0 {
+			return nil, fmt.Errorf("%s element with children", n.Kind)
+		}
+		return e, nil
+	}
+	for _, cn := range n.Children {
+		if _, err := cn.build(e.(*AbstractElement)); err != nil {
+			return nil, err
+		}
+	}
+	return e, nil
+}
+
+// decode builds a decoded tree, it's only added to parent once complete
+func decode(parent *AbstractElement, n *node) (IElement, error) {
+	e, err := n.build(nil)
+	if err != nil || parent == nil {
+		return e, err
+	}
+	parent.AddChild(e)
+	if c, ok := e.(*ConcreteElement); ok {
+		c.zIndex = n.Z
+	}
+	Invalidate(e)
+	return e, nil
+}
+
+// EncodeJSON encodes the element and its descendants to JSON: their
+// structure, areas, z-indexes, paints, shapes, ids, style types and classes,
+// visibility and enabled state. Handlers aren't encoded, except that editable
+// texts become text inputs again when decoded. The overlay of popups of a
+// scene is left out.
+func EncodeJSON(e IElement) ([]byte, error) {
+	n, err := toNode(e)
+	if err != nil {
+		return nil, err
+	}
+	return json.MarshalIndent(n, "", "\t")
+}
+
+// DecodeJSON builds the elements encoded by EncodeJSON as a child of parent,
+// or as a new root if parent is nil, and returns the top one
+func DecodeJSON(parent *AbstractElement, data []byte) (IElement, error) {
+	n := new(node)
+	if err := json.Unmarshal(data, n); err != nil {
+		return nil, err
+	}
+	return decode(parent, n)
+}
+
+// binaryMagic starts the binary encoding, followed by the version of the format
+var binaryMagic = []byte("GSUI\x01")
+
+// EncodeBinary encodes the element and its descendants like EncodeJSON, in a
+// compact binary format made of varints and length-prefixed strings
+func EncodeBinary(e IElement) ([]byte, error) {
+	n, err := toNode(e)
+	if err != nil {
+		return nil, err
+	}
+	w := &binWriter{}
+	w.buf.Write(binaryMagic)
+	w.node(n)
+	return w.buf.Bytes(), nil
+}
+
+// DecodeBinary builds the elements encoded by EncodeBinary as a child of parent,
+// or as a new root if parent is nil, and returns the top one
+func DecodeBinary(parent *AbstractElement, data []byte) (IElement, error) {
+	if !bytes.HasPrefix(data, binaryMagic) {
+		return nil, errors.New("not a binary element tree")
+	}
+	r := &binReader{r: bytes.NewReader(data[len(binaryMagic):])}
+	n := r.node()
+	if r.err != nil {
+		if r.err == io.EOF {
+			r.err = io.ErrUnexpectedEOF
+		}
+		return nil, r.err
+	}
+	return decode(parent, n)
+}
+
+// Flags of binary nodes
+const (
+	flagHidden = 1 << iota
+	flagDisabled
+	flagOpacity
+	flagCorners
+)
+
+type binWriter struct {
+	buf bytes.Buffer
+	tmp [binary.MaxVarintLen64]byte
+}
+
+func (w *binWriter) uint(v uint64) {
+	w.buf.Write(w.tmp[:binary.PutUvarint(w.tmp[:], v)])
+}
+
+func (w *binWriter) int(v int) {
+	w.buf.Write(w.tmp[:binary.PutVarint(w.tmp[:], int64(v))])
+}
+
+func (w *binWriter) string(s string) {
+	w.uint(uint64(len(s)))
+	w.buf.WriteString(s)
+}
+
+func (w *binWriter) color(s string) {
+	// Colors of nodes come from formatColor, they can't be invalid
+	c, _ := parseNodeColor(s)
+	w.buf.Write([]byte{c.R, c.G, c.B, c.A})
+}
+
+func (w *binWriter) node(n *node) {
+	kind := n.Kind
+	if n.Shape != nil {
+		kind = kindCustom
+	}
+	w.string(kind)
+	if kind == kindCustom {
+		w.string(n.Kind)
+		w.string(string(n.Shape))
+	}
+	w.string(n.Type)
+	w.string(n.ID)
+	w.uint(uint64(len(n.Classes)))
+	for _, c := range n.Classes {
+		w.string(c)
+	}
+	for _, v := range n.Area {
+		w.int(v)
+	}
+	w.uint(uint64(math.Float32bits(n.Z)))
+	var flags uint64
+	if n.Hidden {
+		flags |= flagHidden
+	}
+	if n.Disabled {
+		flags |= flagDisabled
+	}
+	if n.Opacity != nil {
+		flags |= flagOpacity
+	}
+	if n.Corners != nil {
+		flags |= flagCorners
+	}
+	w.uint(flags)
+	if n.Opacity != nil {
+		w.uint(math.Float64bits(*n.Opacity))
+	}
+	w.color(n.Fill)
+	w.color(n.Stroke)
+	w.int(n.StrokeWidth)
+	if n.Corners != nil {
+		for _, v := range n.Corners {
+			w.int(v)
+		}
+	}
+	switch kind {
+	case kindText:
+		t := n.Text
+		w.string(t.Content)
+		w.string(t.Font.Family)
+		w.int(t.Font.Size)
+		var style uint64
+		if t.Font.Style.Bold {
+			style |= 1
+		}
+		if t.Font.Style.Italic {
+			style |= 2
+		}
+		if t.Editable {
+			style |= 4
+		}
+		w.uint(style)
+		w.int(t.Origin[0])
+		w.int(t.Origin[1])
+	case kindGroup:
+		w.uint(uint64(len(n.Children)))
+		for _, c := range n.Children {
+			w.node(c)
+		}
+	}
+}
+
+// binReader reads binary nodes, keeping the first error
+type binReader struct {
+	r   *bytes.Reader
+	err error
+}
+
+func (r *binReader) uint() uint64 {
+	if r.err != nil {
+		return 0
+	}
+	v, err := binary.ReadUvarint(r.r)
+	r.err = err
+	return v
+}
+
+func (r *binReader) int() int {
+	if r.err != nil {
+		return 0
+	}
+	v, err := binary.ReadVarint(r.r)
+	r.err = err
+	return int(v)
+}
+
+func (r *binReader) string() string {
+	n := r.uint()
+	if r.err != nil {
+		return ""
+	}
+	if n > uint64(r.r.Len()) {
+		r.err = io.ErrUnexpectedEOF
+		return ""
+	}
+	b := make([]byte, n)
+	_, r.err = io.ReadFull(r.r, b)
+	return string(b)
+}
+
+func (r *binReader) color() string {
+	var c [4]byte
+	if r.err == nil {
+		_, r.err = io.ReadFull(r.r, c[:])
+	}
+	return formatColor(Color{c[0], c[1], c[2], c[3]})
+}
+
+func (r *binReader) node() *node {
+	n := &node{Kind: r.string()}
+	if n.Kind == kindCustom {
+		n.Kind = r.string()
+		n.Shape = json.RawMessage(r.string())
+	}
+	n.Type = r.string()
+	n.ID = r.string()
+	if count := r.uint(); count > 0 && r.err == nil {
+		if count > uint64(r.r.Len()) {
+			r.err = io.ErrUnexpectedEOF
+			return nil
+		}
+		n.Classes = make([]string, count)
+		for i := range n.Classes {
+			n.Classes[i] = r.string()
+		}
+	}
+	for i := range n.Area {
+		n.Area[i] = r.int()
+	}
+	n.Z = math.Float32frombits(uint32(r.uint()))
+	flags := r.uint()
+	n.Hidden, n.Disabled = flags&flagHidden != 0, flags&flagDisabled != 0
+	if flags&flagOpacity != 0 {
+		o := math.Float64frombits(r.uint())
+		n.Opacity = &o
+	}
+	n.Fill = r.color()
+	n.Stroke = r.color()
+	n.StrokeWidth = r.int()
+	if flags&flagCorners != 0 {
+		n.Corners = new([4]int)
+		for i := range n.Corners {
+			n.Corners[i] = r.int()
+		}
+	}
+	switch n.Kind {
+	case kindText:
+		t := &textNode{Content: r.string()}
+		t.Font.Family = r.string()
+		t.Font.Size = r.int()
+		style := r.uint()
+		t.Font.Style = FontStyle{Bold: style&1 != 0, Italic: style&2 != 0}
+		t.Editable = style&4 != 0
+		t.Origin = [2]int{r.int(), r.int()}
+		n.Text = t
+	case kindGroup:
+		count := r.uint()
+		for i := uint64(0); i < count && r.err == nil; i++ {
+			n.Children = append(n.Children, r.node())
+		}
+	}
+	if r.err != nil {
+		return nil
+	}
+	return n
+}
@@ -0,0 +1,112 @@
+package gosui
+
+import (
+	"image"
+
+	chk "launchpad.net/gocheck"
+)
+
+// starShape is a shape unknown to the package, drawn as a square
+type starShape struct {
+	Points int
+}
+
+func (s *starShape) Render(e *ConcreteElement, backend DrawBackend) {
+	backend.DrawRect(e.Area, [4]int{}, e.Paint)
+}
+
+func init() {
+	RegisterShape("star", &starShape{})
+}
+
+func serializeTree() *Scene {
+	sc := NewScene()
+	box := NewAbstractElement(sc.Root(), MakeRect(10, 10, 200, 200))
+	box.SetID("box")
+	box.SetType("panel")
+	box.AddClass("dark", "wide")
+	box.SetOpacity(0.5)
+	r := NewRectElement(box, MakeRect(10, 10, 100, 50))
+	r.Paint = Paint{Color{255, 0, 0, 255}, 2, Color{0, 0, 0, 128}}
+	r.RectShape().SetCornerRadiis(RectCornersRad{1, 2, 3, 4})
+	r.SetZIndex(1.5)
+	t := NewTextElement(box, 20, 80, Font{"DejaVu Sans", 12, BoldItalic}, false)
+	t.TextShape().Content = "Héllo"
+	t.FillColor = Color{0, 0, 255, 255}
+	SetVisible(t, false)
+	in := NewTextInputElement(sc.Root(), 20, 250, Font{"Arial", 14, Regular})
+	in.Input().SetText("typed")
+	SetEnabled(in, false)
+	NewShapeElement(sc.Root(), MakeRect(300, 300, 320, 320), &starShape{5})
+	return sc
+}
+
+func (s *MySuite) TestSerialize(c *chk.C) {
+	sc := serializeTree()
+	js, err := EncodeJSON(sc.Root())
+	c.Assert(err, chk.IsNil)
+	bin, err := EncodeBinary(sc.Root())
+	c.Assert(err, chk.IsNil)
+	c.Check(len(bin) < len(js)/3, chk.Equals, true, chk.Commentf("%d bytes in binary, %d in JSON", len(bin), len(js)))
+
+	e, err := DecodeJSON(nil, js)
+	c.Assert(err, chk.IsNil)
+	again, err := EncodeJSON(e)
+	c.Check(string(again), chk.Equals, string(js))
+	e, err = DecodeBinary(nil, bin)
+	c.Assert(err, chk.IsNil)
+	again, err = EncodeJSON(e)
+	c.Check(string(again), chk.Equals, string(js))
+
+	root := e.(*AbstractElement)
+	box := root.FindByID("box").(*AbstractElement)
+	c.Check(TypeOf(box), chk.Equals, "panel")
+	c.Check(box.Classes(), chk.DeepEquals, []string{"dark", "wide"})
+	c.Check(box.Opacity(), chk.Equals, 0.5)
+	r := box.Children()[0].(*ConcreteElement)
+	c.Check(r.Paint, chk.Equals, Paint{Color{255, 0, 0, 255}, 2, Color{0, 0, 0, 128}})
+	c.Check(r.RectShape().CornerRadiis(), chk.Equals, RectCornersRad{1, 2, 3, 4})
+	c.Check(r.ZIndex(), chk.Equals, float32(1.5))
+	t := box.Children()[1].(*ConcreteElement)
+	c.Check(*t.TextShape(), chk.Equals, TextShape{Content: "Héllo", Font: Font{"DejaVu Sans", 12, BoldItalic}, origin: image.Point{20, 80}})
+	c.Check(t.Visible(), chk.Equals, false)
+	in := root.Children()[1].(*ConcreteElement)
+	c.Check(in.Input().Text(), chk.Equals, "typed")
+	c.Check(in.Enabled(), chk.Equals, false)
+	star := root.Children()[2].(*ConcreteElement)
+	c.Check(star.Shape(), chk.DeepEquals, &starShape{5})
+
+	// Decoding into a scene
+	sc2 := NewScene()
+	n := len(sc2.Root().Children())
+	e, err = DecodeBinary(sc2.Root(), bin)
+	c.Assert(err, chk.IsNil)
+	c.Check(sc2.Root().Children()[n:], chk.DeepEquals, []IElement{e})
+	c.Check(sc2.Root().FindByID("box"), chk.NotNil)
+	c.Check(sc2.NeedsRedraw(), chk.Equals, true)
+}
+
+func (s *MySuite) TestSerializeErrors(c *chk.C) {
+	sc := NewScene()
+	NewShapeElement(sc.Root(), MakeRect(0, 0, 1, 1), unknownShape{})
+	_, err := EncodeJSON(sc.Root())
+	c.Check(err, chk.ErrorMatches, `shape type gosui.unknownShape isn't registered`)
+
+	n := len(sc.Root().Children())
+	_, err = DecodeJSON(sc.Root(), []byte(`{"kind": "group", "children": [{"kind": "rect"}, {"kind": "blob"}]}`))
+	c.Check(err, chk.ErrorMatches, `unknown shape "blob"`)
+	_, err = DecodeJSON(sc.Root(), []byte(`{"kind": "rect", "fill": "red"}`))
+	c.Check(err, chk.ErrorMatches, `invalid color "red"`)
+	c.Check(sc.Root().Children(), chk.HasLen, n)
+
+	bin, err := EncodeBinary(serializeTree().Root())
+	c.Assert(err, chk.IsNil)
+	_, err = DecodeBinary(nil, bin[:len(bin)-3])
+	c.Check(err, chk.ErrorMatches, `unexpected EOF`)
+	_, err = DecodeBinary(nil, []byte(`{"kind": "rect"}`))
+	c.Check(err, chk.ErrorMatches, `not a binary element tree`)
+}
+
+type unknownShape struct{}
+
+func (unknownShape) Render(e *ConcreteElement, backend DrawBackend) {}
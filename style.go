@@ -0,0 +1,662 @@
+package gosui
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// SetType sets the type name of the element, matched by the type selectors of
+// stylesheets. Widgets set it to names like "button".
+func (e *Element) SetType(t string) {
+	e.typ = t
+}
+
+// TypeOf returns the type name of the element. Unless set with SetType, it's
+// "rect" or "text" for ConcreteElements, "group" for AbstractElements and "root" for roots.
+func TypeOf(e IElement) string {
+	b := e.BaseElement()
+	switch {
+	case b.typ != "":
+		return b.typ
+	case b.parent == nil && !e.IsConcrete():
+		return "root"
+	case !e.IsConcrete():
+		return "group"
+	case e.(*ConcreteElement).IsText():
+		return "text"
+	}
+	return "rect"
+}
+
+// AddClass adds style classes to the element
+func (e *Element) AddClass(names ...string) {
+	for _, n := range names {
+		if !e.HasClass(n) {
+			e.classes = append(e.classes, n)
+			e.updateIndex("."+n, true)
+		}
+	}
+}
+
+// RemoveClass removes a style class from the element
+func (e *Element) RemoveClass(name string) {
+	for i, c := range e.classes {
+		if c == name {
+			e.updateIndex("."+name, false)
+			e.classes = append(e.classes[:i], e.classes[i+1:]...)
+			return
+		}
+	}
+}
+
+// HasClass reports whether the element has the style class
+func (e *Element) HasClass(name string) bool {
+	for _, c := range e.classes {
+		if c == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Classes returns the style classes of the element
+func (e *Element) Classes() []string {
+	return e.classes
+}
+
+// StyleState is the state of an element matched by the pseudo-classes of stylesheets
+type StyleState struct {
+	Hovered, Pressed, Focused, Disabled bool
+}
+
+// stateHandler is implemented by handlers that have a state for stylesheets
+type stateHandler interface {
+	StyleState() StyleState
+}
+
+// StyleProp is a set of style properties
+type StyleProp uint
+
+const (
+	PropFill StyleProp = 1 << iota
+	PropStroke
+	PropStrokeWidth
+	PropCorners
+	PropColor // Color of text, inherited
+	PropAccent
+	PropBackground
+	PropFontFamily // Font properties are inherited
+	PropFontSize
+	PropFontStyle
+	PropPadding
+
+	inheritedProps = PropColor | PropFontFamily | PropFontSize | PropFontStyle
+)
+
+// StyleProps holds the values of style properties, only those in Set are meaningful
+type StyleProps struct {
+	Set StyleProp
+	Paint
+	Corners    RectCornersRad
+	Color      Color
+	Accent     Color
+	Background Color
+	Font       Font
+	Padding    int
+}
+
+// Has reports whether the property is set
+func (p *StyleProps) Has(prop StyleProp) bool {
+	return p.Set&prop != 0
+}
+
+// merge overrides the properties of p with the ones set in q, keeping only those in mask
+func (p *StyleProps) merge(q *StyleProps, mask StyleProp) {
+	set := q.Set & mask
+	if set&PropFill != 0 {
+		p.FillColor = q.FillColor
+	}
+	if set&PropStroke != 0 {
+		p.StrokeColor = q.StrokeColor
+	}
+	if set&PropStrokeWidth != 0 {
+		p.StrokeWidth = q.StrokeWidth
+	}
+	if set&PropCorners != 0 {
+		p.Corners = q.Corners
+	}
+	if set&PropColor != 0 {
+		p.Color = q.Color
+	}
+	if set&PropAccent != 0 {
+		p.Accent = q.Accent
+	}
+	if set&PropBackground != 0 {
+		p.Background = q.Background
+	}
+	if set&PropFontFamily != 0 {
+		p.Font.Family = q.Font.Family
+	}
+	if set&PropFontSize != 0 {
+		p.Font.Size = q.Font.Size
+	}
+	if set&PropFontStyle != 0 {
+		p.Font.Style = q.Font.Style
+	}
+	if set&PropPadding != 0 {
+		p.Padding = q.Padding
+	}
+	p.Set |= set
+}
+
+// selector matches elements by type, id, classes and state, like button.primary:hover
+type selector struct {
+	typ     string // "" matches any type
+	id      string // "" matches any id
+	classes []string
+	state   StyleState
+}
+
+func (s *selector) specificity() int {
+	n := 10 * len(s.classes)
+	if s.id != "" {
+		n += 100
+	}
+	for _, b := range []bool{s.state.Hovered, s.state.Pressed, s.state.Focused, s.state.Disabled} {
+		if b {
+			n += 10
+		}
+	}
+	if s.typ != "" {
+		n++
+	}
+	return n
+}
+
+func (s *selector) matches(e IElement, st StyleState) bool {
+	if s.typ != "" && s.typ != TypeOf(e) {
+		return false
+	}
+	b := e.BaseElement()
+	if s.id != "" && s.id != b.id {
+		return false
+	}
+	for _, c := range s.classes {
+		if !b.HasClass(c) {
+			return false
+		}
+	}
+	return (!s.state.Hovered || st.Hovered) && (!s.state.Pressed || st.Pressed) &&
+		(!s.state.Focused || st.Focused) && (!s.state.Disabled || st.Disabled)
+}
+
+func isIdent(s string) bool {
+	for _, r := range s {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '-' && r != '_' {
+			return false
+		}
+	}
+	return s != ""
+}
+
+func parseSelector(text string) (sel selector, err error) {
+	src := strings.TrimSpace(text)
+	if src == "*" {
+		return sel, nil
+	}
+	i := strings.IndexAny(src, ".#:")
+	if i < 0 {
+		i = len(src)
+	}
+	if sel.typ = src[:i]; sel.typ != "" && !isIdent(sel.typ) {
+		return sel, fmt.Errorf("invalid selector %q", strings.TrimSpace(text))
+	}
+	for src = src[i:]; src != ""; {
+		kind := src[0]
+		src = src[1:]
+		j := strings.IndexAny(src, ".#:")
+		if j < 0 {
+			j = len(src)
+		}
+		name := src[:j]
+		src = src[j:]
+		if !isIdent(name) {
+			return sel, fmt.Errorf("invalid selector %q", strings.TrimSpace(text))
+		}
+		switch kind {
+		case '.':
+			sel.classes = append(sel.classes, name)
+			continue
+		case '#':
+			sel.id = name
+			continue
+		}
+		switch name {
+		case "hover":
+			sel.state.Hovered = true
+		case "pressed":
+			sel.state.Pressed = true
+		case "focused":
+			sel.state.Focused = true
+		case "disabled":
+			sel.state.Disabled = true
+		default:
+			return sel, fmt.Errorf("unknown pseudo-class %q", name)
+		}
+	}
+	return sel, nil
+}
+
+type styleRule struct {
+	sel   selector
+	props StyleProps
+	order int
+}
+
+// Stylesheet is a list of rules setting style properties of the elements
+// matching their selectors. Selectors are made of an optional type, an id, classes
+// and pseudo-classes (hover, pressed, focused, disabled), like button.primary:hover
+// or #save:pressed, or are * to match everything. When rules set the same
+// property, the most specific wins, then the last one.
+//
+// The properties are
+//
+//	fill, stroke, color, accent, background: colors written #rgb, #rrggbb, #rrggbbaa or none
+//	stroke-width, padding: integers
+//	corners: the radius of all corners, or top-left, top-right, bottom-left and bottom-right ones
+//	font: a shorthand for font-family, font-size and font-style, like Arial 14 bold
+//	font-family, font-size, font-style: where font-style is regular, bold, italic or bold italic
+//
+// color and the font properties are inherited from the parent element when no rule sets them.
+// Elements use the properties that make sense for them: rects take fill, stroke,
+// stroke-width and corners, texts take color, stroke and the font. Widgets
+// also use accent for their marks, background for their content areas and padding.
+type Stylesheet struct {
+	rules []styleRule
+}
+
+// NewStylesheet creates an empty stylesheet
+func NewStylesheet() *Stylesheet {
+	return new(Stylesheet)
+}
+
+// ParseStylesheet parses rules written like CSS:
+//
+//	/* Comment */
+//	button, .primary:hover { fill: #0078d7; font: Arial 14 bold }
+func ParseStylesheet(src string) (*Stylesheet, error) {
+	s := NewStylesheet()
+	// Comments are blanked out, keeping the lines
+	for {
+		i := strings.Index(src, "/*")
+		if i < 0 {
+			break
+		}
+		j := strings.Index(src[i:], "*/")
+		if j < 0 {
+			return nil, fmt.Errorf("line %d: unterminated comment", lineAt(src, i))
+		}
+		blank := strings.Map(func(r rune) rune {
+			if r == '\n' {
+				return r
+			}
+			return ' '
+		}, src[i:i+j+2])
+		src = src[:i] + blank + src[i+j+2:]
+	}
+	for pos := 0; strings.TrimSpace(src[pos:]) != ""; {
+		open := strings.Index(src[pos:], "{")
+		if open < 0 {
+			return nil, fmt.Errorf("line %d: expected {", lineAt(src, pos))
+		}
+		close := strings.Index(src[pos+open:], "}")
+		if close < 0 {
+			return nil, fmt.Errorf("line %d: expected }", lineAt(src, pos+open))
+		}
+		sels := src[pos : pos+open]
+		decls := src[pos+open+1 : pos+open+close]
+		if err := s.Add(sels, decls); err != nil {
+			return nil, fmt.Errorf("line %d: %v", lineAt(src, pos+len(sels)-len(strings.TrimLeft(sels, " \t\r\n"))), err)
+		}
+		pos += open + close + 1
+	}
+	return s, nil
+}
+
+// MustParseStylesheet is like ParseStylesheet but panics on errors.
+// It's meant for stylesheets written in code.
+func MustParseStylesheet(src string) *Stylesheet {
+	s, err := ParseStylesheet(src)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+func lineAt(src string, offset int) int {
+	return strings.Count(src[:offset], "\n") + 1
+}
+
+// Add adds a rule for a comma separated list of selectors, decls being
+// declarations like "fill: #fff; stroke-width: 2"
+func (s *Stylesheet) Add(selectors, decls string) error {
+	props, err := parseDecls(decls)
+	if err != nil {
+		return err
+	}
+	for _, src := range strings.Split(selectors, ",") {
+		sel, err := parseSelector(src)
+		if err != nil {
+			return err
+		}
+		s.rules = append(s.rules, styleRule{sel, props, len(s.rules)})
+	}
+	return nil
+}
+
+func parseDecls(src string) (p StyleProps, err error) {
+	for _, d := range strings.Split(src, ";") {
+		if strings.TrimSpace(d) == "" {
+			continue
+		}
+		i := strings.Index(d, ":")
+		if i < 0 {
+			return p, fmt.Errorf("expected name: value, not %q", strings.TrimSpace(d))
+		}
+		name, value := strings.TrimSpace(d[:i]), strings.TrimSpace(d[i+1:])
+		if err := p.parse(name, value); err != nil {
+			return p, err
+		}
+	}
+	return p, nil
+}
+
+func (p *StyleProps) parse(name, value string) (err error) {
+	var prop StyleProp
+	switch name {
+	case "fill":
+		prop = PropFill
+		p.FillColor, err = ParseColor(value)
+	case "stroke":
+		prop = PropStroke
+		p.StrokeColor, err = ParseColor(value)
+	case "color":
+		prop = PropColor
+		p.Color, err = ParseColor(value)
+	case "accent":
+		prop = PropAccent
+		p.Accent, err = ParseColor(value)
+	case "background":
+		prop = PropBackground
+		p.Background, err = ParseColor(value)
+	case "stroke-width":
+		prop = PropStrokeWidth
+		p.StrokeWidth, err = strconv.Atoi(value)
+	case "padding":
+		prop = PropPadding
+		p.Padding, err = strconv.Atoi(value)
+	case "corners":
+		prop = PropCorners
+		p.Corners, err = parseCorners(value)
+	case "font":
+		f, err := ParseFont(value, Font{})
+		if err != nil {
+			return err
+		}
+		p.Font.Size, p.Font.Style = f.Size, f.Style
+		prop = PropFontSize | PropFontStyle
+		if f.Family != "" {
+			p.Font.Family = f.Family
+			prop |= PropFontFamily
+		}
+	case "font-family":
+		prop = PropFontFamily
+		p.Font.Family = value
+	case "font-size":
+		prop = PropFontSize
+		p.Font.Size, err = strconv.Atoi(value)
+	case "font-style":
+		prop = PropFontStyle
+		p.Font.Style, err = parseFontStyle(strings.Fields(value))
+	default:
+		return fmt.Errorf("unknown property %q", name)
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %v", name, err)
+	}
+	p.Set |= prop
+	return nil
+}
+
+func parseCorners(s string) (r RectCornersRad, err error) {
+	f := strings.Fields(s)
+	v := make([]int, len(f))
+	for i := range f {
+		if v[i], err = strconv.Atoi(f[i]); err != nil {
+			return r, err
+		}
+	}
+	switch len(v) {
+	case 1:
+		return RectCornersRad{v[0], v[0], v[0], v[0]}, nil
+	case 4:
+		return RectCornersRad{v[0], v[1], v[2], v[3]}, nil
+	}
+	return r, fmt.Errorf("expected 1 or 4 integers")
+}
+
+// ParseColor parses a color written #rgb, #rrggbb, #rrggbbaa or none
+func ParseColor(s string) (Color, error) {
+	s = strings.TrimSpace(s)
+	if s == "none" {
+		return Color{}, nil
+	}
+	hex := strings.TrimPrefix(s, "#")
+	if len(hex) == 3 {
+		hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+	}
+	if len(hex) == 6 {
+		hex += "ff"
+	}
+	v, err := strconv.ParseUint(hex, 16, 32)
+	if !strings.HasPrefix(s, "#") || len(hex) != 8 || err != nil {
+		return Color{}, fmt.Errorf("invalid color %q", s)
+	}
+	return Color{uint8(v >> 24), uint8(v >> 16), uint8(v >> 8), uint8(v)}, nil
+}
+
+func parseFontStyle(fields []string) (st FontStyle, err error) {
+	for _, f := range fields {
+		switch f {
+		case "bold":
+			st.Bold = true
+		case "italic":
+			st.Italic = true
+		case "regular":
+		default:
+			return st, fmt.Errorf("unknown font style %q", f)
+		}
+	}
+	return st, nil
+}
+
+// ParseFont parses a font written "Family size [bold] [italic]", the family can
+// be left out to keep the one of def
+func ParseFont(s string, def Font) (Font, error) {
+	fields := strings.Fields(s)
+	for i, field := range fields {
+		size, err := strconv.Atoi(field)
+		if err != nil {
+			continue
+		}
+		f := Font{Family: def.Family, Size: size}
+		if i > 0 {
+			f.Family = strings.Join(fields[:i], " ")
+		}
+		if f.Style, err = parseFontStyle(fields[i+1:]); err != nil {
+			return def, err
+		}
+		return f, nil
+	}
+	return def, fmt.Errorf("font %q has no size", s)
+}
+
+type rulesBySpecificity []*styleRule
+
+func (l rulesBySpecificity) Len() int      { return len(l) }
+func (l rulesBySpecificity) Swap(i, j int) { l[i], l[j] = l[j], l[i] }
+func (l rulesBySpecificity) Less(i, j int) bool {
+	si, sj := l[i].sel.specificity(), l[j].sel.specificity()
+	if si == sj {
+		return l[i].order < l[j].order
+	}
+	return si < sj
+}
+
+// Compute returns the properties set by the rules matching the element in the
+// state, with the inherited properties of its ancestors when no rule sets them.
+// A nil stylesheet sets nothing.
+func (s *Stylesheet) Compute(e IElement, st StyleState) (p StyleProps) {
+	if s == nil {
+		return p
+	}
+	var matched rulesBySpecificity
+	for i := range s.rules {
+		if s.rules[i].sel.matches(e, st) {
+			matched = append(matched, &s.rules[i])
+		}
+	}
+	sort.Sort(matched)
+	for _, r := range matched {
+		p.merge(&r.props, ^StyleProp(0))
+	}
+	if missing := inheritedProps &^ p.Set; missing != 0 {
+		if parent := e.BaseElement().parent; parent != nil {
+			inherited := s.Compute(parent, StyleState{})
+			p.merge(&inherited, missing)
+		}
+	}
+	return p
+}
+
+// styleHandler is implemented by handlers of elements that apply stylesheets
+// themselves, like widgets. Their descendants are left to them.
+type styleHandler interface {
+	ApplyStyle(s *Stylesheet)
+}
+
+// Stylesheet returns the stylesheet of the scene, nil if there's none
+func (s *Scene) Stylesheet() *Stylesheet {
+	return s.sheet
+}
+
+// SetStylesheet changes the stylesheet of the scene, restyling and redrawing
+// all the elements. It's how themes are switched.
+func (s *Scene) SetStylesheet(sheet *Stylesheet) {
+	s.sheet = sheet
+	s.Restyle(s.root)
+	s.InvalidateArea(s.viewport)
+}
+
+// Restyle applies the stylesheet of the scene to an element and its
+// descendants, after they are created or their classes change
+func (s *Scene) Restyle(e IElement) {
+	b := e.BaseElement()
+	if h, ok := b.Handler.(styleHandler); ok {
+		h.ApplyStyle(s.sheet)
+		return
+	}
+	if s.sheet != nil {
+		var st StyleState
+		if h, ok := b.Handler.(stateHandler); ok {
+			st = h.StyleState()
+		}
+		p := s.sheet.Compute(e, st)
+		if c, ok := e.(*ConcreteElement); ok {
+			Invalidate(c)
+			restoreUnstyled(c)
+			applyProps(c, &p)
+			Invalidate(c)
+		}
+	} else if c, ok := e.(*ConcreteElement); ok {
+		Invalidate(c)
+		restoreUnstyled(c)
+		Invalidate(c)
+	}
+	if a, ok := e.(*AbstractElement); ok {
+		for _, c := range a.children {
+			s.Restyle(c)
+		}
+	}
+}
+
+var unstyledKey = NewDataKey("unstyled")
+
+// unstyledLook is the look of a ConcreteElement before a stylesheet was first
+// applied to it
+type unstyledLook struct {
+	paint   Paint
+	corners RectCornersRad
+	font    Font
+}
+
+// restoreUnstyled gives the element back the look it had before stylesheets
+// were applied, so that a new sheet doesn't keep what the previous one set.
+// The look is remembered the first time.
+func restoreUnstyled(e *ConcreteElement) {
+	u, ok := e.Data(unstyledKey).(*unstyledLook)
+	if !ok {
+		u = &unstyledLook{paint: e.Paint}
+		switch sh := e.shape.(type) {
+		case *RectShape:
+			u.corners = sh.CornerRadiis()
+		case *TextShape:
+			u.font = sh.Font
+		}
+		e.SetData(unstyledKey, u)
+		return
+	}
+	e.Paint = u.paint
+	switch sh := e.shape.(type) {
+	case *RectShape:
+		sh.SetCornerRadiis(u.corners)
+	case *TextShape:
+		sh.Font = u.font
+	}
+}
+
+// applyProps sets the paint and shape of a ConcreteElement from style properties.
+// Texts take color rather than fill.
+func applyProps(e *ConcreteElement, p *StyleProps) {
+	if p.Has(PropStroke) {
+		e.StrokeColor = p.StrokeColor
+	}
+	if p.Has(PropStrokeWidth) {
+		e.StrokeWidth = p.StrokeWidth
+	}
+	switch sh := e.shape.(type) {
+	case *RectShape:
+		if p.Has(PropFill) {
+			e.FillColor = p.FillColor
+		}
+		if p.Has(PropCorners) {
+			sh.SetCornerRadiis(p.Corners)
+		}
+	case *TextShape:
+		if p.Has(PropColor) {
+			e.FillColor = p.Color
+		}
+		if p.Has(PropFontFamily) {
+			sh.Font.Family = p.Font.Family
+		}
+		if p.Has(PropFontSize) {
+			sh.Font.Size = p.Font.Size
+		}
+		if p.Has(PropFontStyle) {
+			sh.Font.Style = p.Font.Style
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package gosui
+
+import (
+	chk "launchpad.net/gocheck"
+)
+
+func (s *MySuite) TestParseValues(c *chk.C) {
+	col, err := ParseColor("#1234")
+	c.Check(err, chk.NotNil)
+	col, err = ParseColor("#f80")
+	c.Check(col, chk.Equals, Color{0xff, 0x88, 0, 0xff})
+	col, err = ParseColor("none")
+	c.Check(col, chk.Equals, Color{})
+
+	def := Font{Family: "Arial", Size: 14}
+	f, err := ParseFont("20 bold", def)
+	c.Check(err, chk.IsNil)
+	c.Check(f, chk.Equals, Font{Family: "Arial", Size: 20, Style: Bold})
+	_, err = ParseFont("Arial", def)
+	c.Check(err, chk.ErrorMatches, `font "Arial" has no size`)
+	_, err = ParseFont("Arial 12 thin", def)
+	c.Check(err, chk.ErrorMatches, `unknown font style "thin"`)
+}
+
+func (s *MySuite) TestStylesheetErrors(c *chk.C) {
+	for src, msg := range map[string]string{
+		"rect { fill: red }":                 `line 1: fill: invalid color "red"`,
+		"/* a */\nrect {\n  fill: #fff\n":    `line 2: expected }`,
+		"rect { fill: #fff }\n\n.a b { }":    `line 3: invalid selector ".a b"`,
+		"rect:active { }":                    `line 1: unknown pseudo-class "active"`,
+		"text { size: 3 }":                   `line 1: unknown property "size"`,
+		"rect { fill: #fff }\n/* unfinished": `line 2: unterminated comment`,
+	} {
+		_, err := ParseStylesheet(src)
+		c.Check(err, chk.ErrorMatches, msg, chk.Commentf("%q", src))
+	}
+}
+
+func (s *MySuite) TestStylesheetCascade(c *chk.C) {
+	sheet := MustParseStylesheet(`
+		/* Later rules win between equal selectors, more specific ones always do */
+		rect.warn { fill: #f00 }
+		rect { fill: #0f0; stroke: #00f; corners: 4 }
+		.warn:hover { fill: #ff0 }
+		group { color: #123456; font: Serif 12 }
+		.title { font-size: 20 }
+	`)
+	sc := NewScene()
+	box := NewAbstractElement(sc.Root(), MakeRect(0, 0, 100, 100))
+	r := NewRectElement(box, MakeRect(0, 0, 10, 10))
+	t := NewTextElement(box, 0, 10, Font{Family: "Arial", Size: 14}, false)
+	t.AddClass("title")
+
+	p := sheet.Compute(r, StyleState{})
+	c.Check(p.FillColor, chk.Equals, Color{0, 0xff, 0, 0xff})
+	r.AddClass("warn")
+	p = sheet.Compute(r, StyleState{})
+	c.Check(p.FillColor, chk.Equals, Color{0xff, 0, 0, 0xff})
+	c.Check(p.StrokeColor, chk.Equals, Color{0, 0, 0xff, 0xff})
+	p = sheet.Compute(r, StyleState{Hovered: true})
+	c.Check(p.FillColor, chk.Equals, Color{0xff, 0xff, 0, 0xff})
+
+	p = sheet.Compute(t, StyleState{})
+	c.Check(p.Has(PropFill), chk.Equals, false)
+	c.Check(p.Color, chk.Equals, Color{0x12, 0x34, 0x56, 0xff})
+	c.Check(p.Font, chk.Equals, Font{Family: "Serif", Size: 20})
+
+	sc.SetStylesheet(sheet)
+	c.Check(sc.Stylesheet(), chk.Equals, sheet)
+	c.Check(r.FillColor, chk.Equals, Color{0xff, 0, 0, 0xff})
+	c.Check(r.RectShape().CornerRadiis().TopLeft, chk.Equals, 4)
+	c.Check(t.FillColor, chk.Equals, Color{0x12, 0x34, 0x56, 0xff})
+	c.Check(t.TextShape().Font, chk.Equals, Font{Family: "Serif", Size: 20})
+
+	r.RemoveClass("warn")
+	sc.Restyle(r)
+	c.Check(r.FillColor, chk.Equals, Color{0, 0xff, 0, 0xff})
+
+	// Switching sheets starts again from the look the elements had
+	sc.SetStylesheet(MustParseStylesheet(`text { color: #fff }`))
+	c.Check(r.Paint, chk.Equals, Paint{})
+	c.Check(r.RectShape().CornerRadiis(), chk.Equals, RectCornersRad{})
+	c.Check(t.FillColor, chk.Equals, Color{0xff, 0xff, 0xff, 0xff})
+	c.Check(t.TextShape().Font, chk.Equals, Font{Family: "Arial", Size: 14})
+	sc.SetStylesheet(nil)
+	c.Check(t.FillColor, chk.Equals, Color{})
+	c.Check(TypeOf(box), chk.Equals, "group")
+	c.Check(TypeOf(sc.Root()), chk.Equals, "root")
+}
@@ -2,7 +2,10 @@
 package main
 
 import (
+	"log"
+
 	gs "github.com/phaikawl/gosui"
+	"github.com/phaikawl/gosui/widgets"
 
 	gsr "github.com/phaikawl/gosui/native/skia" //Native rendering backend
 
@@ -33,5 +36,8 @@ func main() {
 	input.FillColor = gs.Color{0, 0, 40, 255}
 	input.SetZIndex(1000)
 	input.TextShape().Content = "Hello world!"
+	btn := widgets.NewButton(root, gs.MakeRectWH(350, 20, 120, 32), "Click me")
+	btn.OnClick = func() { log.Print("Button clicked") }
+	widgets.NewCheckbox(root, gs.MakeRectWH(350, 70, 150, 24), "Check me")
 	window.Start()
 }
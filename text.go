@@ -0,0 +1,443 @@
+package gosui
+
+import "unicode"
+
+const zwj = 0x200d // Zero width joiner
+
+// ExtendsCluster reports whether r belongs to the character before it, like
+// accents, joiners, variation selectors and skin tones
+func ExtendsCluster(r rune) bool {
+	return unicode.Is(unicode.M, r) || r == zwj || r >= 0xfe00 && r <= 0xfe0f ||
+		r >= 0x1f3fb && r <= 0x1f3ff || r >= 0xe0020 && r <= 0xe007f || r >= 0xe0100 && r <= 0xe01ef
+}
+
+func isRegionalIndicator(r rune) bool {
+	return r >= 0x1f1e6 && r <= 0x1f1ff
+}
+
+// hangulType returns the kind of Hangul jamo or syllable of r: 'L', 'V' and
+// 'T' for leading, vowel and trailing jamos, 'v' and 't' for syllables
+// without and with a trailing consonant, 0 for other characters
+func hangulType(r rune) byte {
+	switch {
+	case r >= 0x1100 && r <= 0x115f, r >= 0xa960 && r <= 0xa97c:
+		return 'L'
+	case r >= 0x1160 && r <= 0x11a7, r >= 0xd7b0 && r <= 0xd7c6:
+		return 'V'
+	case r >= 0x11a8 && r <= 0x11ff, r >= 0xd7cb && r <= 0xd7fb:
+		return 'T'
+	case r >= 0xac00 && r <= 0xd7a3 && (r-0xac00)%28 == 0:
+		return 'v'
+	case r >= 0xac00 && r <= 0xd7a3:
+		return 't'
+	}
+	return 0
+}
+
+// hangulJoins reports whether the Hangul of type b continues the syllable of type a
+func hangulJoins(a, b byte) bool {
+	switch a {
+	case 'L':
+		return b == 'L' || b == 'V' || b == 'v' || b == 't'
+	case 'V', 'v':
+		return b == 'V' || b == 'T'
+	case 'T', 't':
+		return b == 'T'
+	}
+	return false
+}
+
+// graphemeEnd returns the end of the grapheme cluster starting at i, that is
+// of the character as the user sees it: a letter with its accents, an emoji
+// sequence, a flag or a Hangul syllable
+func graphemeEnd(text []rune, i int) int {
+	n := len(text)
+	if i >= n {
+		return n
+	}
+	r, j := text[i], i+1
+	switch {
+	case r == '\r' && j < n && text[j] == '\n':
+		return j + 1
+	case unicode.Is(unicode.Cc, r):
+		return j
+	case isRegionalIndicator(r):
+		if j < n && isRegionalIndicator(text[j]) {
+			j++
+		}
+	case hangulType(r) != 0:
+		for h := hangulType(r); j < n && hangulJoins(h, hangulType(text[j])); j++ {
+			h = hangulType(text[j])
+		}
+	}
+	for j < n && (ExtendsCluster(text[j]) || text[j-1] == zwj && isPictographic(text[j])) {
+		j++
+	}
+	return j
+}
+
+// isPictographic reports whether r is an emoji or another pictograph that
+// joins a sequence after a zero width joiner
+func isPictographic(r rune) bool {
+	return unicode.Is(unicode.So, r) || r >= 0x1f000 && r <= 0x1faff
+}
+
+// graphemeBounds returns the offsets of the grapheme clusters of text,
+// starting with 0 and ending with len(text)
+func graphemeBounds(text []rune) []int {
+	bounds := []int{0}
+	for i := 0; i < len(text); {
+		i = graphemeEnd(text, i)
+		bounds = append(bounds, i)
+	}
+	return bounds
+}
+
+// arabicLetter gives the presentation forms of an Arabic letter
+type arabicLetter struct {
+	forms rune // First form, the isolated one, followed by the final, initial and medial ones
+	n     int  // Number of forms: 1 for letters that don't join, 2 for the ones joining the letter before only, 4 for the others
+}
+
+// arabicLetters has the letters of the Arabic Presentation Forms-B block, the
+// Persian letters of Presentation Forms-A, and the tatweel and the zero width
+// joiner that join without changing
+var arabicLetters = func() map[rune]arabicLetter {
+	m := map[rune]arabicLetter{
+		0x67e: {0xfb56, 4}, 0x686: {0xfb7a, 4}, 0x698: {0xfb8a, 2},
+		0x6a9: {0xfb8e, 4}, 0x6af: {0xfb92, 4}, 0x6cc: {0xfbfc, 4},
+		0x640: {0, 4}, zwj: {0, 4},
+	}
+	// The forms of U+0621 to U+063A and U+0641 to U+064A follow each other
+	next := rune(0xfe80)
+	add := func(first rune, counts string) {
+		for i, c := range counts {
+			m[first+rune(i)] = arabicLetter{next, int(c - '0')}
+			next += c - '0'
+		}
+	}
+	add(0x621, "12222424244444222244444444")
+	add(0x641, "4444444224")
+	return m
+}()
+
+// lamAlef has the ligatures of lam with the alefs, isolated, the final form following
+var lamAlef = map[rune]rune{0x622: 0xfef5, 0x623: 0xfef7, 0x625: 0xfef9, 0x627: 0xfefb}
+
+// shapeArabic replaces the Arabic letters of the text with their contextual
+// forms, joined to the letters around them, and lam followed by alef with
+// their ligature. Each rune of the result is the one drawn for the rune of the
+// text at the same index, the alefs of ligatures getting -1.
+func shapeArabic(text []rune) []rune {
+	out := make([]rune, len(text))
+	copy(out, text)
+	// neighbour returns the letter next to i in the direction step, skipping the marks
+	neighbour := func(i, step int) (arabicLetter, bool) {
+		for i += step; i >= 0 && i < len(text); i += step {
+			if !unicode.Is(unicode.Mn, text[i]) {
+				l, ok := arabicLetters[text[i]]
+				return l, ok
+			}
+		}
+		return arabicLetter{}, false
+	}
+	for i := 0; i < len(text); i++ {
+		l, ok := arabicLetters[text[i]]
+		if !ok || l.n == 1 {
+			continue
+		}
+		p, ok := neighbour(i, -1)
+		prev := ok && p.n == 4
+		nx, ok := neighbour(i, 1)
+		next := ok && l.n == 4 && nx.n > 1
+		if lig, ok := lamAlef[at(text, i+1)]; ok && text[i] == 0x644 {
+			out[i], out[i+1] = lig, -1
+			if prev {
+				out[i]++
+			}
+			i++
+			continue
+		}
+		if l.forms == 0 {
+			continue
+		}
+		switch {
+		case prev && next:
+			out[i] = l.forms + 3
+		case next:
+			out[i] = l.forms + 2
+		case prev:
+			out[i] = l.forms + 1
+		default:
+			out[i] = l.forms
+		}
+	}
+	return out
+}
+
+// at returns the rune at i, 0 past the end
+func at(text []rune, i int) rune {
+	if i < len(text) {
+		return text[i]
+	}
+	return 0
+}
+
+// textLayout is a line of text as it is displayed: shaped, in visual order and measured
+type textLayout struct {
+	content  string
+	font     Font
+	measurer TextMeasurer
+	text     []rune  // Logical order
+	bounds   []int   // Grapheme cluster boundaries
+	levels   []uint8 // Bidi levels, odd ones being right to left
+	glyphs   []rune  // Shaped characters from left to right
+	vis      []int   // Index in glyphs of the glyph of each rune
+	starts   []int   // Index in glyphs of the first glyph of each cluster, from left to right
+	xs       []int   // Offset of each glyph, then the width of the text
+}
+
+func newTextLayout(content string, font Font) *textLayout {
+	l := &textLayout{content: content, font: font, measurer: Measurer, text: []rune(content)}
+	n := len(l.text)
+	l.bounds = graphemeBounds(l.text)
+	l.levels, _ = bidiLevels(l.text)
+	shaped := shapeArabic(l.text)
+
+	// Runs of the same level, reversed by rule L2 of UAX #9: from the highest
+	// level to the lowest odd one, each sequence of runs at that level or
+	// higher is reversed
+	var runs [][2]int
+	var max, minOdd uint8 = 0, 255
+	for i := 0; i < n; {
+		lv, j := l.levels[i], i+1
+		for j < n && l.levels[j] == lv {
+			j++
+		}
+		runs = append(runs, [2]int{i, j})
+		if lv > max {
+			max = lv
+		}
+		if lv%2 == 1 && lv < minOdd {
+			minOdd = lv
+		}
+		i = j
+	}
+	for lv := max; lv >= minOdd && lv > 0; lv-- {
+		for i := 0; i < len(runs); {
+			if l.levels[runs[i][0]] < lv {
+				i++
+				continue
+			}
+			j := i
+			for j < len(runs) && l.levels[runs[j][0]] >= lv {
+				j++
+			}
+			for a, b := i, j-1; a < b; a, b = a+1, b-1 {
+				runs[a], runs[b] = runs[b], runs[a]
+			}
+			i = j
+		}
+	}
+
+	// Right to left runs are reversed by cluster, so that the marks still
+	// follow the letters they go on
+	l.vis = make([]int, n)
+	for _, r := range runs {
+		rtl := l.levels[r[0]]%2 == 1
+		cuts := []int{r[0]}
+		for _, b := range l.bounds {
+			if b > r[0] && b < r[1] {
+				cuts = append(cuts, b)
+			}
+		}
+		cuts = append(cuts, r[1])
+		for k := 0; k+1 < len(cuts); k++ {
+			c := k
+			if rtl {
+				c = len(cuts) - 2 - k
+			}
+			start := len(l.glyphs)
+			for i := cuts[c]; i < cuts[c+1]; i++ {
+				g := shaped[i]
+				if g < 0 {
+					continue
+				}
+				if rtl {
+					g = mirrored(g)
+				}
+				l.vis[i] = len(l.glyphs)
+				l.glyphs = append(l.glyphs, g)
+			}
+			if len(l.glyphs) > start {
+				l.starts = append(l.starts, start)
+			}
+		}
+	}
+	for i, g := range shaped {
+		if g < 0 {
+			l.vis[i] = l.vis[i-1]
+		}
+	}
+	return l
+}
+
+// x returns the offset of the glyph k from the start of the text. Each cluster
+// is measured once, the glyphs after the first one of a cluster are placed at
+// its end.
+func (l *textLayout) x(k int) int {
+	if l.xs == nil {
+		l.xs = make([]int, len(l.glyphs)+1)
+		x := 0
+		for c, a := range l.starts {
+			b := len(l.glyphs)
+			if c+1 < len(l.starts) {
+				b = l.starts[c+1]
+			}
+			l.xs[a] = x
+			w, _ := MeasureText(string(l.glyphs[a:b]), l.font)
+			x += w
+			for i := a + 1; i <= b; i++ {
+				l.xs[i] = x
+			}
+		}
+	}
+	return l.xs[k]
+}
+
+func (l *textLayout) width() int {
+	return l.x(len(l.glyphs))
+}
+
+func (l *textLayout) rtl(i int) bool {
+	return l.levels[i]%2 == 1
+}
+
+// span returns the offsets of the left and right edges of the glyphs of the runes from b to e
+func (l *textLayout) span(b, e int) (left, right int) {
+	lo, hi := l.vis[b], l.vis[b]+1
+	for i := b + 1; i < e; i++ {
+		if v := l.vis[i]; v < lo {
+			lo = v
+		} else if v >= hi {
+			hi = v + 1
+		}
+	}
+	return l.x(lo), l.x(hi)
+}
+
+// caretX returns the offset of the caret at the cluster boundary b: the
+// leading edge of the character after it, or the trailing edge of the last one
+func (l *textLayout) caretX(b int) int {
+	n := len(l.text)
+	switch {
+	case n == 0:
+		return 0
+	case b > 0 && b < n && l.vis[b] == l.vis[b-1]:
+		// Inside a ligature
+		return (l.x(l.vis[b]) + l.x(l.vis[b]+1)) / 2
+	case b < n:
+		left, right := l.span(b, l.next(b))
+		if l.rtl(b) {
+			return right
+		}
+		return left
+	}
+	left, right := l.span(l.prev(n), n)
+	if l.rtl(n - 1) {
+		return left
+	}
+	return right
+}
+
+// snap returns the cluster boundary at or before i
+func (l *textLayout) snap(i int) int {
+	b := 0
+	for _, c := range l.bounds {
+		if c > i {
+			break
+		}
+		b = c
+	}
+	return b
+}
+
+// next returns the end of the cluster starting at b, or b at the end of the text
+func (l *textLayout) next(b int) int {
+	for _, c := range l.bounds {
+		if c > b {
+			return c
+		}
+	}
+	return b
+}
+
+// prev returns the start of the cluster ending at b, or b at the start of the text
+func (l *textLayout) prev(b int) int {
+	p := b
+	for _, c := range l.bounds {
+		if c >= b {
+			break
+		}
+		p = c
+	}
+	return p
+}
+
+// move returns the cluster boundary where the caret goes from b with the
+// right or left arrow. The caret moves on the screen: positions are visited
+// by their offset, then in logical order when they are at the same place.
+func (l *textLayout) move(b int, right bool) int {
+	x0 := l.caretX(b)
+	best, bx, found := b, 0, false
+	for _, p := range l.bounds {
+		x := l.caretX(p)
+		if right && (x < x0 || x == x0 && p <= b) || !right && (x > x0 || x == x0 && p >= b) {
+			continue
+		}
+		if !found || right && (x < bx || x == bx && p < best) || !right && (x > bx || x == bx && p > best) {
+			best, bx, found = p, x, true
+		}
+	}
+	return best
+}
+
+// hit returns the cluster boundary whose caret is the nearest to the offset x
+func (l *textLayout) hit(x int) int {
+	best, dist := 0, maxInt
+	for _, b := range l.bounds {
+		d := l.caretX(b) - x
+		if d < 0 {
+			d = -d
+		}
+		if d < dist {
+			best, dist = b, d
+		}
+	}
+	return best
+}
+
+// selection returns the left and right offsets of the parts of the text
+// from the rune start to end, which are split when it goes through runs of
+// both directions
+func (l *textLayout) selection(start, end int) (spans [][2]int) {
+	if end > len(l.text) {
+		end = len(l.text)
+	}
+	selected := make([]bool, len(l.glyphs)+1)
+	for i := start; i < end; i++ {
+		selected[l.vis[i]] = true
+	}
+	for k := 0; k < len(l.glyphs); k++ {
+		if !selected[k] {
+			continue
+		}
+		j := k
+		for selected[j] {
+			j++
+		}
+		spans = append(spans, [2]int{l.x(k), l.x(j)})
+		k = j
+	}
+	return spans
+}
@@ -0,0 +1,153 @@
+package gosui
+
+import (
+	"image"
+
+	chk "launchpad.net/gocheck"
+)
+
+func (s *MySuite) TestGraphemes(c *chk.C) {
+	text := []rune("e\u0301\U0001f44d\U0001f3fd\U0001f1eb\U0001f1f7\U0001f1e9\U0001f468\u200d\U0001f469\u200d\U0001f467\r\n\u1100\u1161\u11a8\uac00x")
+	c.Check(graphemeBounds(text), chk.DeepEquals, []int{0, 2, 4, 6, 7, 12, 14, 17, 18, 19})
+	c.Check(graphemeBounds(nil), chk.DeepEquals, []int{0})
+}
+
+func (s *MySuite) TestBidiReordering(c *chk.C) {
+	visual := func(text string) string {
+		return (&TextShape{Content: text}).Visual()
+	}
+	c.Check(visual("hello, world"), chk.Equals, "hello, world")
+	c.Check(visual("abc שלום 123"), chk.Equals, "abc 123 םולש")
+	// Brackets take the direction of the paragraph around Latin words
+	c.Check(visual("שלום (abc)!"), chk.Equals, "!(abc) םולש")
+	c.Check(visual("a (שלום) b"), chk.Equals, "a (םולש) b")
+	c.Check(visual("שלום -5%, 1.5"), chk.Equals, "1.5 ,5%- םולש")
+	levels, para := bidiLevels([]rune("שלום abc "))
+	c.Check(para, chk.Equals, uint8(1))
+	c.Check(levels, chk.DeepEquals, []uint8{1, 1, 1, 1, 1, 2, 2, 2, 1})
+	_, para = bidiLevels(nil)
+	c.Check(para, chk.Equals, uint8(0))
+}
+
+func (s *MySuite) TestArabicShaping(c *chk.C) {
+	visual := func(text string) string {
+		return (&TextShape{Content: text}).Visual()
+	}
+	// Initial seen, lam-alef joined to it, isolated meem after the alef
+	c.Check(visual("\u0633\u0644\u0627\u0645"), chk.Equals, "\ufee1\ufefc\ufeb3")
+	// The marks follow their letters, which still join over them
+	c.Check(visual("\u0628\u0650\u0633\u0652\u0645"), chk.Equals, "\ufee2\ufeb4\u0652\ufe91\u0650")
+	c.Check(visual("\u0644\u0627"), chk.Equals, "\ufefb")
+	c.Check(visual("\u0621 \u0628"), chk.Equals, "\ufe8f \u0621")
+	// Arabic digits keep their order
+	c.Check(visual("\u0639\u062f\u062f \u0661\u0662"), chk.Equals, "\u0661\u0662 \ufea9\ufeaa\ufecb")
+	c.Check(shapeArabic([]rune("پی")), chk.DeepEquals, []rune{0xfb58, 0xfbfd})
+}
+
+func newTestInput(sc *Scene, text string) *InputHandler {
+	te := NewTextInputElement(sc.Root(), 0, 20, Font{"Arial", 20, Regular})
+	in := te.Input()
+	in.SetText(text)
+	sc.Focus(te)
+	return in
+}
+
+func (s *MySuite) TestBidiCaret(c *chk.C) {
+	sc := NewScene()
+	in := newTestInput(sc, "abc אבג")
+	ts := in.e.TextShape()
+	key := func(k Key, shift bool) int {
+		sc.HandleKey(&KeyEvent{Key: k, Mod: Modifiers{Shift: shift}})
+		return in.Caret()
+	}
+
+	// The caret moves on the screen, through the Hebrew word backward
+	var moves []int
+	in.SetCaret(0)
+	for i := 0; i < 8; i++ {
+		moves = append(moves, key(KeyRight, false))
+	}
+	c.Check(moves, chk.DeepEquals, []int{1, 2, 3, 7, 6, 5, 4, 4})
+	c.Check(ts.caretRect().Min.X, chk.Equals, 77)
+	c.Check(key(KeyLeft, false), chk.Equals, 5)
+
+	// A selection over both directions is drawn in two parts
+	in.SetCaret(2)
+	key(KeyRight, true)
+	key(KeyRight, true)
+	key(KeyRight, true)
+	start, end := in.Selection()
+	c.Check([]int{start, end}, chk.DeepEquals, []int{2, 6})
+	c.Check(in.SelectedText(), chk.Equals, "c אב")
+	c.Check(ts.selectionRects(), chk.DeepEquals, []image.Rectangle{MakeRect(22, 0, 44, 25), MakeRect(55, 0, 77, 25)})
+	c.Check(key(KeyRight, false), chk.Equals, 6)
+	key(KeyLeft, true)
+	c.Check(key(KeyLeft, false), chk.Equals, 7)
+
+	// Dragging the mouse selects
+	sc.HandleMouse(&MouseEvent{Pos: image.Pt(12, 10), Action: EventPress})
+	sc.HandleMouse(&MouseEvent{Pos: image.Pt(60, 10), Action: EventMove})
+	sc.HandleMouse(&MouseEvent{Pos: image.Pt(60, 10), Action: EventRelease})
+	start, end = in.Selection()
+	c.Check([]int{start, end}, chk.DeepEquals, []int{1, 6})
+	sc.HandleMouse(&MouseEvent{Pos: image.Pt(100, 10), Action: EventMove})
+	c.Check(in.Caret(), chk.Equals, 6)
+
+	// Typing replaces the selection
+	sc.HandleChar(&CharEvent{Char: 'x'})
+	c.Check(in.Text(), chk.Equals, "axג")
+	c.Check(in.Caret(), chk.Equals, 2)
+}
+
+func (s *MySuite) TestGraphemeEditing(c *chk.C) {
+	sc := NewScene()
+	in := newTestInput(sc, "e\u0301\U0001f44d\U0001f3fdxy")
+	in.History = NewHistory()
+	key := func(k Key, mod Modifiers) {
+		sc.HandleKey(&KeyEvent{Key: k, Mod: mod})
+	}
+
+	// Accents and emoji sequences are deleted with their character
+	key(KeyBackspace, Modifiers{})
+	key(KeyLeft, Modifiers{})
+	c.Check(in.Caret(), chk.Equals, 4)
+	key(KeyBackspace, Modifiers{})
+	c.Check(in.Text(), chk.Equals, "e\u0301x")
+	key(KeyHome, Modifiers{})
+	key(KeyDelete, Modifiers{})
+	c.Check(in.Text(), chk.Equals, "x")
+	key(KeyZ, Modifiers{Control: true})
+	c.Check(in.Text(), chk.Equals, "e\u0301x")
+
+	// Carets inside characters go to their start
+	in.SetCaret(1)
+	c.Check(in.Caret(), chk.Equals, 0)
+	in.Select(2, 1)
+	c.Check(in.SelectedText(), chk.Equals, "e\u0301")
+
+	// Undoing the deletion of a selection selects it again
+	key(KeyA, Modifiers{Control: true})
+	key(KeyBackspace, Modifiers{})
+	c.Check(in.Text(), chk.Equals, "")
+	key(KeyZ, Modifiers{Control: true})
+	c.Check(in.SelectedText(), chk.Equals, "e\u0301x")
+}
+
+// countingMeasurer records the texts it measures, giving each rune 10 pixels
+type countingMeasurer struct{ texts []string }
+
+func (m *countingMeasurer) MeasureText(text string, font Font) (int, int) {
+	m.texts = append(m.texts, text)
+	return 10 * len([]rune(text)), font.Size
+}
+
+func (s *MySuite) TestLayoutMeasuresClusters(c *chk.C) {
+	m := new(countingMeasurer)
+	Measurer = m
+	defer func() { Measurer = nil }()
+	l := newTextLayout("e\u0301ab\u05d0\u05b8\u05d1", Font{"Arial", 20, Regular})
+	c.Check(l.width(), chk.Equals, 70)
+	c.Check(l.caretX(2), chk.Equals, 20)
+	c.Check(l.caretX(6), chk.Equals, 50)
+	c.Check(m.texts, chk.DeepEquals, []string{"e\u0301", "a", "b", "\u05d1", "\u05d0\u05b8"})
+}
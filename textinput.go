@@ -0,0 +1,380 @@
+package gosui
+
+import (
+	"image"
+	"unicode"
+	"unicode/utf8"
+)
+
+// TextMeasurer measures text without drawing it
+type TextMeasurer interface {
+	MeasureText(text string, font Font) (w, h int)
+}
+
+// Measurer is used by MeasureText when set.
+// Backends that are able to measure text should set it when initialized.
+var Measurer TextMeasurer
+
+// MeasureText returns the size of the text drawn with the font.
+// Without a Measurer the size is estimated from the font size.
+func MeasureText(text string, font Font) (w, h int) {
+	if Measurer != nil {
+		return Measurer.MeasureText(text, font)
+	}
+	return utf8.RuneCountInString(text) * font.Size * 11 / 20, font.Size
+}
+
+// SelectionColor is the color drawn behind selected text
+var SelectionColor = Color{0x3b, 0x82, 0xf6, 0x66}
+
+// textLayout returns the layout of the content, which is kept until the
+// content, the font or the Measurer change
+func (s *TextShape) textLayout() *textLayout {
+	if l := s.layout; l == nil || l.content != s.Content || l.font != s.Font || l.measurer != Measurer {
+		s.layout = newTextLayout(s.Content, s.Font)
+	}
+	return s.layout
+}
+
+// Visual returns the content the way it's displayed, from left to right: in
+// the order of the Unicode bidirectional algorithm, with the brackets of right
+// to left text mirrored and the Arabic letters in their contextual forms and
+// ligatures. Backends draw it rather than the content, with no shaping.
+func (s *TextShape) Visual() string {
+	return string(s.textLayout().glyphs)
+}
+
+// caretRect returns the area of the caret
+func (s *TextShape) caretRect() image.Rectangle {
+	x := s.origin.X + s.textLayout().caretX(s.caret)
+	return MakeRect(x, s.origin.Y-s.Font.Size, x+1, s.origin.Y+s.Font.Size/4)
+}
+
+// selectionRects returns the areas of the selected text, one for each part
+// of it that is displayed apart from the others
+func (s *TextShape) selectionRects() (l []image.Rectangle) {
+	start, end := s.anchor, s.caret
+	if start > end {
+		start, end = end, start
+	}
+	for _, sp := range s.textLayout().selection(start, end) {
+		l = append(l, MakeRect(s.origin.X+sp[0], s.origin.Y-s.Font.Size, s.origin.X+sp[1], s.origin.Y+s.Font.Size/4))
+	}
+	return l
+}
+
+// InputHandler is the Handler of text inputs, it implements editing of the
+// text. The caret goes over the characters as they are seen, with their
+// accents and joined emojis, and the arrows move it on the screen when the
+// text mixes both directions.
+type InputHandler struct {
+	e *ConcreteElement
+
+	// Filter, if set, decides which typed characters are accepted
+	Filter func(rune) bool
+	// OnChange is called after each edit of the text
+	OnChange func(text string)
+	// OnCommit is called when Enter is pressed or the input loses the focus
+	OnCommit func(text string)
+	// OnFocusChange is called when the input gains or loses the focus
+	OnFocusChange func(focused bool)
+	// History, if set, records the edits so that Ctrl+Z undoes them and
+	// Ctrl+Y or Ctrl+Shift+Z redoes them. Typing a word is undone at once.
+	History *History
+
+	selecting bool // Whether the mouse is selecting text
+}
+
+// NewTextInputElement creates an editable text element, x and y being the
+// start of its baseline
+func NewTextInputElement(parent *AbstractElement, x, y int, font Font) *ConcreteElement {
+	te := NewTextElement(parent, x, y, font, true)
+	te.SetType("input")
+	te.Handler = &InputHandler{e: te}
+	te.Input().updateArea()
+	return te
+}
+
+// Input is a helper that casts the element's handler to an InputHandler and returns it
+func (e *ConcreteElement) Input() *InputHandler {
+	return e.Handler.(*InputHandler)
+}
+
+// Text returns the content of the input
+func (h *InputHandler) Text() string {
+	return h.e.TextShape().Content
+}
+
+// SetText replaces the content of the input and moves the caret to its end.
+// OnChange isn't called.
+func (h *InputHandler) SetText(text string) {
+	ts := h.e.TextShape()
+	ts.Content = text
+	ts.caret = utf8.RuneCountInString(text)
+	ts.anchor = ts.caret
+	h.updateArea()
+}
+
+// Caret returns the position of the caret, as a rune index
+func (h *InputHandler) Caret() int {
+	return h.e.TextShape().caret
+}
+
+// SetCaret moves the caret, pos is a rune index. A position inside a
+// character made of several runes goes to its start. The selection is
+// cleared and typing is then undone separately from the edits made before.
+func (h *InputHandler) SetCaret(pos int) {
+	h.Select(pos, pos)
+}
+
+// Selection returns the rune indexes of the start and the end of the
+// selected text, both being the caret if nothing is selected
+func (h *InputHandler) Selection() (start, end int) {
+	ts := h.e.TextShape()
+	if ts.anchor < ts.caret {
+		return ts.anchor, ts.caret
+	}
+	return ts.caret, ts.anchor
+}
+
+// SelectedText returns the selected part of the text
+func (h *InputHandler) SelectedText() string {
+	start, end := h.Selection()
+	return string([]rune(h.Text())[start:end])
+}
+
+// Select selects the text between the rune indexes anchor and caret, moving
+// the caret like SetCaret does
+func (h *InputHandler) Select(anchor, caret int) {
+	ts := h.e.TextShape()
+	l := ts.textLayout()
+	ts.anchor, ts.caret = l.snap(anchor), l.snap(caret)
+	Invalidate(h.e)
+	if h.History != nil {
+		h.History.Seal()
+	}
+}
+
+// moveCaret moves the caret to pos, selecting the text from the other end of
+// the selection if extend is set
+func (h *InputHandler) moveCaret(pos int, extend bool) {
+	anchor := pos
+	if extend {
+		anchor = h.e.TextShape().anchor
+	}
+	h.Select(anchor, pos)
+}
+
+// updateArea sets the element's area to the measured size of the text,
+// so that it can be clicked before being drawn
+func (h *InputHandler) updateArea() {
+	Invalidate(h.e)
+	ts := h.e.TextShape()
+	w := ts.textLayout().width()
+	if w < ts.Font.Size {
+		w = ts.Font.Size
+	}
+	h.e.Area = MakeRect(ts.origin.X, ts.origin.Y-ts.Font.Size, ts.origin.X+w, ts.origin.Y+ts.Font.Size/4)
+	Invalidate(h.e)
+}
+
+// replace replaces the runes from start to end with ins, the caret going after them
+func (h *InputHandler) replace(start, end int, ins []rune) {
+	text := []rune(h.Text())
+	text = append(text[:start:start], append(ins, text[end:]...)...)
+	h.edit(text, start+len(ins))
+}
+
+func (h *InputHandler) edit(text []rune, caret int) {
+	ts := h.e.TextShape()
+	if h.History != nil {
+		h.History.Do(&textEdit{h: h, before: ts.Content, after: string(text),
+			anchorBefore: ts.anchor, caretBefore: ts.caret, caretAfter: caret})
+		return
+	}
+	h.apply(string(text), caret, caret)
+}
+
+func (h *InputHandler) apply(text string, anchor, caret int) {
+	ts := h.e.TextShape()
+	ts.Content = text
+	ts.anchor, ts.caret = anchor, caret
+	h.updateArea()
+	if h.OnChange != nil {
+		h.OnChange(ts.Content)
+	}
+}
+
+// textEdit is an edit of the text of an input
+type textEdit struct {
+	h                       *InputHandler
+	before, after           string
+	anchorBefore            int // Undoing the edit selects the text selected before it
+	caretBefore, caretAfter int
+}
+
+func (t *textEdit) Do()            { t.h.apply(t.after, t.caretAfter, t.caretAfter) }
+func (t *textEdit) Undo()          { t.h.apply(t.before, t.anchorBefore, t.caretBefore) }
+func (t *textEdit) String() string { return "typing" }
+
+// kind returns 1 for insertions, -1 for deletions before the caret and -2
+// for deletions after it
+func (t *textEdit) kind() int {
+	switch {
+	case len(t.after) > len(t.before):
+		return 1
+	case t.caretAfter < t.caretBefore:
+		return -1
+	}
+	return -2
+}
+
+// Merge absorbs next if it continues the same kind of edit from where t left
+// the caret. Insertions are split at the start of words, and edits of a
+// selection aren't merged.
+func (t *textEdit) Merge(next Command) bool {
+	n, ok := next.(*textEdit)
+	if !ok || n.h != t.h || n.caretBefore != t.caretAfter || n.anchorBefore != n.caretBefore ||
+		n.before != t.after || n.kind() != t.kind() {
+		return false
+	}
+	if t.kind() == 1 {
+		last, typed := []rune(t.after)[t.caretAfter-1], []rune(n.after)[n.caretBefore]
+		if unicode.IsSpace(last) && !unicode.IsSpace(typed) {
+			return false
+		}
+	}
+	t.after, t.caretAfter = n.after, n.caretAfter
+	return true
+}
+
+// SetText replaces the text of the input like its SetText, as an undoable
+// edit that calls OnChange
+func (h *History) SetText(in *InputHandler, text string) {
+	ts := in.e.TextShape()
+	h.Do(&textEdit{h: in, before: ts.Content, after: text,
+		anchorBefore: ts.anchor, caretBefore: ts.caret, caretAfter: utf8.RuneCountInString(text)})
+	h.Seal()
+}
+
+func (h *InputHandler) Focusable() bool {
+	return h.e.TextShape().Editable
+}
+
+func (h *InputHandler) OnFocus() {
+	h.e.TextShape().showCaret = true
+	Invalidate(h.e)
+	if h.OnFocusChange != nil {
+		h.OnFocusChange(true)
+	}
+}
+
+func (h *InputHandler) OnBlur() {
+	h.e.TextShape().showCaret = false
+	Invalidate(h.e)
+	if h.OnFocusChange != nil {
+		h.OnFocusChange(false)
+	}
+	if h.OnCommit != nil {
+		h.OnCommit(h.Text())
+	}
+}
+
+// OnMouseEvent moves the caret to the clicked position, selecting the text up
+// to it with Shift, and selects the text the mouse is dragged over
+func (h *InputHandler) OnMouseEvent(evt *MouseEvent) bool {
+	ts := h.e.TextShape()
+	pos := ts.textLayout().hit(evt.Pos.X - ts.origin.X)
+	switch {
+	case evt.Action == EventPress && evt.Button == MouseButtonLeft:
+		h.selecting = true
+		if s := SceneOf(h.e); s != nil {
+			s.GrabMouse(h.e)
+		}
+		h.moveCaret(pos, evt.Mod.Shift)
+	case evt.Action == EventMove && h.selecting:
+		h.moveCaret(pos, true)
+	case evt.Action == EventRelease && h.selecting:
+		h.selecting = false
+	default:
+		return true
+	}
+	return false
+}
+
+func (h *InputHandler) OnCharEvent(evt *CharEvent) bool {
+	ts := h.e.TextShape()
+	if !ts.Editable || !unicode.IsPrint(evt.Char) || (h.Filter != nil && !h.Filter(evt.Char)) {
+		return false
+	}
+	start, end := h.Selection()
+	h.replace(start, end, []rune{evt.Char})
+	return true
+}
+
+// OnKeyEvent moves the caret with the arrows, Home and End, selecting the
+// text it goes over with Shift, selects everything with Ctrl+A and deletes
+// the selection or the character before or after the caret with Backspace
+// and Delete
+func (h *InputHandler) OnKeyEvent(evt *KeyEvent) bool {
+	if evt.Action == EventRelease {
+		return false
+	}
+	ts := h.e.TextShape()
+	l := ts.textLayout()
+	start, end := h.Selection()
+	shift := evt.Mod.Shift
+	if h.History != nil && evt.Mod.Control {
+		switch {
+		case evt.Key == KeyZ && !evt.Mod.Shift:
+			h.History.Undo()
+			return true
+		case evt.Key == KeyZ, evt.Key == KeyY:
+			h.History.Redo()
+			return true
+		}
+	}
+	switch evt.Key {
+	case KeyLeft, KeyRight:
+		right := evt.Key == KeyRight
+		if start == end || shift {
+			h.moveCaret(l.move(ts.caret, right), shift)
+			break
+		}
+		// The caret goes to the side of the selection the arrow points to
+		pos := start
+		if l.caretX(end) > l.caretX(start) == right {
+			pos = end
+		}
+		h.SetCaret(pos)
+	case KeyHome:
+		h.moveCaret(0, shift)
+	case KeyEnd:
+		h.moveCaret(len(l.text), shift)
+	case KeyA:
+		if !evt.Mod.Control {
+			return false
+		}
+		h.Select(0, len(l.text))
+	case KeyBackspace, KeyDelete:
+		if !ts.Editable {
+			return true
+		}
+		if start == end && evt.Key == KeyBackspace {
+			start = l.prev(start)
+		} else if start == end {
+			end = l.next(end)
+		}
+		if start < end {
+			h.replace(start, end, nil)
+		}
+	case KeyEnter:
+		if h.OnCommit == nil {
+			return false
+		}
+		h.OnCommit(ts.Content)
+	default:
+		return false
+	}
+	return true
+}
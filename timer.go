@@ -0,0 +1,120 @@
+package gosui
+
+import (
+	"container/heap"
+	"time"
+)
+
+// TimerID identifies a timer created by SetTimeout or SetInterval
+type TimerID int
+
+type timer struct {
+	id       TimerID
+	deadline time.Time
+	interval time.Duration // 0 for timeouts
+	fn       func()
+	index    int // Index in the heap
+}
+
+// timerHeap orders timers by deadline, then by creation
+type timerHeap []*timer
+
+func (h timerHeap) Len() int { return len(h) }
+func (h timerHeap) Less(i, j int) bool {
+	if h[i].deadline.Equal(h[j].deadline) {
+		return h[i].id < h[j].id
+	}
+	return h[i].deadline.Before(h[j].deadline)
+}
+func (h timerHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index, h[j].index = i, j
+}
+func (h *timerHeap) Push(x interface{}) {
+	t := x.(*timer)
+	t.index = len(*h)
+	*h = append(*h, t)
+}
+func (h *timerHeap) Pop() interface{} {
+	old := *h
+	t := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return t
+}
+
+// SetTimeout calls fn once on the UI loop, d after now by the scene's clock
+func (s *Scene) SetTimeout(d time.Duration, fn func()) TimerID {
+	return s.addTimer(d, 0, fn)
+}
+
+// MinInterval is the shortest period of the timers of SetInterval
+const MinInterval = time.Millisecond
+
+// SetInterval calls fn on the UI loop every d, until the timer is cleared.
+// Calls missed while the loop was busy aren't made up for. Periods shorter
+// than MinInterval, including zero and negative ones, are raised to it.
+func (s *Scene) SetInterval(d time.Duration, fn func()) TimerID {
+	if d < MinInterval {
+		d = MinInterval
+	}
+	return s.addTimer(d, d, fn)
+}
+
+func (s *Scene) addTimer(d, interval time.Duration, fn func()) TimerID {
+	s.lastTimer++
+	t := &timer{id: s.lastTimer, deadline: s.clock.Now().Add(d), interval: interval, fn: fn}
+	heap.Push(&s.timers, t)
+	s.timerIDs[t.id] = t
+	return t.id
+}
+
+// ClearTimer cancels a timer, it does nothing if the timer has already fired or been cleared
+func (s *Scene) ClearTimer(id TimerID) {
+	if t, ok := s.timerIDs[id]; ok {
+		delete(s.timerIDs, id)
+		if t.index >= 0 {
+			heap.Remove(&s.timers, t.index)
+		}
+	}
+}
+
+// NextTimer returns the deadline of the next timer, ok is false if there's none
+func (s *Scene) NextTimer() (deadline time.Time, ok bool) {
+	if len(s.timers) == 0 {
+		return deadline, false
+	}
+	return s.timers[0].deadline, true
+}
+
+// timerDue reports whether a timer has to fire at now
+func (s *Scene) timerDue(now time.Time) bool {
+	return len(s.timers) > 0 && !s.timers[0].deadline.After(now)
+}
+
+// runTimers fires the timers that are due at now. Timers created or rescheduled
+// by the callbacks fire on the next update at the earliest.
+func (s *Scene) runTimers(now time.Time) {
+	var due []*timer
+	for s.timerDue(now) {
+		t := heap.Pop(&s.timers).(*timer)
+		t.index = -1
+		due = append(due, t)
+	}
+	for _, t := range due {
+		if s.timerIDs[t.id] != t {
+			// Cleared by a previous callback
+			continue
+		}
+		if t.interval == 0 {
+			delete(s.timerIDs, t.id)
+		}
+		t.fn()
+		if t.interval > 0 && s.timerIDs[t.id] == t {
+			t.deadline = t.deadline.Add(t.interval)
+			if t.deadline.Before(now) {
+				t.deadline = now.Add(t.interval)
+			}
+			heap.Push(&s.timers, t)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package gosui
+
+import (
+	"time"
+
+	chk "launchpad.net/gocheck"
+)
+
+func (s *MySuite) TestTimers(c *chk.C) {
+	sc := NewScene()
+	clock := NewFakeClock(time.Unix(0, 0))
+	sc.SetClock(clock)
+	var calls []string
+	step := func(d time.Duration) {
+		clock.Advance(d)
+		sc.Update()
+	}
+
+	b := sc.SetTimeout(30*time.Millisecond, func() { calls = append(calls, "b") })
+	sc.SetTimeout(10*time.Millisecond, func() { calls = append(calls, "a") })
+	sc.SetTimeout(20*time.Millisecond, func() {
+		calls = append(calls, "cancel")
+		sc.ClearTimer(b)
+	})
+	var tick TimerID
+	n := 0
+	tick = sc.SetInterval(15*time.Millisecond, func() {
+		n++
+		calls = append(calls, "tick")
+		if n == 3 {
+			sc.ClearTimer(tick)
+		}
+	})
+	next, ok := sc.NextTimer()
+	c.Check(ok, chk.Equals, true)
+	c.Check(next, chk.Equals, time.Unix(0, 0).Add(10*time.Millisecond))
+	c.Check(sc.Idle(), chk.Equals, true)
+
+	step(9 * time.Millisecond)
+	c.Check(calls, chk.IsNil)
+	clock.Advance(time.Millisecond)
+	c.Check(sc.Idle(), chk.Equals, false)
+	sc.Update()
+	c.Check(calls, chk.DeepEquals, []string{"a"})
+	// Timers due in the same update fire in the order of their deadlines,
+	// b is cleared by the callback of the timer before it
+	step(20 * time.Millisecond)
+	c.Check(calls, chk.DeepEquals, []string{"a", "tick", "cancel"})
+	// Missed ticks aren't made up for
+	step(100 * time.Millisecond)
+	c.Check(calls, chk.DeepEquals, []string{"a", "tick", "cancel", "tick"})
+	step(15 * time.Millisecond)
+	step(15 * time.Millisecond)
+	c.Check(calls, chk.DeepEquals, []string{"a", "tick", "cancel", "tick", "tick"})
+	_, ok = sc.NextTimer()
+	c.Check(ok, chk.Equals, false)
+
+	// A timeout created by a callback doesn't fire in the same update
+	sc.SetTimeout(0, func() {
+		sc.SetTimeout(0, func() { calls = append(calls, "later") })
+	})
+	step(0)
+	c.Check(len(calls), chk.Equals, 5)
+	step(0)
+	c.Check(calls[5], chk.Equals, "later")
+
+	// Intervals that aren't positive repeat every MinInterval
+	for _, d := range []time.Duration{0, -time.Second} {
+		n = 0
+		tick = sc.SetInterval(d, func() { n++ })
+		step(MinInterval)
+		step(MinInterval)
+		c.Check(n, chk.Equals, 2)
+		sc.ClearTimer(tick)
+		c.Check(sc.timerIDs, chk.HasLen, 0)
+	}
+}
@@ -0,0 +1,114 @@
+package gosui
+
+import (
+	"image"
+	"time"
+)
+
+var (
+	// TooltipDelay is how long the cursor has to rest on an element before its tooltip is shown
+	TooltipDelay = 600 * time.Millisecond
+	// TooltipOffset is the position of tooltips relative to the cursor
+	TooltipOffset = image.Point{12, 20}
+)
+
+// tooltip is the state of the tooltip of the hovered element
+type tooltip struct {
+	owner   IElement // Innermost hovered element that has a tooltip
+	popup   *Popup   // Open tooltip, nil if none
+	pos     image.Point
+	timer   TimerID // Pending timer showing the tooltip, 0 if none
+	clicked bool    // Set when the owner was clicked, its tooltip isn't shown again until it's left
+}
+
+// SetTooltip gives a tooltip to the element, shown near the cursor after it has rested
+// on the element for TooltipDelay. build creates the content of the tooltip in the popup,
+// positioned relative to (0, 0), each time it's shown. A nil build removes the tooltip.
+func (s *Scene) SetTooltip(e IElement, build func(p *Popup)) {
+	if build == nil {
+		delete(s.tooltips, e.BaseElement())
+		if s.tip.owner != nil && s.tip.owner.BaseElement() == e.BaseElement() {
+			s.hideTooltip()
+			s.tip.owner = nil
+		}
+		return
+	}
+	s.tooltips[e.BaseElement()] = build
+}
+
+// Tooltip returns the open tooltip, nil if there's none
+func (s *Scene) Tooltip() *Popup {
+	return s.tip.popup
+}
+
+// tooltipOwner returns the innermost hovered element that has a tooltip
+func (s *Scene) tooltipOwner() IElement {
+	if s.grab != nil {
+		return nil
+	}
+	for i := len(s.hovered) - 1; i >= 0; i-- {
+		if _, ok := s.tooltips[s.hovered[i].BaseElement()]; ok {
+			if s.blockedByModal(s.hovered[i]) {
+				return nil
+			}
+			return s.hovered[i]
+		}
+	}
+	return nil
+}
+
+// updateTooltip is called on each mouse event, after the hovered elements are updated
+func (s *Scene) updateTooltip(evt *MouseEvent) {
+	s.tip.pos = evt.Pos
+	if evt.Action == EventPress || evt.Action == EventScroll {
+		s.hideTooltip()
+		s.tip.clicked = true
+		return
+	}
+	owner := s.tooltipOwner()
+	if owner == s.tip.owner {
+		return
+	}
+	s.hideTooltip()
+	s.tip.owner, s.tip.clicked = owner, false
+	if owner == nil {
+		return
+	}
+	s.tip.timer = s.SetTimeout(TooltipDelay, func() {
+		s.tip.timer = 0
+		s.showTooltip()
+	})
+}
+
+func (s *Scene) showTooltip() {
+	if s.tip.owner == nil || s.tip.clicked {
+		return
+	}
+	build := s.tooltips[s.tip.owner.BaseElement()]
+	if build == nil {
+		return
+	}
+	p := s.NewPopup()
+	p.KeepOpen = true
+	SetAccessible(p, Accessible{Role: RoleTooltip})
+	build(p)
+	// Keep the tooltip from covering the cursor
+	r := fitIn(image.Rectangle{Max: p.Size()}.Add(s.tip.pos.Add(TooltipOffset)), s.viewport)
+	if s.tip.pos.In(r) {
+		r = r.Add(image.Point{0, s.tip.pos.Y - r.Max.Y - 4})
+	}
+	p.ShowAt(r.Min)
+	s.tip.popup = p
+}
+
+// hideTooltip closes the tooltip and cancels the pending one
+func (s *Scene) hideTooltip() {
+	if s.tip.timer != 0 {
+		s.ClearTimer(s.tip.timer)
+		s.tip.timer = 0
+	}
+	if p := s.tip.popup; p != nil {
+		s.tip.popup = nil
+		p.Close()
+	}
+}
@@ -0,0 +1,329 @@
+package gosui
+
+import (
+	"fmt"
+	"image"
+)
+
+// Command is a reversible change of elements. Do and Undo invalidate what
+// they change, so that replaying them redraws the scene.
+type Command interface {
+	Do()
+	Undo()
+}
+
+// merger is implemented by commands that can absorb the command recorded
+// after them, like the edits of typing a word
+type merger interface {
+	Merge(next Command) bool
+}
+
+// transaction is a group of commands undone and redone together
+type transaction struct {
+	name string
+	cmds []Command
+}
+
+func (t *transaction) Do() {
+	for _, c := range t.cmds {
+		c.Do()
+	}
+}
+
+func (t *transaction) Undo() {
+	for i := len(t.cmds) - 1; i >= 0; i-- {
+		t.cmds[i].Undo()
+	}
+}
+
+// String returns the name of the transaction, or the name of its command
+// when it has a single one that implements fmt.Stringer
+func (t *transaction) String() string {
+	if t.name == "" && len(t.cmds) == 1 {
+		if s, ok := t.cmds[0].(fmt.Stringer); ok {
+			return s.String()
+		}
+	}
+	return t.name
+}
+
+// History records commands to undo and redo them. Commands done outside a
+// transaction are undone one at a time, except consecutive ones that merge.
+type History struct {
+	// Limit is the number of undoable transactions kept, 0 for no limit
+	Limit int
+	// OnChange is called when something is recorded, undone or redone
+	OnChange func()
+
+	undo, redo []*transaction
+	open       *transaction
+	depth      int
+	sealed     bool // Whether the last transaction can't absorb commands
+}
+
+// NewHistory creates an empty history
+func NewHistory() *History {
+	return new(History)
+}
+
+// Do does the command and records it
+func (h *History) Do(cmd Command) {
+	cmd.Do()
+	h.record(cmd)
+}
+
+func (h *History) record(cmd Command) {
+	h.redo = nil
+	if h.open != nil {
+		h.open.cmds = append(h.open.cmds, cmd)
+		return
+	}
+	if n := len(h.undo); n > 0 && !h.sealed {
+		if last := h.undo[n-1]; last.name == "" && len(last.cmds) == 1 {
+			if m, ok := last.cmds[0].(merger); ok && m.Merge(cmd) {
+				h.changed()
+				return
+			}
+		}
+	}
+	h.push(&transaction{cmds: []Command{cmd}})
+}
+
+func (h *History) push(t *transaction) {
+	h.sealed = false
+	h.undo = append(h.undo, t)
+	if h.Limit > 0 && len(h.undo) > h.Limit {
+		h.undo = append(h.undo[:0], h.undo[len(h.undo)-h.Limit:]...)
+	}
+	h.changed()
+}
+
+// Seal stops the next command from merging with the last one, like when the
+// caret of an input is moved by other means than typing
+func (h *History) Seal() {
+	h.sealed = true
+}
+
+func (h *History) changed() {
+	if h.OnChange != nil {
+		h.OnChange()
+	}
+}
+
+// Begin opens a transaction: the commands done until the matching Commit are
+// undone at once. Transactions can be nested, the outermost one is recorded.
+func (h *History) Begin(name string) {
+	if h.depth == 0 {
+		h.open = &transaction{name: name}
+	}
+	h.depth++
+}
+
+// Commit closes the transaction opened by the last Begin
+func (h *History) Commit() {
+	if h.depth == 0 {
+		panic("gosui: Commit without Begin")
+	}
+	h.depth--
+	if h.depth > 0 {
+		return
+	}
+	t := h.open
+	h.open = nil
+	if len(t.cmds) > 0 {
+		h.push(t)
+	}
+}
+
+// Rollback undoes the commands of the open transaction and closes it,
+// nested transactions included
+func (h *History) Rollback() {
+	if h.depth == 0 {
+		panic("gosui: Rollback without Begin")
+	}
+	t := h.open
+	h.open, h.depth = nil, 0
+	t.Undo()
+}
+
+// Transaction runs fn in a transaction named name
+func (h *History) Transaction(name string, fn func()) {
+	h.Begin(name)
+	defer h.Commit()
+	fn()
+}
+
+// CanUndo reports whether there's something to undo
+func (h *History) CanUndo() bool {
+	return len(h.undo) > 0 && h.open == nil
+}
+
+// CanRedo reports whether there's something to redo
+func (h *History) CanRedo() bool {
+	return len(h.redo) > 0 && h.open == nil
+}
+
+// UndoName returns the name of what Undo would undo, "" if there's nothing
+func (h *History) UndoName() string {
+	if len(h.undo) == 0 {
+		return ""
+	}
+	return h.undo[len(h.undo)-1].String()
+}
+
+// RedoName returns the name of what Redo would redo, "" if there's nothing
+func (h *History) RedoName() string {
+	if len(h.redo) == 0 {
+		return ""
+	}
+	return h.redo[len(h.redo)-1].String()
+}
+
+// Undo undoes the last transaction. It returns false if there was nothing to
+// undo or if a transaction is open.
+func (h *History) Undo() bool {
+	if !h.CanUndo() {
+		return false
+	}
+	t := h.undo[len(h.undo)-1]
+	h.undo = h.undo[:len(h.undo)-1]
+	t.Undo()
+	h.redo = append(h.redo, t)
+	h.sealed = true
+	h.changed()
+	return true
+}
+
+// Redo redoes the last undone transaction. It returns false if there was
+// nothing to redo or if a transaction is open.
+func (h *History) Redo() bool {
+	if !h.CanRedo() {
+		return false
+	}
+	t := h.redo[len(h.redo)-1]
+	h.redo = h.redo[:len(h.redo)-1]
+	t.Do()
+	h.undo = append(h.undo, t)
+	h.sealed = true
+	h.changed()
+	return true
+}
+
+// Clear forgets everything recorded
+func (h *History) Clear() {
+	h.undo, h.redo = nil, nil
+	h.changed()
+}
+
+// childCmd adds or removes a child
+type childCmd struct {
+	parent *AbstractElement
+	child  IElement
+	index  int
+	zIndex float32
+	add    bool
+}
+
+func (c *childCmd) insert() {
+	c.parent.InsertChild(c.index, c.child)
+	c.child.BaseElement().zIndex = c.zIndex
+	Invalidate(c.child)
+}
+
+func (c *childCmd) remove() {
+	Invalidate(c.child)
+	c.parent.RemoveChild(c.child)
+}
+
+func (c *childCmd) Do() {
+	if c.add {
+		c.insert()
+	} else {
+		c.remove()
+	}
+}
+
+func (c *childCmd) Undo() {
+	if c.add {
+		c.remove()
+	} else {
+		c.insert()
+	}
+}
+
+func (c *childCmd) String() string {
+	if c.add {
+		return "add"
+	}
+	return "remove"
+}
+
+// childIndex returns the index of child in the children of parent, -1 if
+// it isn't one of them
+func childIndex(parent *AbstractElement, child IElement) int {
+	for i, c := range parent.children {
+		if c == child {
+			return i
+		}
+	}
+	return -1
+}
+
+// AddChild adds child to the end of the children of parent
+func (h *History) AddChild(parent *AbstractElement, child IElement) {
+	h.Do(&childCmd{parent: parent, child: child, index: len(parent.children), add: true})
+}
+
+// RemoveChild removes child from its parent, it does nothing if it has none
+func (h *History) RemoveChild(child IElement) {
+	p := child.BaseElement().parent
+	if p == nil {
+		return
+	}
+	h.Do(&childCmd{parent: p, child: child, index: childIndex(p, child), zIndex: child.BaseElement().zIndex})
+}
+
+// MoveChild moves child to index i of the children of parent, which may be
+// its current parent
+func (h *History) MoveChild(child IElement, parent *AbstractElement, i int) {
+	z := child.BaseElement().zIndex
+	h.Transaction("move", func() {
+		h.RemoveChild(child)
+		h.Do(&childCmd{parent: parent, child: child, index: i, zIndex: z, add: true})
+	})
+}
+
+// areaCmd resizes an element
+type areaCmd struct {
+	e             IElement
+	before, after image.Rectangle
+}
+
+func (c *areaCmd) Do()            { Resize(c.e, c.after) }
+func (c *areaCmd) Undo()          { Resize(c.e, c.before) }
+func (c *areaCmd) String() string { return "resize" }
+
+// Resize gives area to the element with Resize
+func (h *History) Resize(e IElement, area image.Rectangle) {
+	h.Do(&areaCmd{e, e.BaseElement().Area, area})
+}
+
+// paintCmd changes the paint of an element
+type paintCmd struct {
+	e             *ConcreteElement
+	before, after Paint
+}
+
+func (c *paintCmd) set(p Paint) {
+	c.e.Paint = p
+	Invalidate(c.e)
+}
+
+func (c *paintCmd) Do()            { c.set(c.after) }
+func (c *paintCmd) Undo()          { c.set(c.before) }
+func (c *paintCmd) String() string { return "paint" }
+
+// SetPaint changes the paint of the element
+func (h *History) SetPaint(e *ConcreteElement, p Paint) {
+	h.Do(&paintCmd{e, e.Paint, p})
+}
@@ -0,0 +1,117 @@
+package gosui
+
+import (
+	chk "launchpad.net/gocheck"
+)
+
+func (s *MySuite) TestHistory(c *chk.C) {
+	sc := NewScene()
+	root := sc.Root()
+	n := len(root.Children())
+	box := NewAbstractElement(root, MakeRect(0, 0, 100, 100))
+	a := NewRectElement(box, MakeRect(0, 0, 10, 10))
+	b := NewRectElement(box, MakeRect(20, 0, 30, 10))
+	b.SetZIndex(2)
+	h := NewHistory()
+	changes := 0
+	h.OnChange = func() { changes++ }
+	c.Check(h.Undo(), chk.Equals, false)
+
+	h.RemoveChild(b)
+	h.Resize(a, MakeRect(5, 5, 50, 50))
+	h.Transaction("restyle", func() {
+		h.SetPaint(a, NoStroke(Color{255, 0, 0, 255}))
+		h.Transaction("nested", func() {
+			h.MoveChild(a, root, 0)
+		})
+	})
+	c.Check(box.Children(), chk.HasLen, 0)
+	c.Check(root.Children()[0], chk.Equals, IElement(a))
+	c.Check(h.UndoName(), chk.Equals, "restyle")
+	c.Check(changes, chk.Equals, 3)
+
+	sc.RedrawDirty(new(DummyBackend))
+	c.Check(h.Undo(), chk.Equals, true)
+	c.Check(box.Children(), chk.DeepEquals, []IElement{a})
+	c.Check(a.FillColor, chk.Equals, Color{})
+	c.Check(sc.NeedsRedraw(), chk.Equals, true)
+	h.Undo()
+	c.Check(a.Area, chk.Equals, MakeRect(0, 0, 10, 10))
+	c.Check(h.UndoName(), chk.Equals, "remove")
+	h.Undo()
+	c.Check(box.Children(), chk.DeepEquals, []IElement{a, b})
+	c.Check(b.ZIndex(), chk.Equals, float32(2))
+	c.Check(h.CanUndo(), chk.Equals, false)
+
+	c.Check(h.RedoName(), chk.Equals, "remove")
+	h.Redo()
+	h.Redo()
+	c.Check(box.Children(), chk.DeepEquals, []IElement{a})
+	c.Check(a.Area, chk.Equals, MakeRect(5, 5, 50, 50))
+	h.AddChild(box, b)
+	c.Check(h.CanRedo(), chk.Equals, false)
+	c.Check(root.Children(), chk.HasLen, n+1)
+
+	// Rolled back transactions aren't recorded
+	h.Begin("drag")
+	h.Resize(box, MakeRect(10, 10, 110, 110))
+	h.Rollback()
+	c.Check(box.Area, chk.Equals, MakeRect(0, 0, 100, 100))
+	c.Check(h.UndoName(), chk.Equals, "add")
+
+	h.Limit = 2
+	h.Resize(box, MakeRect(10, 10, 110, 110))
+	c.Check(h.Undo(), chk.Equals, true)
+	c.Check(h.Undo(), chk.Equals, true)
+	c.Check(h.Undo(), chk.Equals, false)
+}
+
+func (s *MySuite) TestTypingUndo(c *chk.C) {
+	sc := NewScene()
+	te := NewTextInputElement(sc.Root(), 0, 20, Font{"Arial", 10, Regular})
+	in := te.Input()
+	in.History = NewHistory()
+	var changes []string
+	in.OnChange = func(t string) { changes = append(changes, t) }
+	sc.Focus(te)
+	typ := func(s string) {
+		for _, r := range s {
+			sc.HandleChar(&CharEvent{Char: r})
+		}
+	}
+	ctrl := func(k Key, shift bool) {
+		sc.HandleKey(&KeyEvent{Key: k, Mod: Modifiers{Control: true, Shift: shift}})
+	}
+
+	typ("hello world")
+	sc.HandleKey(&KeyEvent{Key: KeyBackspace})
+	sc.HandleKey(&KeyEvent{Key: KeyBackspace})
+	in.SetCaret(0)
+	typ(">")
+	c.Check(in.Text(), chk.Equals, ">hello wor")
+
+	ctrl(KeyZ, false)
+	c.Check(in.Text(), chk.Equals, "hello wor")
+	ctrl(KeyZ, false)
+	c.Check(in.Text(), chk.Equals, "hello world")
+	c.Check(in.Caret(), chk.Equals, 11)
+	ctrl(KeyZ, false)
+	c.Check(in.Text(), chk.Equals, "hello ")
+	ctrl(KeyZ, false)
+	c.Check(in.Text(), chk.Equals, "")
+	c.Check(in.History.CanUndo(), chk.Equals, false)
+	ctrl(KeyZ, true)
+	ctrl(KeyY, false)
+	c.Check(in.Text(), chk.Equals, "hello world")
+	c.Check(changes[len(changes)-4:], chk.DeepEquals, []string{"hello ", "", "hello ", "hello world"})
+
+	// Typing after an undo starts a new edit
+	ctrl(KeyZ, false)
+	typ("there")
+	ctrl(KeyZ, false)
+	c.Check(in.Text(), chk.Equals, "hello ")
+	in.History.SetText(in, "bye")
+	typ("!")
+	ctrl(KeyZ, false)
+	c.Check(in.Text(), chk.Equals, "bye")
+}
@@ -10,8 +10,8 @@ import (
 type Window struct {
 	b gs.RenderBackend
 
-	area image.Rectangle
-	root *gs.AbstractElement
+	area  image.Rectangle
+	scene *gs.Scene
 }
 
 func (wn *Window) Size() (w, h int) {
@@ -20,17 +20,23 @@ func (wn *Window) Size() (w, h int) {
 
 func NewWindow(b gs.RenderBackend, w, h int, title string) *Window {
 	b.Init(w, h)
-	return &Window{
-		b:    b,
-		area: gs.MakeRectWH(0, 0, w, h),
-		root: gs.NewRootElement(),
+	wn := &Window{
+		b:     b,
+		area:  gs.MakeRectWH(0, 0, w, h),
+		scene: gs.NewScene(),
 	}
+	wn.scene.SetViewport(wn.area)
+	return wn
 }
 
 func (wn *Window) RootElement() *gs.AbstractElement {
-	return wn.root
+	return wn.scene.Root()
+}
+
+func (wn *Window) Scene() *gs.Scene {
+	return wn.scene
 }
 
 func (wn *Window) Start() {
-	wn.root.Draw(wn.b)
+	wn.RootElement().Draw(wn.b)
 }
@@ -0,0 +1,65 @@
+package widgets
+
+import (
+	"image"
+
+	gs "github.com/phaikawl/gosui"
+)
+
+// Button is a push button with a text label
+type Button struct {
+	control
+	bg    *gs.ConcreteElement
+	label *gs.ConcreteElement
+
+	// OnClick is called when the button is clicked or activated with the keyboard
+	OnClick func()
+}
+
+// NewButton creates a new Button as a child of parent
+func NewButton(parent *gs.AbstractElement, area image.Rectangle, text string) *Button {
+	b := new(Button)
+	b.init(parent, area)
+	b.SetType("button")
+	b.role = gs.RoleButton
+	b.bg = gs.NewRectElement(b.AbstractElement, area)
+	b.label = newLabel(b.AbstractElement, area, "", b.Style.Font)
+	b.label.SetZIndex(0.1)
+	b.activate = func() {
+		if b.OnClick != nil {
+			b.OnClick()
+		}
+	}
+	b.update = b.updateLook
+	b.SetText(text)
+	return b
+}
+
+// Text returns the label of the button
+func (b *Button) Text() string {
+	return b.label.TextShape().Content
+}
+
+// SetText changes the label of the button
+func (b *Button) SetText(text string) {
+	b.label.TextShape().Content = text
+	b.refresh()
+}
+
+// Click activates the button as if the user clicked it
+func (b *Button) Click() {
+	if b.Enabled() {
+		b.activate()
+	}
+}
+
+func (b *Button) updateLook() {
+	b.bg.Paint = b.Style.Paint(b.State())
+	b.bg.RectShape().SetAllCornerRadiusTo(b.Style.CornerRadius)
+	b.label.FillColor = b.Style.TextColor(b.State())
+	// The label is centered with the font of the style, which may have changed
+	ts := b.label.TextShape()
+	ts.Font = b.Style.Font
+	w, _ := gs.MeasureText(ts.Content, ts.Font)
+	ts.SetOrigin(image.Point{b.X() + (b.W()-w)/2, b.Y() + (b.H()+ts.Font.Size*7/10)/2})
+}
@@ -0,0 +1,83 @@
+package widgets
+
+import (
+	"image"
+
+	gs "github.com/phaikawl/gosui"
+)
+
+const (
+	markSize = 16 // Size of checkbox and radio button boxes
+	markGap  = 6  // Space between a box and its label
+)
+
+// boxArea returns the area of a square box of markSize at the left of area,
+// vertically centered
+func boxArea(area image.Rectangle) image.Rectangle {
+	y := area.Min.Y + (area.Dy()-markSize)/2
+	return gs.MakeRectWH(area.Min.X, y, markSize, markSize)
+}
+
+func labelArea(area image.Rectangle) image.Rectangle {
+	area.Min.X += markSize + markGap
+	return area
+}
+
+// Checkbox is a box that can be checked or unchecked, followed by a label
+type Checkbox struct {
+	control
+	box, mark *gs.ConcreteElement
+	label     *gs.ConcreteElement
+	checked   bool
+
+	// OnChange is called with the new value when the user toggles the checkbox
+	OnChange func(checked bool)
+}
+
+// NewCheckbox creates a new unchecked Checkbox as a child of parent
+func NewCheckbox(parent *gs.AbstractElement, area image.Rectangle, text string) *Checkbox {
+	c := new(Checkbox)
+	c.init(parent, area)
+	c.SetType("checkbox")
+	c.role = gs.RoleCheckBox
+	c.describe = func(a *gs.Accessible) { a.States |= checkState(c.checked) }
+	c.box = gs.NewRectElement(c.AbstractElement, boxArea(area))
+	c.mark = gs.NewRectElement(c.AbstractElement, boxArea(area).Inset(4))
+	c.mark.SetZIndex(0.1)
+	c.label = newLabel(c.AbstractElement, labelArea(area), text, c.Style.Font)
+	c.activate = func() {
+		c.SetChecked(!c.checked)
+		if c.OnChange != nil {
+			c.OnChange(c.checked)
+		}
+	}
+	c.update = c.updateLook
+	c.refresh()
+	return c
+}
+
+// Checked reports whether the checkbox is checked
+func (c *Checkbox) Checked() bool {
+	return c.checked
+}
+
+// SetChecked changes the value of the checkbox without calling OnChange
+func (c *Checkbox) SetChecked(checked bool) {
+	c.checked = checked
+	c.refresh()
+}
+
+func (c *Checkbox) updateLook() {
+	c.box.Paint = c.Style.Paint(c.State())
+	c.box.RectShape().SetAllCornerRadiusTo(c.Style.CornerRadius)
+	c.mark.Paint = gs.NoStroke(gs.Color{})
+	if c.checked {
+		c.mark.FillColor = c.Style.Accent
+		if !c.Enabled() {
+			c.mark.FillColor = c.Style.Disabled.StrokeColor
+		}
+	}
+	c.mark.RectShape().SetAllCornerRadiusTo(c.Style.CornerRadius / 2)
+	c.label.FillColor = c.Style.TextColor(c.State())
+	c.label.TextShape().Font = c.Style.Font
+}
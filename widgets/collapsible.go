@@ -0,0 +1,303 @@
+package widgets
+
+import (
+	"image"
+	"time"
+
+	gs "github.com/phaikawl/gosui"
+)
+
+var (
+	// HeaderHeight is the height of the headers of collapsible panels
+	HeaderHeight = 28
+
+	// ExpandDuration is how long collapsible panels take to expand or collapse,
+	// they change at once if it's 0 or they aren't in a Scene
+	ExpandDuration = 150 * time.Millisecond
+)
+
+// collapsibleHeader is the clickable title bar of a Collapsible
+type collapsibleHeader struct {
+	control
+	bg, arrow, label *gs.ConcreteElement
+}
+
+// Collapsible is a panel with a title bar that shows or hides its body when clicked.
+// The height of the panel is animated. The body is only attached to the tree
+// while the panel is fully expanded, its children are resized to fill it.
+type Collapsible struct {
+	*gs.AbstractElement
+	head       *collapsibleHeader
+	frame      *gs.ConcreteElement // Background of the body, grows and shrinks with it
+	body       *gs.AbstractElement
+	bodyHeight int
+	shown      int // Current height of the body
+	expanded   bool
+	anim       int // Incremented to cancel the running animation
+
+	// Set by containers: relayout is called when the height changes,
+	// expanding when the panel starts to expand
+	relayout, expanding func()
+
+	// OnToggle is called when the panel starts to expand or collapse
+	OnToggle func(expanded bool)
+}
+
+// NewCollapsible creates an expanded Collapsible, area is its expanded area
+func NewCollapsible(parent *gs.AbstractElement, area image.Rectangle, title string) *Collapsible {
+	c := &Collapsible{expanded: true}
+	c.AbstractElement = gs.NewAbstractElement(parent, area)
+	c.SetType("collapsible")
+	c.Handler = c
+	c.bodyHeight = area.Dy() - HeaderHeight
+	c.shown = c.bodyHeight
+
+	h := new(collapsibleHeader)
+	h.init(c.AbstractElement, c.headerArea())
+	h.bg = gs.NewRectElement(h.AbstractElement, h.Area)
+	h.arrow = newLabel(h.AbstractElement, h.Area.Add(image.Point{8, 0}), "", h.Style.Font)
+	h.arrow.SetZIndex(0.1)
+	h.label = newLabel(h.AbstractElement, h.Area.Add(image.Point{26, 0}), title, h.Style.Font)
+	h.label.SetZIndex(0.1)
+	hideGlyph(h.arrow)
+	h.role = gs.RoleButton
+	h.describe = func(a *gs.Accessible) { a.States |= expandState(c.expanded) }
+	h.activate = c.Toggle
+	h.keyHook = c.onKey
+	h.update = func() {
+		h.bg.Paint = h.Style.Paint(h.State())
+		h.arrow.FillColor = h.Style.TextColor(h.State())
+		h.label.FillColor = h.Style.TextColor(h.State())
+		if c.expanded {
+			h.arrow.TextShape().Content = "▼"
+		} else {
+			h.arrow.TextShape().Content = "▶"
+		}
+	}
+	c.head = h
+	h.refresh()
+
+	c.frame = gs.NewRectElement(c.AbstractElement, c.bodyArea())
+	c.frame.Paint = gs.NoStroke(h.Style.Base)
+	c.body = newFill(c.AbstractElement, c.bodyArea())
+	return c
+}
+
+func (c *Collapsible) headerArea() image.Rectangle {
+	return gs.MakeRectWH(c.X(), c.Y(), c.W(), HeaderHeight)
+}
+
+func (c *Collapsible) bodyArea() image.Rectangle {
+	return gs.MakeRectWH(c.X(), c.Y()+HeaderHeight, c.W(), c.shown)
+}
+
+// ApplyStyle restyles the title bar and the background of the body from
+// DefaultStyle with the rules of the stylesheet, then the content of the body
+func (c *Collapsible) ApplyStyle(sheet *gs.Stylesheet) {
+	c.head.Style = StyleFrom(sheet, c.AbstractElement, DefaultStyle)
+	c.head.refresh()
+	c.frame.FillColor = c.head.Style.Base
+	restyleContent(c.AbstractElement, c.body)
+}
+
+// Accessible describes the panel to assistive technologies
+func (c *Collapsible) Accessible() gs.Accessible {
+	return gs.Accessible{Role: gs.RoleGroup, Name: c.Title()}
+}
+
+// Title returns the text of the title bar
+func (c *Collapsible) Title() string {
+	return c.head.label.TextShape().Content
+}
+
+// SetTitle changes the text of the title bar
+func (c *Collapsible) SetTitle(title string) {
+	c.head.label.TextShape().Content = title
+	c.head.refresh()
+}
+
+// Body returns the element holding the content of the panel
+func (c *Collapsible) Body() *gs.AbstractElement {
+	return c.body
+}
+
+// BodyHeight returns the height of the body when expanded
+func (c *Collapsible) BodyHeight() int {
+	return c.bodyHeight
+}
+
+// SetBodyHeight changes the height of the body when expanded
+func (c *Collapsible) SetBodyHeight(h int) {
+	c.bodyHeight = h
+	if c.expanded {
+		c.setShown(h)
+	}
+}
+
+// Expanded reports whether the panel is expanded or expanding
+func (c *Collapsible) Expanded() bool {
+	return c.expanded
+}
+
+// Toggle expands the panel if it's collapsed and collapses it otherwise
+func (c *Collapsible) Toggle() {
+	c.SetExpanded(!c.expanded)
+}
+
+// SetExpanded expands or collapses the panel, with an animation if it's in a Scene
+func (c *Collapsible) SetExpanded(expanded bool) {
+	if expanded == c.expanded {
+		return
+	}
+	c.expanded = expanded
+	c.head.refresh()
+	if !expanded {
+		detach(c.body)
+	}
+	target := 0
+	if expanded {
+		target = c.bodyHeight
+	}
+	c.anim++
+	s := gs.SceneOf(c.AbstractElement)
+	if s == nil || ExpandDuration <= 0 {
+		c.setShown(target)
+	} else {
+		anim, from := c.anim, c.shown
+		var elapsed time.Duration
+		s.Animate(func(dt time.Duration) bool {
+			if anim != c.anim {
+				return false
+			}
+			elapsed += dt
+			if elapsed >= ExpandDuration {
+				c.setShown(target)
+				return false
+			}
+			c.setShown(from + int(int64(target-from)*int64(elapsed)/int64(ExpandDuration)))
+			return true
+		})
+	}
+	if expanded && c.expanding != nil {
+		c.expanding()
+	}
+	if c.OnToggle != nil {
+		c.OnToggle(expanded)
+	}
+}
+
+func (c *Collapsible) setShown(h int) {
+	gs.Invalidate(c.AbstractElement)
+	c.shown = h
+	c.Area.Max.Y = c.Y() + HeaderHeight + h
+	c.frame.Area = c.bodyArea()
+	if c.expanded && h == c.bodyHeight && c.body.Parent() == nil {
+		reparent(c.body, c.AbstractElement, c.bodyArea())
+		restyleContent(c.AbstractElement, c.body)
+	}
+	gs.Invalidate(c.AbstractElement)
+	if c.relayout != nil {
+		c.relayout()
+	}
+}
+
+// OnResize lays out the title bar and the body, the new height becomes
+// the expanded height if the panel is expanded
+func (c *Collapsible) OnResize(area image.Rectangle) {
+	if c.expanded {
+		c.bodyHeight = area.Dy() - HeaderHeight
+		c.shown = c.bodyHeight
+	} else {
+		c.Area.Max.Y = c.Y() + HeaderHeight + c.shown
+	}
+	gs.Resize(c.head.AbstractElement, c.headerArea())
+	c.frame.Area = c.bodyArea()
+	if c.body.Parent() != nil {
+		gs.Resize(c.body, c.bodyArea())
+	} else {
+		c.body.Area = c.bodyArea()
+	}
+}
+
+func (c *Collapsible) onKey(evt *gs.KeyEvent) bool {
+	if evt.Key != gs.KeyLeft && evt.Key != gs.KeyRight {
+		return false
+	}
+	if evt.Action != gs.EventRelease {
+		c.SetExpanded(evt.Key == gs.KeyRight)
+	}
+	return true
+}
+
+// Accordion stacks collapsible sections vertically
+type Accordion struct {
+	*gs.AbstractElement
+	sections []*Collapsible
+
+	// Exclusive makes expanding a section collapse the others
+	Exclusive bool
+}
+
+// NewAccordion creates an empty Accordion as a child of parent
+func NewAccordion(parent *gs.AbstractElement, area image.Rectangle) *Accordion {
+	a := new(Accordion)
+	a.AbstractElement = gs.NewAbstractElement(parent, area)
+	a.Handler = a
+	return a
+}
+
+// Sections returns the sections of the accordion, from the top
+func (a *Accordion) Sections() []*Collapsible {
+	return a.sections
+}
+
+// AddSection appends an expanded section whose body is bodyHeight high
+func (a *Accordion) AddSection(title string, bodyHeight int) *Collapsible {
+	y := a.Y()
+	if n := len(a.sections); n > 0 {
+		y = a.sections[n-1].Area.Max.Y
+	}
+	c := NewCollapsible(a.AbstractElement, gs.MakeRectWH(a.X(), y, a.W(), HeaderHeight+bodyHeight), title)
+	c.relayout = a.layout
+	c.expanding = func() {
+		if a.Exclusive {
+			a.collapseOthers(c)
+		}
+	}
+	a.sections = append(a.sections, c)
+	if a.Exclusive {
+		a.collapseOthers(c)
+	}
+	return c
+}
+
+func (a *Accordion) collapseOthers(c *Collapsible) {
+	for _, o := range a.sections {
+		if o != c {
+			o.SetExpanded(false)
+		}
+	}
+}
+
+// layout moves the sections so that each one starts where the previous one ends
+func (a *Accordion) layout() {
+	y := a.Y()
+	for _, c := range a.sections {
+		if d := y - c.Y(); d != 0 {
+			gs.Invalidate(c.AbstractElement)
+			c.MoveBy(0, d)
+			gs.Invalidate(c.AbstractElement)
+		}
+		y = c.Area.Max.Y
+	}
+}
+
+// OnResize gives the new width to all sections
+func (a *Accordion) OnResize(area image.Rectangle) {
+	for _, c := range a.sections {
+		r := c.Area
+		r.Min.X, r.Max.X = area.Min.X, area.Max.X
+		gs.Resize(c, r)
+	}
+	a.layout()
+}
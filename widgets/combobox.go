@@ -0,0 +1,228 @@
+package widgets
+
+import (
+	"image"
+	"strings"
+
+	gs "github.com/phaikawl/gosui"
+)
+
+// ComboBox is a text input with a list of suggestions.
+// Typing shows the suggestions starting with the text, picking one replaces the text.
+type ComboBox struct {
+	*gs.AbstractElement
+	Style  Style
+	frame  *gs.ConcreteElement
+	input  *gs.ConcreteElement
+	button *Button
+	items  []string
+	shown  []string // Items currently in the list
+	list   *listPopup
+	state  State
+
+	// OnChange is called with the text when the user picks a suggestion
+	// or commits the typed text
+	OnChange func(text string)
+}
+
+// NewComboBox creates an empty ComboBox offering the items
+func NewComboBox(parent *gs.AbstractElement, area image.Rectangle, items []string) *ComboBox {
+	c := new(ComboBox)
+	c.AbstractElement = gs.NewAbstractElement(parent, area)
+	c.SetType("combobox")
+	c.Handler = c
+	c.Style = DefaultStyle
+	c.items = items
+
+	c.frame = gs.NewRectElement(c.AbstractElement, area)
+	font := c.Style.Font
+	baseline := area.Min.Y + (area.Dy()+font.Size*7/10)/2
+	c.input = gs.NewTextInputElement(c.AbstractElement, area.Min.X+4, baseline, font)
+	c.input.SetZIndex(0.1)
+	in := c.input.Input()
+	in.OnChange = c.suggest
+	in.OnCommit = func(text string) {
+		if c.IsOpen() && c.list.highlighted >= 0 {
+			c.list.pick(c.list.highlighted)
+			return
+		}
+		if c.OnChange != nil {
+			c.OnChange(text)
+		}
+	}
+	in.OnFocusChange = func(focused bool) {
+		c.state.Focused = focused
+		c.refresh()
+	}
+
+	c.button = NewButton(c.AbstractElement, gs.MakeRect(area.Max.X-spinButtonWidth, area.Min.Y, area.Max.X, area.Max.Y), "▼")
+	c.button.noFocus = true
+	c.button.SetZIndex(0.2)
+	c.button.OnClick = func() {
+		if c.IsOpen() {
+			c.Close()
+		} else {
+			c.open(c.items)
+		}
+	}
+	c.refresh()
+	return c
+}
+
+// Accessible describes the combo box to assistive technologies
+func (c *ComboBox) Accessible() gs.Accessible {
+	a := gs.Accessible{Role: gs.RoleComboBox, Value: c.Text(), States: gs.StateEditable | expandState(c.IsOpen())}
+	if !c.Enabled() {
+		a.States |= gs.StateDisabled
+	}
+	return a
+}
+
+// Text returns the text of the combo box
+func (c *ComboBox) Text() string {
+	return c.input.Input().Text()
+}
+
+// SetText replaces the text without calling OnChange
+func (c *ComboBox) SetText(text string) {
+	c.input.Input().SetText(text)
+}
+
+// SetItems replaces the suggestions
+func (c *ComboBox) SetItems(items []string) {
+	c.Close()
+	c.items = items
+}
+
+// State returns the current visual state of the combo box
+func (c *ComboBox) State() State {
+	st := c.state
+	st.Disabled = !c.Enabled()
+	return st
+}
+
+// Enabled reports whether the combo box and its containers are enabled
+func (c *ComboBox) Enabled() bool {
+	return c.EnabledInTree()
+}
+
+// SetEnabled enables or disables the combo box
+func (c *ComboBox) SetEnabled(enabled bool) {
+	gs.SetEnabled(c.AbstractElement, enabled)
+}
+
+// OnEnabledChange locks the input and closes the list while the combo box
+// or a container is disabled
+func (c *ComboBox) OnEnabledChange(enabled bool) {
+	c.input.TextShape().Editable = enabled
+	if !enabled {
+		c.Close()
+	}
+	c.refresh()
+}
+
+// IsOpen reports whether the list of suggestions is shown
+func (c *ComboBox) IsOpen() bool {
+	return c.list != nil && c.list.IsOpen()
+}
+
+// Close hides the suggestions
+func (c *ComboBox) Close() {
+	if c.IsOpen() {
+		c.list.Close()
+	}
+}
+
+// Focus gives the keyboard focus to the input of the combo box
+func (c *ComboBox) Focus() {
+	if s := gs.SceneOf(c.AbstractElement); s != nil {
+		s.Focus(c.input)
+	}
+}
+
+func (c *ComboBox) open(items []string) {
+	s := gs.SceneOf(c.AbstractElement)
+	if s == nil {
+		return
+	}
+	if len(items) == 0 {
+		c.Close()
+		return
+	}
+	if c.list == nil {
+		c.list = newListPopup(s, c.AbstractElement, &c.Style, c.pick)
+	}
+	c.shown = items
+	c.list.setItems(items, c.W())
+	c.list.ShowNear(c.Area, gs.PlaceBelow)
+}
+
+// suggest shows the items starting with the typed text
+func (c *ComboBox) suggest(text string) {
+	var l []string
+	lower := strings.ToLower(text)
+	for _, it := range c.items {
+		if text != "" && strings.HasPrefix(strings.ToLower(it), lower) {
+			l = append(l, it)
+		}
+	}
+	c.open(l)
+}
+
+func (c *ComboBox) pick(i int) {
+	c.SetText(c.shown[i])
+	if c.OnChange != nil {
+		c.OnChange(c.shown[i])
+	}
+}
+
+func (c *ComboBox) refresh() {
+	c.frame.Paint = c.Style.Paint(c.State())
+	c.frame.FillColor = c.Style.Base
+	c.frame.RectShape().SetAllCornerRadiusTo(c.Style.CornerRadius)
+	c.input.FillColor = c.Style.TextColor(c.State())
+	gs.Invalidate(c.AbstractElement)
+}
+
+// ApplyStyle restyles the combo box from DefaultStyle with the rules of the stylesheet
+func (c *ComboBox) ApplyStyle(sheet *gs.Stylesheet) {
+	c.Style = StyleFrom(sheet, c.AbstractElement, DefaultStyle)
+	c.input.TextShape().Font = c.Style.Font
+	c.refresh()
+}
+
+// OnKeyEvent handles the keys that bubble up from the input
+func (c *ComboBox) OnKeyEvent(evt *gs.KeyEvent) bool {
+	if evt.Action == gs.EventRelease {
+		return false
+	}
+	switch {
+	case evt.Key == gs.KeyDown && !c.IsOpen():
+		c.open(c.items)
+	case evt.Key == gs.KeyDown:
+		c.list.move(1)
+	case evt.Key == gs.KeyUp && c.IsOpen():
+		c.list.move(-1)
+	default:
+		return false
+	}
+	return true
+}
+
+// OnMouseEvent focuses the input when the frame is clicked
+func (c *ComboBox) OnMouseEvent(evt *gs.MouseEvent) bool {
+	if evt.Action == gs.EventPress && !evt.Pos.In(c.button.Area) {
+		c.Focus()
+	}
+	return true
+}
+
+func (c *ComboBox) OnMouseEnter() {
+	c.state.Hovered = true
+	c.refresh()
+}
+
+func (c *ComboBox) OnMouseLeave() {
+	c.state.Hovered = false
+	c.refresh()
+}
@@ -0,0 +1,51 @@
+package widgets
+
+import (
+	"image"
+
+	gs "github.com/phaikawl/gosui"
+)
+
+// fill is the Handler of the pages and panes of containers,
+// it resizes their children to fill them
+type fill struct {
+	e *gs.AbstractElement
+}
+
+func newFill(parent *gs.AbstractElement, area image.Rectangle) *gs.AbstractElement {
+	e := gs.NewAbstractElement(parent, area)
+	e.Handler = fill{e}
+	return e
+}
+
+func (f fill) OnResize(area image.Rectangle) {
+	for _, c := range f.e.Children() {
+		gs.Resize(c, area)
+	}
+}
+
+// reparent detaches e from its parent, if it has one, and adds it to parent
+// resized to area
+func reparent(e gs.IElement, parent *gs.AbstractElement, area image.Rectangle) {
+	detach(e)
+	parent.AddChild(e)
+	gs.Resize(e, area)
+}
+
+// removeChildren detaches all the children of e
+func removeChildren(e *gs.AbstractElement) {
+	gs.Invalidate(e)
+	for len(e.Children()) > 0 {
+		e.RemoveChild(e.Children()[0])
+	}
+}
+
+// detach removes e from the tree, redrawing the area it covered
+func detach(e gs.IElement) {
+	p := e.BaseElement().Parent()
+	if p == nil {
+		return
+	}
+	gs.Invalidate(e)
+	p.RemoveChild(e)
+}
@@ -0,0 +1,736 @@
+package widgets
+
+import (
+	"image"
+	"sort"
+
+	gs "github.com/phaikawl/gosui"
+)
+
+var (
+	// GridRowHeight is the height of the rows of data grids
+	GridRowHeight = 24
+	// GridHeaderHeight is the height of the header row of data grids
+	GridHeaderHeight = 28
+
+	gridResizeMargin  = 4 // Distance from a column edge where dragging resizes the column
+	gridMinColWidth   = 20
+	gridScrollbarSize = 8
+	gridWheelRows     = 3 // Rows scrolled by a wheel step
+)
+
+// GridModel provides the data shown by a DataGrid
+type GridModel interface {
+	Rows() int
+	// Cell returns the text of a cell, col being the index of the column in the model
+	Cell(row, col int) string
+}
+
+// EditableGridModel is a GridModel whose cells can be edited inline
+type EditableGridModel interface {
+	GridModel
+	SetCell(row, col int, text string)
+}
+
+// GridColumn describes a column of a DataGrid
+type GridColumn struct {
+	Title    string
+	Width    int
+	MinWidth int
+	// Sortable columns sort the rows when their header is clicked
+	Sortable bool
+	// Editable columns can be edited if the model is an EditableGridModel
+	Editable bool
+	// Less compares model rows a and b, nil compares the texts of the cells
+	Less func(a, b int) bool
+	// Render creates the element showing the cell of a model row as a child of parent
+	// and returns it, the element is then resized to area. Nil shows the text of the cell.
+	Render func(parent *gs.AbstractElement, area image.Rectangle, row int) gs.IElement
+
+	index int
+}
+
+// Index returns the index of the column in the model
+func (c *GridColumn) Index() int {
+	return c.index
+}
+
+// gridEdit is the state of the inline editor
+type gridEdit struct {
+	row, col int // Display row and column
+	box      *gs.AbstractElement
+	input    *gs.ConcreteElement
+}
+
+// DataGrid shows the rows of a GridModel in columns under a fixed header.
+// It's virtualized: only the visible rows have elements, which are recreated
+// when the grid scrolls or its data changes.
+// Rows are referred to by their model index, except where noted.
+type DataGrid struct {
+	*gs.AbstractElement
+	Style     Style
+	model     GridModel
+	columns   []*GridColumn
+	header    *gs.AbstractElement
+	body      *gs.AbstractElement
+	order     []int // Model rows in display order
+	sortCol   *GridColumn
+	sortDesc  bool
+	scroll    int // First visible display row
+	selected  map[int]bool
+	anchor    int // Display row where range selections start
+	cursor    int // Display row of the keyboard cursor
+	cursorCol int
+	focused   bool
+	edit      *gridEdit
+
+	// Header dragging state
+	resizing   *GridColumn
+	resizeFrom int // Pointer position minus the column width
+	pressed    *GridColumn
+	moved      bool
+
+	// Multiselect allows selecting several rows with Ctrl and Shift
+	Multiselect bool
+	// OnSelect is called when the selection changes
+	OnSelect func(rows []int)
+}
+
+// NewDataGrid creates a DataGrid without columns showing model
+func NewDataGrid(parent *gs.AbstractElement, area image.Rectangle, model GridModel) *DataGrid {
+	g := &DataGrid{Style: DefaultStyle, model: model, selected: make(map[int]bool)}
+	g.AbstractElement = gs.NewAbstractElement(parent, area)
+	g.SetType("datagrid")
+	g.Handler = g
+	g.header = gs.NewAbstractElement(g.AbstractElement, g.headerArea())
+	gs.SetAccessible(g.header, gs.Accessible{Role: gs.RoleRow})
+	g.body = gs.NewAbstractElement(g.AbstractElement, g.bodyArea())
+	g.Refresh()
+	return g
+}
+
+func (g *DataGrid) headerArea() image.Rectangle {
+	return gs.MakeRectWH(g.X(), g.Y(), g.W(), GridHeaderHeight)
+}
+
+func (g *DataGrid) bodyArea() image.Rectangle {
+	return gs.MakeRect(g.X(), g.Y()+GridHeaderHeight, g.Area.Max.X, g.Area.Max.Y)
+}
+
+// Model returns the model shown by the grid
+func (g *DataGrid) Model() GridModel {
+	return g.model
+}
+
+// AddColumn appends a column showing the next column of the model
+func (g *DataGrid) AddColumn(title string, width int) *GridColumn {
+	c := &GridColumn{Title: title, Width: width, MinWidth: gridMinColWidth, index: len(g.columns)}
+	g.columns = append(g.columns, c)
+	g.build()
+	return c
+}
+
+// Columns returns the columns in display order
+func (g *DataGrid) Columns() []*GridColumn {
+	return g.columns
+}
+
+// MoveColumn moves the column at display index from to index to
+func (g *DataGrid) MoveColumn(from, to int) {
+	if from == to {
+		return
+	}
+	g.CommitEdit()
+	c := g.columns[from]
+	g.columns = append(g.columns[:from], g.columns[from+1:]...)
+	g.columns = append(g.columns, nil)
+	copy(g.columns[to+1:], g.columns[to:])
+	g.columns[to] = c
+	g.build()
+}
+
+// SetColumnWidth resizes a column, the width can't go below its MinWidth
+func (g *DataGrid) SetColumnWidth(c *GridColumn, w int) {
+	if w < c.MinWidth {
+		w = c.MinWidth
+	}
+	g.CommitEdit()
+	c.Width = w
+	g.build()
+}
+
+// Refresh reloads the rows from the model, keeping the sort order.
+// It must be called when rows are added or removed.
+func (g *DataGrid) Refresh() {
+	g.CommitEdit()
+	n := g.model.Rows()
+	g.order = make([]int, n)
+	for i := range g.order {
+		g.order[i] = i
+	}
+	for row := range g.selected {
+		if row >= n {
+			delete(g.selected, row)
+		}
+	}
+	if g.sortCol != nil {
+		g.sort()
+	}
+	g.clampCursor()
+	g.ScrollTo(g.scroll)
+}
+
+// SortBy sorts the rows by a column, nil restores the model order
+func (g *DataGrid) SortBy(c *GridColumn, descending bool) {
+	g.sortCol, g.sortDesc = c, descending
+	g.Refresh()
+}
+
+// SortColumn returns the column the rows are sorted by, nil if they aren't sorted
+func (g *DataGrid) SortColumn() (c *GridColumn, descending bool) {
+	return g.sortCol, g.sortDesc
+}
+
+// rowSorter sorts model rows with a comparison function
+type rowSorter struct {
+	rows []int
+	less func(a, b int) bool
+}
+
+func (s rowSorter) Len() int           { return len(s.rows) }
+func (s rowSorter) Swap(i, j int)      { s.rows[i], s.rows[j] = s.rows[j], s.rows[i] }
+func (s rowSorter) Less(i, j int) bool { return s.less(s.rows[i], s.rows[j]) }
+
+func (g *DataGrid) sort() {
+	c := g.sortCol
+	less := c.Less
+	if less == nil {
+		less = func(a, b int) bool {
+			return g.model.Cell(a, c.index) < g.model.Cell(b, c.index)
+		}
+	}
+	if g.sortDesc {
+		asc := less
+		less = func(a, b int) bool { return asc(b, a) }
+	}
+	sort.Stable(rowSorter{g.order, less})
+}
+
+// RowAt returns the model row displayed at a display index
+func (g *DataGrid) RowAt(i int) int {
+	return g.order[i]
+}
+
+// visibleRows returns how many rows fit in the body
+func (g *DataGrid) visibleRows() int {
+	return g.body.H() / GridRowHeight
+}
+
+// FirstVisible returns the display index of the first visible row
+func (g *DataGrid) FirstVisible() int {
+	return g.scroll
+}
+
+// ScrollTo scrolls so that the row at display index i is the first visible one,
+// as far as possible
+func (g *DataGrid) ScrollTo(i int) {
+	if max := len(g.order) - g.visibleRows(); i > max {
+		i = max
+	}
+	if i < 0 {
+		i = 0
+	}
+	if i != g.scroll && g.edit != nil {
+		g.CommitEdit()
+	}
+	g.scroll = i
+	g.render()
+}
+
+// EnsureVisible scrolls as little as possible to show the row at display index i
+func (g *DataGrid) EnsureVisible(i int) {
+	switch {
+	case i < g.scroll:
+		g.ScrollTo(i)
+	case i >= g.scroll+g.visibleRows():
+		g.ScrollTo(i - g.visibleRows() + 1)
+	}
+}
+
+// Selected returns the selected model rows in increasing order
+func (g *DataGrid) Selected() []int {
+	rows := make([]int, 0, len(g.selected))
+	for row := range g.selected {
+		rows = append(rows, row)
+	}
+	sort.Ints(rows)
+	return rows
+}
+
+// IsSelected reports whether a model row is selected
+func (g *DataGrid) IsSelected(row int) bool {
+	return g.selected[row]
+}
+
+// SetSelected replaces the selection, OnSelect isn't called
+func (g *DataGrid) SetSelected(rows ...int) {
+	g.selected = make(map[int]bool)
+	for _, row := range rows {
+		g.selected[row] = true
+	}
+	g.render()
+}
+
+// selectAt updates the selection for a click or a cursor move to display row i
+func (g *DataGrid) selectAt(i int, mod gs.Modifiers) {
+	row := g.order[i]
+	switch {
+	case g.Multiselect && mod.Shift:
+		g.selected = make(map[int]bool)
+		from, to := g.anchor, i
+		if from > to {
+			from, to = to, from
+		}
+		for d := from; d <= to; d++ {
+			g.selected[g.order[d]] = true
+		}
+	case g.Multiselect && mod.Control:
+		g.selected[row] = !g.selected[row]
+		if !g.selected[row] {
+			delete(g.selected, row)
+		}
+		g.anchor = i
+	default:
+		g.selected = map[int]bool{row: true}
+		g.anchor = i
+	}
+	g.cursor = i
+	g.EnsureVisible(i)
+	g.render()
+	if g.OnSelect != nil {
+		g.OnSelect(g.Selected())
+	}
+}
+
+func (g *DataGrid) clampCursor() {
+	if g.cursor >= len(g.order) {
+		g.cursor = len(g.order) - 1
+	}
+	if g.cursor < 0 {
+		g.cursor = 0
+	}
+	if g.cursorCol >= len(g.columns) {
+		g.cursorCol = len(g.columns) - 1
+	}
+	if g.cursorCol < 0 {
+		g.cursorCol = 0
+	}
+}
+
+// cellArea returns the area of a cell, i being a display row and col a display column
+func (g *DataGrid) cellArea(i, col int) image.Rectangle {
+	x := g.X()
+	for _, c := range g.columns[:col] {
+		x += c.Width
+	}
+	y := g.body.Y() + (i-g.scroll)*GridRowHeight
+	return gs.MakeRectWH(x, y, g.columns[col].Width, GridRowHeight)
+}
+
+// cellAt returns the display row and column at a point of the body, -1 if there's none
+func (g *DataGrid) cellAt(pt image.Point) (i, col int) {
+	i = g.scroll + (pt.Y-g.body.Y())/GridRowHeight
+	if i >= len(g.order) {
+		i = -1
+	}
+	return i, g.columnAt(pt.X)
+}
+
+// columnAt returns the display index of the column containing x, -1 if there's none
+func (g *DataGrid) columnAt(x int) int {
+	right := g.X()
+	for i, c := range g.columns {
+		right += c.Width
+		if x < right {
+			return i
+		}
+	}
+	return -1
+}
+
+// EditCell opens the inline editor on a cell, i being a display row and col a display column.
+// It does nothing if the column isn't editable or the model isn't an EditableGridModel.
+func (g *DataGrid) EditCell(i, col int) {
+	c := g.columns[col]
+	if _, ok := g.model.(EditableGridModel); !ok || !c.Editable {
+		return
+	}
+	g.CommitEdit()
+	g.EnsureVisible(i)
+	g.cursor, g.cursorCol = i, col
+	area := g.cellArea(i, col)
+	e := &gridEdit{row: i, col: col}
+	e.box = gs.NewAbstractElement(g.AbstractElement, area)
+	bg := gs.NewRectElement(e.box, area)
+	bg.Paint = paint(g.Style.Base, g.Style.Focus)
+	bg.SetZIndex(0.5)
+	font := g.Style.Font
+	baseline := area.Min.Y + (area.Dy()+font.Size*7/10)/2
+	e.input = gs.NewTextInputElement(e.box, area.Min.X+g.Style.Padding, baseline, font)
+	e.input.SetZIndex(0.6)
+	e.input.FillColor = g.Style.Text
+	in := e.input.Input()
+	in.SetText(g.model.Cell(g.order[i], c.index))
+	in.OnCommit = func(string) { g.CommitEdit() }
+	g.edit = e
+	g.render()
+	if s := gs.SceneOf(g.AbstractElement); s != nil {
+		s.Focus(e.input)
+	}
+}
+
+// Editing reports whether the inline editor is open
+func (g *DataGrid) Editing() bool {
+	return g.edit != nil
+}
+
+// CommitEdit stores the text of the inline editor in the model and closes it
+func (g *DataGrid) CommitEdit() {
+	if e := g.edit; e != nil {
+		g.closeEdit()
+		c := g.columns[e.col]
+		g.model.(EditableGridModel).SetCell(g.order[e.row], c.index, e.input.Input().Text())
+		g.render()
+	}
+}
+
+// CancelEdit closes the inline editor, leaving the cell unchanged
+func (g *DataGrid) CancelEdit() {
+	if g.edit != nil {
+		g.closeEdit()
+		g.render()
+	}
+}
+
+func (g *DataGrid) closeEdit() {
+	box := g.edit.box
+	g.edit = nil
+	s := gs.SceneOf(g.AbstractElement)
+	refocus := s != nil && s.Focused() != nil && gs.Within(s.Focused(), box)
+	detach(box)
+	if refocus {
+		s.Focus(g.AbstractElement)
+	}
+}
+
+// build recreates the header, then the rows
+func (g *DataGrid) build() {
+	removeChildren(g.header)
+	bar := gs.NewRectElement(g.header, g.header.Area)
+	bar.Paint = g.Style.Normal
+	x := g.X()
+	for _, c := range g.columns {
+		area := gs.MakeRectWH(x, g.Y(), c.Width, GridHeaderHeight)
+		bg := gs.NewRectElement(g.header, area)
+		bg.SetZIndex(0.1)
+		bg.Paint = g.Style.Paint(State{Pressed: c == g.pressed && !g.moved})
+		title := c.Title
+		if c == g.sortCol {
+			if g.sortDesc {
+				title += " ▼"
+			} else {
+				title += " ▲"
+			}
+		}
+		gs.SetAccessible(g.cellLabel(g.header, area, title, 0.2), gs.Accessible{Role: gs.RoleColumnHeader, Name: c.Title})
+		x += c.Width
+	}
+	gs.Invalidate(g.header)
+	g.render()
+}
+
+// cellLabel creates and returns a label showing text in area, elided if it's too long
+func (g *DataGrid) cellLabel(parent *gs.AbstractElement, area image.Rectangle, text string, z float32) *gs.ConcreteElement {
+	area.Min.X += g.Style.Padding
+	l := newLabel(parent, area, elide(text, g.Style.Font, area.Dx()-g.Style.Padding), g.Style.Font)
+	l.SetZIndex(z)
+	l.FillColor = g.Style.Text
+	return l
+}
+
+// elide shortens text with an ellipsis so that it fits in w
+func elide(text string, font gs.Font, w int) string {
+	if tw, _ := gs.MeasureText(text, font); tw <= w {
+		return text
+	}
+	r := []rune(text)
+	for len(r) > 0 {
+		r = r[:len(r)-1]
+		s := string(r) + "…"
+		if tw, _ := gs.MeasureText(s, font); tw <= w {
+			return s
+		}
+	}
+	return ""
+}
+
+// render recreates the elements of the visible rows
+func (g *DataGrid) render() {
+	removeChildren(g.body)
+	bg := gs.NewRectElement(g.body, g.body.Area)
+	bg.Paint = gs.NoStroke(g.Style.Base)
+	g.clampCursor()
+	n := g.visibleRows()
+	for i := g.scroll; i < g.scroll+n && i < len(g.order); i++ {
+		row := g.order[i]
+		y := g.body.Y() + (i-g.scroll)*GridRowHeight
+		// Each row has its own element, so that assistive technologies see rows of cells
+		rowEl := gs.NewAbstractElement(g.body, gs.MakeRectWH(g.X(), y, g.W(), GridRowHeight))
+		if g.selected[row] {
+			gs.SetAccessible(rowEl, gs.Accessible{Role: gs.RoleRow, States: gs.StateSelected})
+		} else {
+			gs.SetAccessible(rowEl, gs.Accessible{Role: gs.RoleRow})
+		}
+		rowBg := gs.NewRectElement(rowEl, rowEl.Area)
+		rowBg.SetZIndex(0.1)
+		switch {
+		case g.selected[row]:
+			rowBg.Paint = gs.NoStroke(g.Style.Pressed.FillColor)
+		case i%2 == 1:
+			rowBg.Paint = gs.NoStroke(rgb(248, 248, 248))
+		default:
+			rowBg.Paint = gs.NoStroke(g.Style.Base)
+		}
+		if g.focused && i == g.cursor {
+			rowBg.StrokeWidth, rowBg.StrokeColor = 1, g.Style.Focus
+		}
+		for col, c := range g.columns {
+			if g.edit != nil && g.edit.row == i && g.edit.col == col {
+				continue
+			}
+			area := g.cellArea(i, col)
+			text := g.model.Cell(row, c.index)
+			if c.Render != nil {
+				cell := c.Render(rowEl, area, row)
+				gs.Resize(cell, area)
+				if gs.AccessibleOf(cell).Role == gs.RoleNone {
+					gs.SetAccessible(cell, gs.Accessible{Role: gs.RoleCell, Name: text})
+				}
+			} else {
+				gs.SetAccessible(g.cellLabel(rowEl, area, text, 0.2), gs.Accessible{Role: gs.RoleCell, Name: text})
+			}
+		}
+	}
+	if total := len(g.order); total > n && n > 0 {
+		h := g.body.H()
+		track := gs.MakeRect(g.Area.Max.X-gridScrollbarSize, g.body.Y(), g.Area.Max.X, g.body.Area.Max.Y)
+		thumb := track
+		thumb.Min.Y += h * g.scroll / total
+		thumb.Max.Y = thumb.Min.Y + h*n/total
+		sb := gs.NewRectElement(g.body, thumb)
+		sb.SetZIndex(0.4)
+		sb.Paint = gs.NoStroke(g.Style.Normal.StrokeColor)
+		sb.RectShape().SetAllCornerRadiusTo(gridScrollbarSize / 2)
+	}
+	gs.Invalidate(g.body)
+}
+
+// ApplyStyle restyles the grid from DefaultStyle with the rules of the stylesheet
+func (g *DataGrid) ApplyStyle(sheet *gs.Stylesheet) {
+	g.Style = StyleFrom(sheet, g.AbstractElement, DefaultStyle)
+	g.render()
+}
+
+// OnResize lays out the header and the visible rows
+func (g *DataGrid) OnResize(area image.Rectangle) {
+	g.CommitEdit()
+	g.header.Area = g.headerArea()
+	g.body.Area = g.bodyArea()
+	g.build()
+	g.ScrollTo(g.scroll)
+}
+
+// Accessible describes the grid to assistive technologies
+func (g *DataGrid) Accessible() gs.Accessible {
+	return gs.Accessible{Role: gs.RoleGrid}
+}
+
+func (g *DataGrid) Focusable() bool {
+	return true
+}
+
+func (g *DataGrid) OnFocus() {
+	g.focused = true
+	g.render()
+}
+
+func (g *DataGrid) OnBlur() {
+	g.focused = false
+	g.render()
+}
+
+// columnEdge returns the column whose right edge is near x, nil if there's none
+func (g *DataGrid) columnEdge(x int) *GridColumn {
+	right := g.X()
+	for _, c := range g.columns {
+		right += c.Width
+		if x >= right-gridResizeMargin && x <= right+gridResizeMargin {
+			return c
+		}
+	}
+	return nil
+}
+
+func (g *DataGrid) OnMouseEvent(evt *gs.MouseEvent) bool {
+	switch {
+	case g.resizing != nil:
+		g.dragResize(evt)
+	case g.pressed != nil:
+		g.dragHeader(evt)
+	case evt.Action == gs.EventScroll:
+		g.ScrollTo(g.scroll - evt.Scroll.Y*gridWheelRows)
+	case evt.Action != gs.EventPress || evt.Button != gs.MouseButtonLeft:
+		return true
+	case evt.Pos.In(g.header.Area):
+		if c := g.columnEdge(evt.Pos.X); c != nil {
+			g.resizing = c
+			g.resizeFrom = evt.Pos.X - c.Width
+		} else if col := g.columnAt(evt.Pos.X); col >= 0 {
+			g.pressed, g.moved = g.columns[col], false
+			g.build()
+		} else {
+			return false
+		}
+		if s := gs.SceneOf(g.AbstractElement); s != nil {
+			s.GrabMouse(g.AbstractElement)
+		}
+	default:
+		if g.edit != nil && evt.Pos.In(g.edit.box.Area) {
+			return true
+		}
+		i, col := g.cellAt(evt.Pos)
+		if i < 0 || col < 0 {
+			return false
+		}
+		if i == g.cursor && col == g.cursorCol && len(g.selected) == 1 && g.selected[g.order[i]] &&
+			!evt.Mod.Control && !evt.Mod.Shift {
+			// Clicking the current cell again edits it
+			g.EditCell(i, col)
+			return false
+		}
+		g.CommitEdit()
+		g.cursorCol = col
+		g.selectAt(i, evt.Mod)
+	}
+	return false
+}
+
+func (g *DataGrid) dragResize(evt *gs.MouseEvent) {
+	switch evt.Action {
+	case gs.EventMove:
+		g.SetColumnWidth(g.resizing, evt.Pos.X-g.resizeFrom)
+	case gs.EventRelease:
+		g.resizing = nil
+	}
+}
+
+func (g *DataGrid) dragHeader(evt *gs.MouseEvent) {
+	switch evt.Action {
+	case gs.EventMove:
+		from := g.columnIndex(g.pressed)
+		if to := g.columnAt(evt.Pos.X); to >= 0 && to != from {
+			g.moved = true
+			g.MoveColumn(from, to)
+		}
+	case gs.EventRelease:
+		c := g.pressed
+		g.pressed = nil
+		if !g.moved && c.Sortable && evt.Pos.In(g.header.Area) {
+			g.SortBy(c, c == g.sortCol && !g.sortDesc)
+		}
+		g.build()
+	}
+}
+
+func (g *DataGrid) columnIndex(c *GridColumn) int {
+	for i, o := range g.columns {
+		if o == c {
+			return i
+		}
+	}
+	return -1
+}
+
+// OnKeyEvent moves the cursor with the arrows, Page Up/Down, Home and End,
+// edits the current cell with Enter or F2 and cancels editing with Escape
+func (g *DataGrid) OnKeyEvent(evt *gs.KeyEvent) bool {
+	if evt.Action == gs.EventRelease {
+		return false
+	}
+	if g.edit != nil {
+		switch evt.Key {
+		case gs.KeyEscape:
+			g.CancelEdit()
+			return true
+		case gs.KeyUp, gs.KeyDown, gs.KeyPageUp, gs.KeyPageDown:
+			g.CommitEdit()
+		default:
+			return false
+		}
+	}
+	if len(g.order) == 0 {
+		return false
+	}
+	i := g.cursor
+	page := g.visibleRows() - 1
+	if page < 1 {
+		page = 1
+	}
+	switch evt.Key {
+	case gs.KeyUp:
+		i--
+	case gs.KeyDown:
+		i++
+	case gs.KeyPageUp:
+		i -= page
+	case gs.KeyPageDown:
+		i += page
+	case gs.KeyHome:
+		i = 0
+	case gs.KeyEnd:
+		i = len(g.order) - 1
+	case gs.KeyLeft, gs.KeyRight:
+		if evt.Key == gs.KeyLeft {
+			g.cursorCol--
+		} else {
+			g.cursorCol++
+		}
+		g.clampCursor()
+		return true
+	case gs.KeyEnter, gs.KeyF2:
+		if len(g.columns) > 0 {
+			g.EditCell(g.cursor, g.cursorCol)
+		}
+		return true
+	case gs.KeyA:
+		if !evt.Mod.Control || !g.Multiselect {
+			return false
+		}
+		rows := make([]int, len(g.order))
+		copy(rows, g.order)
+		g.SetSelected(rows...)
+		if g.OnSelect != nil {
+			g.OnSelect(g.Selected())
+		}
+		return true
+	default:
+		return false
+	}
+	if i < 0 {
+		i = 0
+	} else if i >= len(g.order) {
+		i = len(g.order) - 1
+	}
+	g.selectAt(i, gs.Modifiers{Shift: evt.Mod.Shift})
+	return true
+}
@@ -0,0 +1,200 @@
+package widgets
+
+import (
+	"image"
+	"strings"
+
+	gs "github.com/phaikawl/gosui"
+)
+
+const (
+	dialogPadding      = 12
+	dialogTitleHeight  = 30
+	dialogButtonHeight = 28
+	dialogButtonWidth  = 80
+)
+
+// Dialog is a modal window inside the scene, with a title, a content area
+// and a row of buttons. Everything beneath it is blocked while it's open.
+// Enter activates the default button unless the focused widget uses it,
+// Escape activates the cancel button.
+//
+// Like any popup, its content is created relative to (0, 0), the Content
+// element covering the area between the title and the buttons.
+type Dialog struct {
+	*gs.Popup
+	Style   Style
+	Content *gs.AbstractElement
+	Buttons []*Button
+	// Index of the buttons activated by Enter and Escape, -1 for none
+	DefaultButton, CancelButton int
+
+	// OnResult is called with the index of the button that closed the dialog
+	OnResult func(button int)
+
+	frame, title *gs.ConcreteElement
+	result       chan int
+}
+
+// NewDialog creates a dialog of the given size with a button for each label,
+// aligned to the right. The first button is the default one and the last
+// one the cancel button.
+func NewDialog(s *gs.Scene, title string, size image.Point, buttons ...string) *Dialog {
+	d := &Dialog{Popup: s.NewPopup(), Style: DefaultStyle, CancelButton: len(buttons) - 1}
+	d.Modal = true
+	d.Handler = d
+	d.frame = gs.NewRectElement(d.AbstractElement, gs.MakeRectWH(0, 0, size.X, size.Y))
+	d.frame.Paint = paint(d.Style.Base, d.Style.Normal.StrokeColor)
+	d.frame.RectShape().SetAllCornerRadiusTo(d.Style.CornerRadius * 2)
+	titleFont := d.Style.Font
+	titleFont.Style = gs.Bold
+	d.title = newLabel(d.AbstractElement, gs.MakeRect(dialogPadding, 0, size.X-dialogPadding, dialogTitleHeight), title, titleFont)
+	d.title.FillColor = d.Style.Text
+	d.title.SetZIndex(0.1)
+	d.Content = gs.NewAbstractElement(d.AbstractElement,
+		gs.MakeRect(dialogPadding, dialogTitleHeight, size.X-dialogPadding, size.Y-dialogButtonHeight-2*dialogPadding))
+
+	x := size.X - dialogPadding - len(buttons)*(dialogButtonWidth+markGap) + markGap
+	y := size.Y - dialogPadding - dialogButtonHeight
+	for i, label := range buttons {
+		b := NewButton(d.AbstractElement, gs.MakeRectWH(x, y, dialogButtonWidth, dialogButtonHeight), label)
+		b.SetZIndex(0.1)
+		i := i
+		b.OnClick = func() { d.Finish(i) }
+		d.Buttons = append(d.Buttons, b)
+		x += dialogButtonWidth + markGap
+	}
+	return d
+}
+
+// Show opens the dialog at the center of the viewport and gives the focus
+// to its first focusable element
+func (d *Dialog) Show() {
+	vp := d.Scene().Viewport()
+	sz := d.Size()
+	center := vp.Min.Add(vp.Size().Div(2))
+	d.ShowAt(center.Sub(sz.Div(2)))
+	d.Scene().Focus(nil)
+	d.Scene().FocusNext(false)
+}
+
+// Result returns a channel that receives the index of the button that closed
+// the dialog, for use from other goroutines
+func (d *Dialog) Result() <-chan int {
+	if d.result == nil {
+		d.result = make(chan int, 1)
+	}
+	return d.result
+}
+
+// Finish closes the dialog as if the i-th button was clicked. It does nothing
+// if the dialog isn't open, so only the first of several finishes counts.
+func (d *Dialog) Finish(i int) {
+	if !d.IsOpen() {
+		return
+	}
+	d.Close()
+	if d.OnResult != nil {
+		d.OnResult(i)
+	}
+	if d.result != nil {
+		// A result of a previous opening that wasn't read is dropped
+		select {
+		case d.result <- i:
+		default:
+		}
+	}
+}
+
+// Accessible describes the dialog to assistive technologies
+func (d *Dialog) Accessible() gs.Accessible {
+	return gs.Accessible{Role: gs.RoleDialog, Name: d.title.TextShape().Content}
+}
+
+func (d *Dialog) OnKeyEvent(evt *gs.KeyEvent) bool {
+	if evt.Action == gs.EventRelease {
+		return false
+	}
+	switch {
+	case evt.Key == gs.KeyEnter && d.DefaultButton >= 0:
+		d.Buttons[d.DefaultButton].Click()
+	case evt.Key == gs.KeyEscape && d.CancelButton >= 0:
+		d.Buttons[d.CancelButton].Click()
+	default:
+		return false
+	}
+	return true
+}
+
+// messageSize returns the size of a dialog showing the lines of text
+func messageSize(lines []string, buttons int, extra int) image.Point {
+	w := buttons*(dialogButtonWidth+markGap) + dialogPadding
+	for _, l := range lines {
+		if lw, _ := gs.MeasureText(l, DefaultFont); lw > w {
+			w = lw
+		}
+	}
+	h := dialogTitleHeight + len(lines)*DefaultFont.Size*3/2 + extra + dialogButtonHeight + 3*dialogPadding
+	return image.Point{w + 2*dialogPadding, h}
+}
+
+func newMessageDialog(s *gs.Scene, title, text string, extra int, buttons ...string) *Dialog {
+	lines := strings.Split(text, "\n")
+	d := NewDialog(s, title, messageSize(lines, len(buttons), extra), buttons...)
+	lh := DefaultFont.Size * 3 / 2
+	for i, line := range lines {
+		l := newLabel(d.Content, gs.MakeRectWH(d.Content.X(), d.Content.Y()+i*lh, d.Content.W(), lh), line, d.Style.Font)
+		l.FillColor = d.Style.Text
+		l.SetZIndex(0.1)
+	}
+	return d
+}
+
+// MessageBox shows a message with an OK button.
+// onDone, which can be nil, is called when it's closed.
+func MessageBox(s *gs.Scene, title, text string, onDone func()) *Dialog {
+	d := newMessageDialog(s, title, text, 0, "OK")
+	d.OnResult = func(int) {
+		if onDone != nil {
+			onDone()
+		}
+	}
+	d.Show()
+	return d
+}
+
+// Confirm asks a question with OK and Cancel buttons,
+// onResult receives true if the user chose OK
+func Confirm(s *gs.Scene, title, text string, onResult func(ok bool)) *Dialog {
+	d := newMessageDialog(s, title, text, 0, "OK", "Cancel")
+	d.OnResult = func(i int) {
+		if onResult != nil {
+			onResult(i == 0)
+		}
+	}
+	d.Show()
+	return d
+}
+
+// Prompt asks the user for a line of text, initially set to initial.
+// onResult receives the text and whether the user chose OK.
+func Prompt(s *gs.Scene, title, text, initial string, onResult func(text string, ok bool)) *Dialog {
+	inputH := DefaultFont.Size * 2
+	d := newMessageDialog(s, title, text, inputH+dialogPadding, "OK", "Cancel")
+	c := d.Content
+	y := c.Area.Max.Y - inputH
+	field := gs.NewRectElement(c, gs.MakeRect(c.X(), y, c.Area.Max.X, y+inputH))
+	field.Paint = paint(d.Style.Base, d.Style.Normal.StrokeColor)
+	field.SetZIndex(0.1)
+	input := gs.NewTextInputElement(c, c.X()+4, y+(inputH+DefaultFont.Size*7/10)/2, d.Style.Font)
+	input.SetZIndex(0.2)
+	input.FillColor = d.Style.Text
+	input.Input().SetText(initial)
+	d.OnResult = func(i int) {
+		if onResult != nil {
+			onResult(input.Input().Text(), i == 0)
+		}
+	}
+	d.Show()
+	return d
+}
@@ -0,0 +1,158 @@
+package widgets
+
+import (
+	"image"
+
+	gs "github.com/phaikawl/gosui"
+)
+
+// Dropdown lets the user select one of a list of choices, shown in a popup
+type Dropdown struct {
+	control
+	frame, label, arrow *gs.ConcreteElement
+	items               []string
+	selected            int
+	list                *listPopup
+
+	// OnChange is called with the index of the item selected by the user
+	OnChange func(selected int)
+}
+
+// NewDropdown creates a Dropdown with no item selected
+func NewDropdown(parent *gs.AbstractElement, area image.Rectangle, items []string) *Dropdown {
+	d := new(Dropdown)
+	d.init(parent, area)
+	d.SetType("dropdown")
+	d.role = gs.RoleComboBox
+	d.describe = func(a *gs.Accessible) {
+		if d.selected >= 0 {
+			a.Value = d.items[d.selected]
+		}
+		a.States |= expandState(d.IsOpen())
+	}
+	d.items = items
+	d.selected = -1
+	d.frame = gs.NewRectElement(d.AbstractElement, area)
+	la := area.Inset(6)
+	la.Max.X -= spinButtonWidth
+	d.label = newLabel(d.AbstractElement, la, "", d.Style.Font)
+	d.label.SetZIndex(0.1)
+	aa := area
+	aa.Min.X = area.Max.X - spinButtonWidth
+	d.arrow = newLabel(d.AbstractElement, aa, "▼", d.Style.Font)
+	d.arrow.SetZIndex(0.1)
+	d.activate = d.Toggle
+	d.keyHook = d.onKey
+	d.update = d.updateLook
+	d.refresh()
+	return d
+}
+
+// Items returns the choices of the dropdown
+func (d *Dropdown) Items() []string {
+	return d.items
+}
+
+// SetItems replaces the choices, clearing the selection
+func (d *Dropdown) SetItems(items []string) {
+	d.Close()
+	d.items = items
+	d.SetSelected(-1)
+}
+
+// Selected returns the index of the selected item, -1 if there's none
+func (d *Dropdown) Selected() int {
+	return d.selected
+}
+
+// SetSelected selects an item without calling OnChange, -1 clears the selection
+func (d *Dropdown) SetSelected(i int) {
+	if i < -1 || i >= len(d.items) {
+		return
+	}
+	d.selected = i
+	d.refresh()
+}
+
+// IsOpen reports whether the list is shown
+func (d *Dropdown) IsOpen() bool {
+	return d.list != nil && d.list.IsOpen()
+}
+
+// Open shows the list of choices below the dropdown
+func (d *Dropdown) Open() {
+	s := gs.SceneOf(d.AbstractElement)
+	if s == nil || d.IsOpen() || !d.Enabled() {
+		return
+	}
+	if d.list == nil {
+		d.list = newListPopup(s, d.AbstractElement, &d.Style, d.choose)
+	}
+	d.list.setItems(d.items, d.W())
+	d.list.highlight(d.selected)
+	d.list.ShowNear(d.Area, gs.PlaceBelow)
+}
+
+// Close hides the list of choices
+func (d *Dropdown) Close() {
+	if d.IsOpen() {
+		d.list.Close()
+	}
+}
+
+// Toggle opens the list if it's closed, closes it otherwise
+func (d *Dropdown) Toggle() {
+	if d.IsOpen() {
+		d.Close()
+	} else {
+		d.Open()
+	}
+}
+
+func (d *Dropdown) choose(i int) {
+	if i == d.selected {
+		return
+	}
+	d.SetSelected(i)
+	if d.OnChange != nil {
+		d.OnChange(i)
+	}
+}
+
+func (d *Dropdown) onKey(evt *gs.KeyEvent) bool {
+	if evt.Action == gs.EventRelease || len(d.items) == 0 {
+		return false
+	}
+	open := d.IsOpen()
+	switch {
+	case evt.Key == gs.KeyDown && evt.Mod.Alt:
+		d.Open()
+	case evt.Key == gs.KeyDown && open:
+		d.list.move(1)
+	case evt.Key == gs.KeyUp && open:
+		d.list.move(-1)
+	case evt.Key == gs.KeyDown:
+		d.choose((d.selected + 1) % len(d.items))
+	case evt.Key == gs.KeyUp && d.selected > 0:
+		d.choose(d.selected - 1)
+	case (evt.Key == gs.KeyEnter || evt.Key == gs.KeySpace) && open:
+		d.list.pick(d.list.highlighted)
+	default:
+		return false
+	}
+	return true
+}
+
+func (d *Dropdown) updateLook() {
+	d.frame.Paint = d.Style.Paint(d.State())
+	d.frame.RectShape().SetAllCornerRadiusTo(d.Style.CornerRadius)
+	text := ""
+	if d.selected >= 0 {
+		text = d.items[d.selected]
+	}
+	d.label.TextShape().Content = text
+	for _, l := range []*gs.ConcreteElement{d.label, d.arrow} {
+		l.FillColor = d.Style.TextColor(d.State())
+		l.TextShape().Font = d.Style.Font
+	}
+}
@@ -0,0 +1,124 @@
+package widgets
+
+import (
+	"image"
+
+	gs "github.com/phaikawl/gosui"
+)
+
+// ListItemHeight is the height of the items of dropdown lists
+var ListItemHeight = 24
+
+type listItem struct {
+	bg, label *gs.ConcreteElement
+}
+
+// listPopup is the list of choices shown by dropdowns and combo boxes
+type listPopup struct {
+	*gs.Popup
+	style       *Style
+	frame       *gs.ConcreteElement
+	items       []listItem
+	width       int
+	highlighted int
+
+	onPick func(i int)
+}
+
+func newListPopup(s *gs.Scene, anchor gs.IElement, style *Style, onPick func(int)) *listPopup {
+	l := &listPopup{Popup: s.NewPopup(), style: style, onPick: onPick, highlighted: -1}
+	l.Anchor = anchor
+	l.Handler = l
+	return l
+}
+
+// setItems rebuilds the content of the list
+func (l *listPopup) setItems(texts []string, width int) {
+	removeChildren(l.AbstractElement)
+	l.items = l.items[:0]
+	l.highlighted = -1
+	pos := l.Position()
+	h := len(texts) * ListItemHeight
+	l.frame = gs.NewRectElement(l.AbstractElement, gs.MakeRectWH(pos.X, pos.Y, width, h+2))
+	l.frame.Paint = paint(l.style.Base, l.style.Normal.StrokeColor)
+	for i, text := range texts {
+		area := gs.MakeRectWH(pos.X+1, pos.Y+1+i*ListItemHeight, width-2, ListItemHeight)
+		it := listItem{
+			bg:    gs.NewRectElement(l.AbstractElement, area),
+			label: newLabel(l.AbstractElement, area.Inset(4), text, l.style.Font),
+		}
+		it.bg.SetZIndex(0.1)
+		it.label.SetZIndex(0.2)
+		l.items = append(l.items, it)
+	}
+	l.width = width
+	l.updateLook()
+}
+
+func (l *listPopup) highlight(i int) {
+	if i < -1 || i >= len(l.items) || i == l.highlighted {
+		return
+	}
+	l.highlighted = i
+	l.updateLook()
+	gs.Invalidate(l.AbstractElement)
+}
+
+// move moves the highlight by d items, wrapping around
+func (l *listPopup) move(d int) {
+	n := len(l.items)
+	if n == 0 {
+		return
+	}
+	i := l.highlighted + d
+	if l.highlighted < 0 && d < 0 {
+		i = n - 1
+	}
+	l.highlight((i%n + n) % n)
+}
+
+func (l *listPopup) pick(i int) {
+	if i < 0 || i >= len(l.items) {
+		return
+	}
+	l.Close()
+	l.onPick(i)
+}
+
+func (l *listPopup) updateLook() {
+	for i, it := range l.items {
+		it.bg.Paint = gs.NoStroke(l.style.Base)
+		it.label.FillColor = l.style.Text
+		a := gs.Accessible{Role: gs.RoleListItem}
+		if i == l.highlighted {
+			it.bg.FillColor = l.style.Accent
+			it.label.FillColor = rgb(255, 255, 255)
+			a.States = gs.StateSelected
+		}
+		gs.SetAccessible(it.label, a)
+	}
+}
+
+// Accessible describes the list to assistive technologies
+func (l *listPopup) Accessible() gs.Accessible {
+	return gs.Accessible{Role: gs.RoleList}
+}
+
+func (l *listPopup) itemAt(pt image.Point) int {
+	for i, it := range l.items {
+		if pt.In(it.bg.Area) {
+			return i
+		}
+	}
+	return -1
+}
+
+func (l *listPopup) OnMouseEvent(evt *gs.MouseEvent) bool {
+	switch evt.Action {
+	case gs.EventMove:
+		l.highlight(l.itemAt(evt.Pos))
+	case gs.EventRelease:
+		l.pick(l.itemAt(evt.Pos))
+	}
+	return false
+}
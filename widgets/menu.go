@@ -0,0 +1,426 @@
+package widgets
+
+import (
+	"image"
+
+	gs "github.com/phaikawl/gosui"
+)
+
+var (
+	// MenuItemHeight is the height of menu items other than separators
+	MenuItemHeight = 24
+	// MenuSeparatorHeight is the height of menu separators
+	MenuSeparatorHeight = 9
+)
+
+const (
+	menuCheckWidth = 22 // Room left of the item texts for check marks
+	menuArrowWidth = 20 // Room right of the item texts for submenu arrows
+	menuMinWidth   = 120
+)
+
+// MenuItem is an entry of a Menu
+type MenuItem struct {
+	Text string
+	// Shortcut is shown as the item's accelerator label.
+	// Menus of a MenuBar register it on the scene.
+	Shortcut  gs.Shortcut
+	Checkable bool
+	Checked   bool
+	Disabled  bool
+	Submenu   *Menu
+
+	// OnSelect is called when the item is activated, after Checked was toggled
+	OnSelect func()
+
+	separator bool
+}
+
+// Separator returns a new separator item
+func Separator() *MenuItem {
+	return &MenuItem{separator: true}
+}
+
+// IsSeparator reports whether the item is a separator
+func (it *MenuItem) IsSeparator() bool {
+	return it.separator
+}
+
+func (it *MenuItem) selectable() bool {
+	return !it.separator && !it.Disabled
+}
+
+func (it *MenuItem) height() int {
+	if it.separator {
+		return MenuSeparatorHeight
+	}
+	return MenuItemHeight
+}
+
+// activate toggles a checkable item then calls OnSelect
+func (it *MenuItem) activate() {
+	if !it.selectable() {
+		return
+	}
+	if it.Checkable {
+		it.Checked = !it.Checked
+	}
+	if it.OnSelect != nil {
+		it.OnSelect()
+	}
+}
+
+type menuRow struct {
+	item   *MenuItem
+	bg     *gs.ConcreteElement
+	labels []*gs.ConcreteElement
+}
+
+// Menu is a list of items shown in a popup, used as a context menu,
+// a submenu or a menu of a MenuBar.
+// The items are read each time the menu is opened, so they can be changed
+// while it's closed.
+type Menu struct {
+	Items []*MenuItem
+	Style Style
+
+	popup       *gs.Popup
+	rows        []menuRow
+	highlighted int
+	parent      *Menu // Set while open as a submenu
+	sub         *Menu // Open submenu
+	bar         *MenuBar
+
+	// OnClose is called when the menu has been closed
+	OnClose func()
+}
+
+// NewMenu creates a menu with the items
+func NewMenu(items ...*MenuItem) *Menu {
+	return &Menu{Items: items, Style: DefaultStyle, highlighted: -1}
+}
+
+// IsOpen reports whether the menu is shown
+func (m *Menu) IsOpen() bool {
+	return m.popup != nil && m.popup.IsOpen()
+}
+
+// Popup opens the menu with its top-left corner at pt, as a context menu
+func (m *Menu) Popup(s *gs.Scene, pt image.Point) {
+	m.build(s)
+	m.popup.Anchor = nil
+	m.popup.ShowAt(pt)
+	s.Focus(m.popup.AbstractElement)
+}
+
+// AttachTo makes the menu the context menu of the element, opened when it's right-clicked.
+// The element must be in a Scene.
+func (m *Menu) AttachTo(e gs.IElement) {
+	s := gs.SceneOf(e)
+	if s == nil {
+		return
+	}
+	s.SetContextMenu(e, func(pt image.Point) {
+		m.Close()
+		m.Popup(s, pt)
+	})
+}
+
+func (m *Menu) showNear(s *gs.Scene, anchor image.Rectangle, placement gs.Placement) {
+	m.build(s)
+	m.popup.ShowNear(anchor, placement)
+	s.Focus(m.popup.AbstractElement)
+}
+
+// Close hides the menu and its open submenus
+func (m *Menu) Close() {
+	if m.IsOpen() {
+		m.popup.Close()
+	}
+}
+
+// closeAll closes the menu and all the menus it was opened from
+func (m *Menu) closeAll() {
+	r := m
+	for r.parent != nil {
+		r = r.parent
+	}
+	r.Close()
+}
+
+func (m *Menu) onClosed() {
+	if m.sub != nil {
+		m.sub.Close()
+	}
+	if m.parent != nil {
+		m.parent.sub = nil
+		if m.parent.IsOpen() {
+			m.parent.updateLook()
+		}
+		m.parent = nil
+	}
+	if m.OnClose != nil {
+		m.OnClose()
+	}
+	if m.bar != nil {
+		m.bar.menuClosed(m)
+	}
+}
+
+func (m *Menu) width() int {
+	textW, shortcutW := 0, 0
+	for _, it := range m.Items {
+		w, _ := gs.MeasureText(it.Text, m.Style.Font)
+		if w > textW {
+			textW = w
+		}
+		if it.Shortcut != (gs.Shortcut{}) {
+			w, _ = gs.MeasureText(it.Shortcut.String(), m.Style.Font)
+			if w+markGap*4 > shortcutW {
+				shortcutW = w + markGap*4
+			}
+		}
+	}
+	w := menuCheckWidth + textW + shortcutW + menuArrowWidth
+	if w < menuMinWidth {
+		w = menuMinWidth
+	}
+	return w
+}
+
+// build creates the content of the popup from the items
+func (m *Menu) build(s *gs.Scene) {
+	if m.popup == nil {
+		m.popup = s.NewPopup()
+		m.popup.Handler = m
+		m.popup.OnClose = m.onClosed
+	}
+	p := m.popup
+	removeChildren(p.AbstractElement)
+	m.rows = m.rows[:0]
+	m.highlighted = -1
+	origin := p.Position()
+	w, h := m.width(), 2
+	for _, it := range m.Items {
+		h += it.height()
+	}
+	frame := gs.NewRectElement(p.AbstractElement, gs.MakeRectWH(origin.X, origin.Y, w, h))
+	frame.Paint = paint(m.Style.Base, m.Style.Normal.StrokeColor)
+	frame.RectShape().SetAllCornerRadiusTo(m.Style.CornerRadius)
+	y := origin.Y + 1
+	for _, it := range m.Items {
+		area := gs.MakeRectWH(origin.X+1, y, w-2, it.height())
+		y += it.height()
+		row := menuRow{item: it, bg: gs.NewRectElement(p.AbstractElement, area)}
+		row.bg.SetZIndex(0.1)
+		if it.separator {
+			cy := area.Min.Y + area.Dy()/2
+			line := gs.NewRectElement(p.AbstractElement, gs.MakeRect(area.Min.X+4, cy, area.Max.X-4, cy+1))
+			line.Paint = gs.NoStroke(m.Style.Normal.StrokeColor)
+			line.SetZIndex(0.2)
+			m.rows = append(m.rows, row)
+			continue
+		}
+		row.addText(area, menuCheckWidth, it.Text, m.Style.Font)
+		if it.Checkable && it.Checked {
+			row.addText(area, markGap, "✓", m.Style.Font)
+		}
+		if it.Shortcut != (gs.Shortcut{}) {
+			label := it.Shortcut.String()
+			sw, _ := gs.MeasureText(label, m.Style.Font)
+			row.addText(area, area.Dx()-menuArrowWidth-sw, label, m.Style.Font)
+		}
+		if it.Submenu != nil {
+			row.addText(area, area.Dx()-menuArrowWidth+markGap, "▶", m.Style.Font)
+		}
+		m.rows = append(m.rows, row)
+	}
+	m.updateLook()
+}
+
+func (r *menuRow) addText(area image.Rectangle, x int, text string, font gs.Font) {
+	area.Min.X += x
+	l := newLabel(r.bg.Parent(), area, text, font)
+	l.SetZIndex(0.2)
+	r.labels = append(r.labels, l)
+}
+
+// describe gives the role and states of the item to the label of its text,
+// and hides its other labels
+func (r *menuRow) describe(highlighted, open bool) {
+	it := r.item
+	a := gs.Accessible{Role: gs.RoleMenuItem}
+	if it.Shortcut != (gs.Shortcut{}) {
+		a.Description = it.Shortcut.String()
+	}
+	if it.Checkable {
+		a.States |= checkState(it.Checked)
+	}
+	if it.Submenu != nil {
+		a.States |= expandState(open)
+	}
+	if highlighted {
+		a.States |= gs.StateSelected
+	}
+	if it.Disabled {
+		a.States |= gs.StateDisabled
+	}
+	gs.SetAccessible(r.labels[0], a)
+	for _, l := range r.labels[1:] {
+		hideGlyph(l)
+	}
+}
+
+func (m *Menu) updateLook() {
+	for i, row := range m.rows {
+		row.bg.Paint = gs.NoStroke(m.Style.Base)
+		color := m.Style.Text
+		if row.item.Disabled {
+			color = m.Style.Disabled.StrokeColor
+		} else if i == m.highlighted {
+			row.bg.FillColor = m.Style.Accent
+			color = rgb(255, 255, 255)
+		}
+		for _, l := range row.labels {
+			l.FillColor = color
+		}
+		if len(row.labels) > 0 {
+			row.describe(i == m.highlighted, m.sub != nil && m.sub == row.item.Submenu)
+		}
+	}
+	gs.Invalidate(m.popup.AbstractElement)
+}
+
+func (m *Menu) rowAt(pt image.Point) int {
+	for i, row := range m.rows {
+		if pt.In(row.bg.Area) {
+			return i
+		}
+	}
+	return -1
+}
+
+func (m *Menu) highlight(i int) {
+	if i == m.highlighted {
+		return
+	}
+	m.highlighted = i
+	m.updateLook()
+}
+
+// move highlights the next selectable item in the direction d
+func (m *Menu) move(d int) {
+	n := len(m.rows)
+	i := m.highlighted
+	if i < 0 && d < 0 {
+		i = 0
+	}
+	for k := 0; k < n; k++ {
+		i = ((i+d)%n + n) % n
+		if m.rows[i].item.selectable() {
+			m.highlight(i)
+			return
+		}
+	}
+}
+
+// openSub opens the submenu of the row, if it has one
+func (m *Menu) openSub(i int, focus bool) {
+	it := m.rows[i].item
+	if m.sub != nil && m.sub != it.Submenu {
+		m.sub.Close()
+	}
+	if it.Submenu == nil || !it.selectable() || it.Submenu.IsOpen() {
+		return
+	}
+	s := gs.SceneOf(m.popup.AbstractElement)
+	prev := s.Focused()
+	m.sub = it.Submenu
+	m.sub.parent = m
+	m.sub.Style = m.Style
+	m.sub.showNear(s, m.rows[i].bg.Area, gs.PlaceRight)
+	m.updateLook()
+	if focus {
+		m.sub.move(1)
+	} else {
+		// Hovering opens the submenu but the focus stays on this menu
+		s.Focus(prev)
+	}
+}
+
+func (m *Menu) activate(i int) {
+	if i < 0 {
+		return
+	}
+	it := m.rows[i].item
+	if !it.selectable() {
+		return
+	}
+	if it.Submenu != nil {
+		m.openSub(i, true)
+		return
+	}
+	m.closeAll()
+	it.activate()
+}
+
+// Accessible describes the menu to assistive technologies
+func (m *Menu) Accessible() gs.Accessible {
+	return gs.Accessible{Role: gs.RoleMenu}
+}
+
+func (m *Menu) Focusable() bool {
+	return true
+}
+
+func (m *Menu) OnMouseEvent(evt *gs.MouseEvent) bool {
+	i := m.rowAt(evt.Pos)
+	switch evt.Action {
+	case gs.EventMove:
+		if i >= 0 && m.rows[i].item.selectable() {
+			m.highlight(i)
+			m.openSub(i, false)
+		}
+	case gs.EventRelease:
+		if i >= 0 && evt.Button == gs.MouseButtonLeft {
+			m.activate(i)
+		}
+	}
+	return false
+}
+
+func (m *Menu) OnKeyEvent(evt *gs.KeyEvent) bool {
+	if evt.Action == gs.EventRelease {
+		return true
+	}
+	switch evt.Key {
+	case gs.KeyDown:
+		m.move(1)
+	case gs.KeyUp:
+		m.move(-1)
+	case gs.KeyRight:
+		if m.highlighted >= 0 && m.rows[m.highlighted].item.Submenu != nil {
+			m.openSub(m.highlighted, true)
+		} else if r := m.rootMenu(); r.bar != nil {
+			r.bar.openNext(r, 1)
+		}
+	case gs.KeyLeft:
+		if m.parent != nil {
+			m.Close()
+		} else if m.bar != nil {
+			m.bar.openNext(m, -1)
+		}
+	case gs.KeyEnter, gs.KeySpace:
+		m.activate(m.highlighted)
+	default:
+		return false
+	}
+	return true
+}
+
+func (m *Menu) rootMenu() *Menu {
+	for m.parent != nil {
+		m = m.parent
+	}
+	return m
+}
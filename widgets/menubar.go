@@ -0,0 +1,189 @@
+package widgets
+
+import (
+	"image"
+
+	gs "github.com/phaikawl/gosui"
+)
+
+const menuTitlePadding = 10
+
+type menuTitle struct {
+	menu  *Menu
+	bg    *gs.ConcreteElement
+	label *gs.ConcreteElement
+}
+
+// MenuBar is a horizontal bar of menu titles, each opening a Menu below it.
+// The shortcuts of the items of its menus are registered as accelerators.
+type MenuBar struct {
+	*gs.AbstractElement
+	Style   Style
+	bg      *gs.ConcreteElement
+	titles  []menuTitle
+	open    int // Index of the open menu, -1 if none
+	hovered int
+}
+
+// NewMenuBar creates an empty MenuBar
+func NewMenuBar(parent *gs.AbstractElement, area image.Rectangle) *MenuBar {
+	b := &MenuBar{open: -1, hovered: -1, Style: DefaultStyle}
+	b.AbstractElement = gs.NewAbstractElement(parent, area)
+	b.SetType("menubar")
+	b.Handler = b
+	b.bg = gs.NewRectElement(b.AbstractElement, area)
+	b.refresh()
+	return b
+}
+
+// AddMenu appends a menu to the bar, with the given title
+func (b *MenuBar) AddMenu(title string, m *Menu) {
+	x := b.X()
+	if n := len(b.titles); n > 0 {
+		x = b.titles[n-1].bg.Area.Max.X
+	}
+	w, _ := gs.MeasureText(title, b.Style.Font)
+	area := gs.MakeRectWH(x, b.Y(), w+2*menuTitlePadding, b.H())
+	t := menuTitle{menu: m, bg: gs.NewRectElement(b.AbstractElement, area)}
+	t.bg.SetZIndex(0.1)
+	area.Min.X += menuTitlePadding
+	t.label = newLabel(b.AbstractElement, area, title, b.Style.Font)
+	t.label.SetZIndex(0.2)
+	b.titles = append(b.titles, t)
+	m.bar = b
+	if s := gs.SceneOf(b.AbstractElement); s != nil {
+		registerShortcuts(s, m)
+	}
+	b.refresh()
+}
+
+func registerShortcuts(s *gs.Scene, m *Menu) {
+	for _, it := range m.Items {
+		if it.Submenu != nil {
+			registerShortcuts(s, it.Submenu)
+		}
+		if it.Shortcut != (gs.Shortcut{}) && !it.separator {
+			s.AddAccelerator(it.Shortcut, it.activate)
+		}
+	}
+}
+
+// Menus returns the menus of the bar
+func (b *MenuBar) Menus() []*Menu {
+	menus := make([]*Menu, len(b.titles))
+	for i, t := range b.titles {
+		menus[i] = t.menu
+	}
+	return menus
+}
+
+// OpenMenu opens the i-th menu, closing the one that was open
+func (b *MenuBar) OpenMenu(i int) {
+	s := gs.SceneOf(b.AbstractElement)
+	if s == nil || i < 0 || i >= len(b.titles) || i == b.open {
+		return
+	}
+	b.CloseMenu()
+	b.open = i
+	m := b.titles[i].menu
+	m.Style = b.Style
+	m.showNear(s, b.titles[i].bg.Area, gs.PlaceBelow)
+	m.popup.Anchor = b.AbstractElement
+	b.refresh()
+}
+
+// CloseMenu closes the open menu
+func (b *MenuBar) CloseMenu() {
+	if b.open >= 0 {
+		b.titles[b.open].menu.Close()
+	}
+}
+
+// openNext opens the menu d positions from m, wrapping around
+func (b *MenuBar) openNext(m *Menu, d int) {
+	n := len(b.titles)
+	for i, t := range b.titles {
+		if t.menu == m {
+			b.OpenMenu(((i+d)%n + n) % n)
+			b.titles[b.open].menu.move(1)
+			return
+		}
+	}
+}
+
+func (b *MenuBar) menuClosed(m *Menu) {
+	if b.open >= 0 && b.titles[b.open].menu == m {
+		b.open = -1
+		b.refresh()
+	}
+}
+
+func (b *MenuBar) titleAt(pt image.Point) int {
+	for i, t := range b.titles {
+		if pt.In(t.bg.Area) {
+			return i
+		}
+	}
+	return -1
+}
+
+func (b *MenuBar) refresh() {
+	b.bg.Paint = gs.NoStroke(b.Style.Normal.FillColor)
+	for i, t := range b.titles {
+		t.bg.Paint = gs.NoStroke(b.Style.Normal.FillColor)
+		if i == b.open {
+			t.bg.Paint = gs.NoStroke(b.Style.Pressed.FillColor)
+		} else if i == b.hovered {
+			t.bg.Paint = gs.NoStroke(b.Style.Hovered.FillColor)
+		}
+		t.label.FillColor = b.Style.Text
+		gs.SetAccessible(t.label, gs.Accessible{Role: gs.RoleMenuItem, States: expandState(i == b.open)})
+	}
+	gs.Invalidate(b.AbstractElement)
+}
+
+// ApplyStyle restyles the bar and its menus from DefaultStyle with the rules of the stylesheet
+func (b *MenuBar) ApplyStyle(sheet *gs.Stylesheet) {
+	b.Style = StyleFrom(sheet, b.AbstractElement, DefaultStyle)
+	for _, t := range b.titles {
+		t.label.TextShape().Font = b.Style.Font
+	}
+	b.refresh()
+}
+
+// Accessible describes the bar to assistive technologies
+func (b *MenuBar) Accessible() gs.Accessible {
+	return gs.Accessible{Role: gs.RoleMenuBar}
+}
+
+func (b *MenuBar) OnMouseEvent(evt *gs.MouseEvent) bool {
+	i := b.titleAt(evt.Pos)
+	switch evt.Action {
+	case gs.EventMove:
+		if i != b.hovered {
+			b.hovered = i
+			b.refresh()
+		}
+		// Once a menu is open, hovering the other titles switches menus
+		if b.open >= 0 && i >= 0 {
+			b.OpenMenu(i)
+		}
+	case gs.EventPress:
+		if evt.Button != gs.MouseButtonLeft || i < 0 {
+			break
+		}
+		if i == b.open {
+			b.CloseMenu()
+		} else {
+			b.OpenMenu(i)
+		}
+	}
+	return false
+}
+
+func (b *MenuBar) OnMouseEnter() {}
+
+func (b *MenuBar) OnMouseLeave() {
+	b.hovered = -1
+	b.refresh()
+}
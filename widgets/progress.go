@@ -0,0 +1,121 @@
+package widgets
+
+import (
+	"fmt"
+	"image"
+
+	gs "github.com/phaikawl/gosui"
+)
+
+// PulseSteps is the number of Pulse calls it takes the indicator of an
+// indeterminate progress bar to cross the bar
+var PulseSteps = 50
+
+// ProgressBar shows the progress of a task.
+// A determinate bar is filled proportionally to its value,
+// an indeterminate one shows a chunk moving back and forth each time Pulse is called.
+type ProgressBar struct {
+	*gs.AbstractElement
+	Style         Style
+	track, fill   *gs.ConcreteElement
+	orient        Orientation
+	value         float64
+	indeterminate bool
+	pulse         int
+}
+
+// NewProgressBar creates a determinate progress bar with a value of 0
+func NewProgressBar(parent *gs.AbstractElement, area image.Rectangle, orient Orientation) *ProgressBar {
+	p := new(ProgressBar)
+	p.AbstractElement = gs.NewAbstractElement(parent, area)
+	p.SetType("progress")
+	p.Handler = p
+	p.Style = DefaultStyle
+	p.orient = orient
+	p.track = gs.NewRectElement(p.AbstractElement, area)
+	p.fill = gs.NewRectElement(p.AbstractElement, image.Rectangle{})
+	p.fill.SetZIndex(0.1)
+	p.refresh()
+	return p
+}
+
+// Value returns the progress, between 0 and 1
+func (p *ProgressBar) Value() float64 {
+	return p.value
+}
+
+// SetValue changes the progress, it's clamped between 0 and 1
+func (p *ProgressBar) SetValue(v float64) {
+	if v < 0 {
+		v = 0
+	} else if v > 1 {
+		v = 1
+	}
+	p.value = v
+	p.refresh()
+}
+
+// Indeterminate reports whether the bar is in indeterminate mode
+func (p *ProgressBar) Indeterminate() bool {
+	return p.indeterminate
+}
+
+// SetIndeterminate switches between determinate and indeterminate modes
+func (p *ProgressBar) SetIndeterminate(indeterminate bool) {
+	p.indeterminate = indeterminate
+	p.pulse = 0
+	p.refresh()
+}
+
+// Pulse moves the indicator of an indeterminate bar by one step,
+// it should be called periodically while the task runs
+func (p *ProgressBar) Pulse() {
+	p.pulse = (p.pulse + 1) % (2 * PulseSteps)
+	p.refresh()
+}
+
+func (p *ProgressBar) refresh() {
+	gs.Invalidate(p.AbstractElement)
+	a := p.Area
+	length := a.Dx()
+	if p.orient == Vertical {
+		length = a.Dy()
+	}
+	// Offset and size of the fill along the bar
+	off, size := 0, int(float64(length)*p.value+0.5)
+	if p.indeterminate {
+		size = length / 4
+		step := p.pulse
+		if step > PulseSteps {
+			step = 2*PulseSteps - step
+		}
+		off = (length - size) * step / PulseSteps
+	}
+	if p.orient == Horizontal {
+		p.fill.Area = gs.MakeRectWH(a.Min.X+off, a.Min.Y, size, a.Dy())
+	} else {
+		// Vertical bars fill from the bottom
+		p.fill.Area = gs.MakeRectWH(a.Min.X, a.Max.Y-off-size, a.Dx(), size)
+	}
+	p.track.Paint = p.Style.Normal
+	p.track.RectShape().SetAllCornerRadiusTo(p.Style.CornerRadius)
+	p.fill.Paint = gs.NoStroke(p.Style.Accent)
+	p.fill.RectShape().SetAllCornerRadiusTo(p.Style.CornerRadius)
+	gs.Invalidate(p.AbstractElement)
+}
+
+// Accessible describes the bar to assistive technologies, its value is a
+// percentage unless it's indeterminate
+func (p *ProgressBar) Accessible() gs.Accessible {
+	a := gs.Accessible{Role: gs.RoleProgressBar}
+	if !p.indeterminate {
+		a.Value = fmt.Sprintf("%.0f%%", p.value*100)
+	}
+	return a
+}
+
+// ApplyStyle restyles the bar from DefaultStyle with the rules of the stylesheet
+func (p *ProgressBar) ApplyStyle(sheet *gs.Stylesheet) {
+	p.Style = StyleFrom(sheet, p.AbstractElement, DefaultStyle)
+	p.refresh()
+}
@@ -0,0 +1,128 @@
+package widgets
+
+import (
+	"image"
+
+	gs "github.com/phaikawl/gosui"
+)
+
+// RadioItemHeight is the height of each option of a RadioGroup
+var RadioItemHeight = 24
+
+type radioItem struct {
+	circle, dot, label *gs.ConcreteElement
+}
+
+// RadioGroup is a vertical list of mutually exclusive options.
+// The group takes the focus as a whole, arrow keys change the selection.
+type RadioGroup struct {
+	control
+	items    []radioItem
+	selected int
+
+	// OnChange is called with the index of the option selected by the user
+	OnChange func(selected int)
+}
+
+// NewRadioGroup creates a RadioGroup with no option selected.
+// The options are laid out from the top of area, one every RadioItemHeight pixels.
+func NewRadioGroup(parent *gs.AbstractElement, area image.Rectangle, options []string) *RadioGroup {
+	g := new(RadioGroup)
+	g.init(parent, area)
+	g.SetType("radiogroup")
+	g.role = gs.RoleGroup
+	g.selected = -1
+	for i, text := range options {
+		ia := gs.MakeRectWH(area.Min.X, area.Min.Y+i*RadioItemHeight, area.Dx(), RadioItemHeight)
+		it := radioItem{
+			circle: gs.NewRectElement(g.AbstractElement, boxArea(ia)),
+			dot:    gs.NewRectElement(g.AbstractElement, boxArea(ia).Inset(4)),
+			label:  newLabel(g.AbstractElement, labelArea(ia), text, g.Style.Font),
+		}
+		it.circle.RectShape().SetAllCornerRadiusTo(markSize / 2)
+		it.dot.RectShape().SetAllCornerRadiusTo(markSize/2 - 4)
+		it.dot.SetZIndex(0.1)
+		g.items = append(g.items, it)
+	}
+	g.activate = func() {
+		if i := (g.pressPos.Y - g.Y()) / RadioItemHeight; i >= 0 && i < len(g.items) {
+			g.choose(i)
+		}
+	}
+	g.keyHook = g.onKey
+	g.update = g.updateLook
+	g.refresh()
+	return g
+}
+
+// Selected returns the index of the selected option, -1 if there's none
+func (g *RadioGroup) Selected() int {
+	return g.selected
+}
+
+// SetSelected selects an option without calling OnChange, -1 clears the selection
+func (g *RadioGroup) SetSelected(i int) {
+	if i < -1 || i >= len(g.items) {
+		return
+	}
+	g.selected = i
+	g.refresh()
+}
+
+func (g *RadioGroup) choose(i int) {
+	if i == g.selected {
+		return
+	}
+	g.SetSelected(i)
+	if g.OnChange != nil {
+		g.OnChange(i)
+	}
+}
+
+func (g *RadioGroup) onKey(evt *gs.KeyEvent) bool {
+	if len(g.items) == 0 {
+		return false
+	}
+	press := evt.Action != gs.EventRelease
+	switch evt.Key {
+	case gs.KeyUp, gs.KeyLeft:
+		if press {
+			g.choose((g.selected - 1 + len(g.items)) % len(g.items))
+		}
+	case gs.KeyDown, gs.KeyRight:
+		if press {
+			g.choose((g.selected + 1) % len(g.items))
+		}
+	case gs.KeySpace, gs.KeyEnter:
+		if press && g.selected < 0 {
+			g.choose(0)
+		}
+	default:
+		return false
+	}
+	return true
+}
+
+func (g *RadioGroup) updateLook() {
+	current := g.selected
+	if current < 0 {
+		current = 0
+	}
+	for i, it := range g.items {
+		st := g.State()
+		// Only the current option shows the focus
+		st.Focused = st.Focused && i == current
+		st.Pressed = st.Pressed && (g.pressPos.Y-g.Y())/RadioItemHeight == i
+		it.circle.Paint = g.Style.Paint(st)
+		it.dot.Paint = gs.NoStroke(gs.Color{})
+		if i == g.selected {
+			it.dot.FillColor = g.Style.Accent
+			if !g.Enabled() {
+				it.dot.FillColor = g.Style.Disabled.StrokeColor
+			}
+		}
+		it.label.FillColor = g.Style.TextColor(g.State())
+		it.label.TextShape().Font = g.Style.Font
+		gs.SetAccessible(it.label, gs.Accessible{Role: gs.RoleRadioButton, States: checkState(i == g.selected)})
+	}
+}
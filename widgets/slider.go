@@ -0,0 +1,194 @@
+package widgets
+
+import (
+	"image"
+	"math"
+	"strconv"
+
+	gs "github.com/phaikawl/gosui"
+)
+
+// Orientation of range widgets
+type Orientation int
+
+const (
+	Horizontal Orientation = iota
+	Vertical
+)
+
+const (
+	thumbSize     = 16
+	sliderTrack   = 4
+	pageStepScale = 10 // PageUp/PageDown move the slider by this many steps
+)
+
+// Slider lets the user pick a value in a range by dragging a thumb along a track
+type Slider struct {
+	control
+	track, fill, thumb *gs.ConcreteElement
+	orient             Orientation
+	min, max, value    float64
+	step               float64
+
+	// OnChange is called with the new value when the user moves the slider
+	OnChange func(value float64)
+}
+
+// NewSlider creates a slider for values between min and max, starting at min.
+// Vertical sliders have their minimum at the bottom.
+func NewSlider(parent *gs.AbstractElement, area image.Rectangle, orient Orientation, min, max float64) *Slider {
+	s := new(Slider)
+	s.init(parent, area)
+	s.SetType("slider")
+	s.role = gs.RoleSlider
+	s.describe = func(a *gs.Accessible) { a.Value = strconv.FormatFloat(s.value, 'g', -1, 64) }
+	s.orient = orient
+	s.min, s.max, s.value = min, max, min
+	s.step = (max - min) / 100
+	s.track = gs.NewRectElement(s.AbstractElement, image.Rectangle{})
+	s.fill = gs.NewRectElement(s.AbstractElement, image.Rectangle{})
+	s.fill.SetZIndex(0.1)
+	s.thumb = gs.NewRectElement(s.AbstractElement, image.Rectangle{})
+	s.thumb.SetZIndex(0.2)
+	s.thumb.RectShape().SetAllCornerRadiusTo(thumbSize / 2)
+	s.activate = func() {}
+	s.drag = func(pt image.Point) {
+		s.change(s.valueAt(pt))
+	}
+	s.keyHook = s.onKey
+	s.update = s.updateLook
+	s.refresh()
+	return s
+}
+
+// Value returns the current value of the slider
+func (s *Slider) Value() float64 {
+	return s.value
+}
+
+// SetValue changes the value without calling OnChange.
+// It's clamped to the range and snapped to the step.
+func (s *Slider) SetValue(v float64) {
+	s.value = s.snap(v)
+	s.refresh()
+}
+
+// Range returns the minimum and maximum values
+func (s *Slider) Range() (min, max float64) {
+	return s.min, s.max
+}
+
+// SetRange changes the minimum and maximum values
+func (s *Slider) SetRange(min, max float64) {
+	s.min, s.max = min, max
+	s.SetValue(s.value)
+}
+
+// Step returns the increment used by keyboard and the value snapping
+func (s *Slider) Step() float64 {
+	return s.step
+}
+
+// SetStep changes the increment, 0 means no snapping
+// and keyboard increments of a hundredth of the range.
+func (s *Slider) SetStep(step float64) {
+	s.step = step
+	s.SetValue(s.value)
+}
+
+func (s *Slider) keyStep() float64 {
+	if s.step > 0 {
+		return s.step
+	}
+	return (s.max - s.min) / 100
+}
+
+func (s *Slider) snap(v float64) float64 {
+	if s.step > 0 {
+		v = s.min + math.Floor((v-s.min)/s.step+0.5)*s.step
+	}
+	return math.Max(s.min, math.Min(s.max, v))
+}
+
+func (s *Slider) change(v float64) {
+	v = s.snap(v)
+	if v == s.value {
+		return
+	}
+	s.SetValue(v)
+	if s.OnChange != nil {
+		s.OnChange(v)
+	}
+}
+
+func (s *Slider) onKey(evt *gs.KeyEvent) bool {
+	if evt.Action == gs.EventRelease {
+		return false
+	}
+	switch evt.Key {
+	case gs.KeyRight, gs.KeyUp:
+		s.change(s.value + s.keyStep())
+	case gs.KeyLeft, gs.KeyDown:
+		s.change(s.value - s.keyStep())
+	case gs.KeyPageUp:
+		s.change(s.value + s.keyStep()*pageStepScale)
+	case gs.KeyPageDown:
+		s.change(s.value - s.keyStep()*pageStepScale)
+	case gs.KeyHome:
+		s.change(s.min)
+	case gs.KeyEnd:
+		s.change(s.max)
+	default:
+		return false
+	}
+	return true
+}
+
+// travel returns the start and the length of the segment the thumb center moves along
+func (s *Slider) travel() (start, length int) {
+	if s.orient == Horizontal {
+		return s.X() + thumbSize/2, s.W() - thumbSize
+	}
+	return s.Area.Max.Y - thumbSize/2, -(s.H() - thumbSize)
+}
+
+func (s *Slider) valueAt(pt image.Point) float64 {
+	start, length := s.travel()
+	if length == 0 {
+		return s.min
+	}
+	p := pt.X
+	if s.orient == Vertical {
+		p = pt.Y
+	}
+	return s.min + float64(p-start)/float64(length)*(s.max-s.min)
+}
+
+func (s *Slider) updateLook() {
+	ratio := 0.0
+	if s.max > s.min {
+		ratio = (s.value - s.min) / (s.max - s.min)
+	}
+	start, length := s.travel()
+	pos := start + int(math.Floor(float64(length)*ratio+0.5))
+	a := s.Area
+	if s.orient == Horizontal {
+		cy := a.Min.Y + a.Dy()/2
+		s.track.Area = gs.MakeRect(a.Min.X, cy-sliderTrack/2, a.Max.X, cy+sliderTrack/2)
+		s.fill.Area = gs.MakeRect(a.Min.X, cy-sliderTrack/2, pos, cy+sliderTrack/2)
+		s.thumb.Area = gs.MakeRectWH(pos-thumbSize/2, cy-thumbSize/2, thumbSize, thumbSize)
+	} else {
+		cx := a.Min.X + a.Dx()/2
+		s.track.Area = gs.MakeRect(cx-sliderTrack/2, a.Min.Y, cx+sliderTrack/2, a.Max.Y)
+		s.fill.Area = gs.MakeRect(cx-sliderTrack/2, pos, cx+sliderTrack/2, a.Max.Y)
+		s.thumb.Area = gs.MakeRectWH(cx-thumbSize/2, pos-thumbSize/2, thumbSize, thumbSize)
+	}
+	s.track.Paint = gs.NoStroke(s.Style.Normal.StrokeColor)
+	s.track.RectShape().SetAllCornerRadiusTo(sliderTrack / 2)
+	s.fill.Paint = gs.NoStroke(s.Style.Accent)
+	s.fill.RectShape().SetAllCornerRadiusTo(sliderTrack / 2)
+	s.thumb.Paint = s.Style.Paint(s.State())
+	if !s.Enabled() {
+		s.fill.FillColor = s.Style.Disabled.StrokeColor
+	}
+}
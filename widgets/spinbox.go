@@ -0,0 +1,220 @@
+package widgets
+
+import (
+	"image"
+	"math"
+	"strconv"
+
+	gs "github.com/phaikawl/gosui"
+)
+
+const spinButtonWidth = 20
+
+// SpinBox is a numeric text input with buttons to increase and decrease its value.
+// Typed values are validated when Enter is pressed or the input loses the focus:
+// invalid text is reverted and out of range numbers are clamped.
+type SpinBox struct {
+	*gs.AbstractElement
+	Style    Style
+	frame    *gs.ConcreteElement
+	input    *gs.ConcreteElement
+	up, down *Button
+	state    State
+	min, max float64
+	value    float64
+	step     float64
+	decimals int
+
+	// OnChange is called with the new value when the user changes it
+	OnChange func(value float64)
+}
+
+// NewSpinBox creates a spin box for values between min and max, changed by step,
+// showing the given number of decimals. Its value starts at min.
+func NewSpinBox(parent *gs.AbstractElement, area image.Rectangle, min, max, step float64, decimals int) *SpinBox {
+	s := new(SpinBox)
+	s.AbstractElement = gs.NewAbstractElement(parent, area)
+	s.SetType("spinbox")
+	s.Handler = s
+	s.Style = DefaultStyle
+	s.min, s.max, s.step, s.decimals = min, max, step, decimals
+	s.value = min
+
+	s.frame = gs.NewRectElement(s.AbstractElement, area)
+	font := s.Style.Font
+	baseline := area.Min.Y + (area.Dy()+font.Size*7/10)/2
+	s.input = gs.NewTextInputElement(s.AbstractElement, area.Min.X+4, baseline, font)
+	s.input.SetZIndex(0.1)
+	in := s.input.Input()
+	in.Filter = s.accepts
+	in.OnCommit = func(string) { s.commit() }
+	in.OnFocusChange = func(focused bool) {
+		s.state.Focused = focused
+		s.refresh()
+	}
+
+	bx := area.Max.X - spinButtonWidth
+	half := area.Dy() / 2
+	s.up = NewButton(s.AbstractElement, gs.MakeRect(bx, area.Min.Y, area.Max.X, area.Min.Y+half), "+")
+	s.down = NewButton(s.AbstractElement, gs.MakeRect(bx, area.Min.Y+half, area.Max.X, area.Max.Y), "-")
+	for _, b := range []*Button{s.up, s.down} {
+		b.noFocus = true
+		b.SetZIndex(0.2)
+		b.Style.CornerRadius = 0
+		b.refresh()
+	}
+	s.up.OnClick = func() { s.change(s.value + s.step) }
+	s.down.OnClick = func() { s.change(s.value - s.step) }
+	s.SetValue(min)
+	return s
+}
+
+// Accessible describes the spin box to assistive technologies
+func (s *SpinBox) Accessible() gs.Accessible {
+	a := gs.Accessible{Role: gs.RoleSpinButton, Value: s.Text(), States: gs.StateEditable}
+	if !s.Enabled() {
+		a.States |= gs.StateDisabled
+	}
+	return a
+}
+
+// Value returns the current value
+func (s *SpinBox) Value() float64 {
+	return s.value
+}
+
+// SetValue changes the value without calling OnChange, it's clamped to the range
+func (s *SpinBox) SetValue(v float64) {
+	s.value = s.clamp(v)
+	s.input.Input().SetText(s.format(s.value))
+	s.refresh()
+}
+
+// Text returns the text currently in the input, which may not be validated yet
+func (s *SpinBox) Text() string {
+	return s.input.Input().Text()
+}
+
+// State returns the current visual state of the spin box
+func (s *SpinBox) State() State {
+	st := s.state
+	st.Disabled = !s.Enabled()
+	return st
+}
+
+// Enabled reports whether the spin box and its containers are enabled
+func (s *SpinBox) Enabled() bool {
+	return s.EnabledInTree()
+}
+
+// SetEnabled enables or disables the spin box
+func (s *SpinBox) SetEnabled(enabled bool) {
+	gs.SetEnabled(s.AbstractElement, enabled)
+}
+
+// OnEnabledChange locks the input while the spin box or a container is disabled
+func (s *SpinBox) OnEnabledChange(enabled bool) {
+	s.input.TextShape().Editable = enabled
+	s.refresh()
+}
+
+// Focus gives the keyboard focus to the input of the spin box
+func (s *SpinBox) Focus() {
+	if sc := gs.SceneOf(s.AbstractElement); sc != nil && s.Enabled() {
+		sc.Focus(s.input)
+	}
+}
+
+func (s *SpinBox) accepts(r rune) bool {
+	return (r >= '0' && r <= '9') || (r == '-' && s.min < 0) || (r == '.' && s.decimals > 0)
+}
+
+func (s *SpinBox) format(v float64) string {
+	return strconv.FormatFloat(v, 'f', s.decimals, 64)
+}
+
+func (s *SpinBox) clamp(v float64) float64 {
+	return math.Max(s.min, math.Min(s.max, v))
+}
+
+func (s *SpinBox) change(v float64) {
+	v = s.clamp(v)
+	old := s.value
+	s.SetValue(v)
+	if v != old && s.OnChange != nil {
+		s.OnChange(v)
+	}
+}
+
+// commit validates the typed text
+func (s *SpinBox) commit() {
+	v, err := strconv.ParseFloat(s.Text(), 64)
+	if err != nil || math.IsNaN(v) {
+		v = s.value
+	}
+	s.change(v)
+}
+
+func (s *SpinBox) refresh() {
+	st := s.State()
+	s.frame.Paint = s.Style.Paint(st)
+	s.frame.FillColor = s.Style.Base
+	s.frame.RectShape().SetAllCornerRadiusTo(s.Style.CornerRadius)
+	s.input.FillColor = s.Style.TextColor(st)
+	gs.Invalidate(s.AbstractElement)
+}
+
+// ApplyStyle restyles the spin box and its buttons from DefaultStyle with the rules of the stylesheet
+func (s *SpinBox) ApplyStyle(sheet *gs.Stylesheet) {
+	s.Style = StyleFrom(sheet, s.AbstractElement, DefaultStyle)
+	s.input.TextShape().Font = s.Style.Font
+	for _, b := range []*Button{s.up, s.down} {
+		b.Style = StyleFrom(sheet, b.AbstractElement, DefaultStyle)
+		b.Style.CornerRadius = 0
+		b.refresh()
+	}
+	s.refresh()
+}
+
+// OnKeyEvent handles the keys that bubble up from the input
+func (s *SpinBox) OnKeyEvent(evt *gs.KeyEvent) bool {
+	if !s.Enabled() || evt.Action == gs.EventRelease {
+		return false
+	}
+	switch evt.Key {
+	case gs.KeyUp:
+		s.commit()
+		s.change(s.value + s.step)
+	case gs.KeyDown:
+		s.commit()
+		s.change(s.value - s.step)
+	case gs.KeyPageUp:
+		s.commit()
+		s.change(s.value + s.step*pageStepScale)
+	case gs.KeyPageDown:
+		s.commit()
+		s.change(s.value - s.step*pageStepScale)
+	default:
+		return false
+	}
+	return true
+}
+
+// OnMouseEvent focuses the input when the frame is clicked
+func (s *SpinBox) OnMouseEvent(evt *gs.MouseEvent) bool {
+	if evt.Action == gs.EventPress && s.Enabled() &&
+		!evt.Pos.In(s.up.Area) && !evt.Pos.In(s.down.Area) {
+		s.Focus()
+	}
+	return true
+}
+
+func (s *SpinBox) OnMouseEnter() {
+	s.state.Hovered = true
+	s.refresh()
+}
+
+func (s *SpinBox) OnMouseLeave() {
+	s.state.Hovered = false
+	s.refresh()
+}
@@ -0,0 +1,191 @@
+package widgets
+
+import (
+	"image"
+	"strconv"
+
+	gs "github.com/phaikawl/gosui"
+)
+
+// DividerSize is the thickness of the divider of split panes
+var DividerSize = 6
+
+// SplitPane divides its area in two panes separated by a divider
+// the user can drag. The children of each pane are resized to fill it.
+type SplitPane struct {
+	*gs.AbstractElement
+	Style         Style
+	orient        Orientation
+	first, second *gs.AbstractElement
+	divider       *gs.ConcreteElement
+	pos           int    // Size of the first pane
+	limits        [4]int // Min and max sizes of the first then the second pane, 0 max means none
+	dragOffset    int
+	dragging      bool
+	hovered       bool
+
+	// OnChange is called with the size of the first pane when the user moves the divider
+	OnChange func(pos int)
+}
+
+// NewSplitPane creates a SplitPane with the divider in the middle.
+// Horizontal panes are side by side, vertical ones one above the other.
+func NewSplitPane(parent *gs.AbstractElement, area image.Rectangle, orient Orientation) *SplitPane {
+	p := &SplitPane{orient: orient, Style: DefaultStyle}
+	p.AbstractElement = gs.NewAbstractElement(parent, area)
+	p.SetType("splitpane")
+	p.Handler = p
+	p.first = newFill(p.AbstractElement, image.Rectangle{})
+	p.second = newFill(p.AbstractElement, image.Rectangle{})
+	p.divider = gs.NewRectElement(p.AbstractElement, image.Rectangle{})
+	p.divider.SetZIndex(0.5)
+	p.pos = (p.length() - DividerSize) / 2
+	p.layout()
+	return p
+}
+
+// First returns the left or top pane
+func (p *SplitPane) First() *gs.AbstractElement {
+	return p.first
+}
+
+// Second returns the right or bottom pane
+func (p *SplitPane) Second() *gs.AbstractElement {
+	return p.second
+}
+
+// SetFirstLimits sets the minimum and maximum sizes of the first pane, 0 max means no maximum
+func (p *SplitPane) SetFirstLimits(min, max int) {
+	p.limits[0], p.limits[1] = min, max
+	p.SetPosition(p.pos)
+}
+
+// SetSecondLimits sets the minimum and maximum sizes of the second pane, 0 max means no maximum
+func (p *SplitPane) SetSecondLimits(min, max int) {
+	p.limits[2], p.limits[3] = min, max
+	p.SetPosition(p.pos)
+}
+
+// Position returns the size of the first pane
+func (p *SplitPane) Position() int {
+	return p.pos
+}
+
+// SetPosition sets the size of the first pane, it's clamped so that both panes
+// respect their limits. The first pane's limits win if they can't both be met.
+func (p *SplitPane) SetPosition(pos int) {
+	p.pos = p.clamp(pos)
+	p.layout()
+}
+
+func (p *SplitPane) length() int {
+	if p.orient == Horizontal {
+		return p.W()
+	}
+	return p.H()
+}
+
+func (p *SplitPane) clamp(pos int) int {
+	avail := p.length() - DividerSize
+	// The second pane's limits, as limits of the first one
+	if max := p.limits[3]; max > 0 && pos < avail-max {
+		pos = avail - max
+	}
+	if pos > avail-p.limits[2] {
+		pos = avail - p.limits[2]
+	}
+	if max := p.limits[1]; max > 0 && pos > max {
+		pos = max
+	}
+	if pos < p.limits[0] {
+		pos = p.limits[0]
+	}
+	return pos
+}
+
+func (p *SplitPane) layout() {
+	a := p.Area
+	var first, div, second image.Rectangle
+	if p.orient == Horizontal {
+		first = gs.MakeRect(a.Min.X, a.Min.Y, a.Min.X+p.pos, a.Max.Y)
+		div = gs.MakeRect(first.Max.X, a.Min.Y, first.Max.X+DividerSize, a.Max.Y)
+		second = gs.MakeRect(div.Max.X, a.Min.Y, a.Max.X, a.Max.Y)
+	} else {
+		first = gs.MakeRect(a.Min.X, a.Min.Y, a.Max.X, a.Min.Y+p.pos)
+		div = gs.MakeRect(a.Min.X, first.Max.Y, a.Max.X, first.Max.Y+DividerSize)
+		second = gs.MakeRect(a.Min.X, div.Max.Y, a.Max.X, a.Max.Y)
+	}
+	gs.Resize(p.first, first)
+	gs.Resize(p.second, second)
+	gs.Invalidate(p.divider)
+	p.divider.Area = div
+	p.divider.Paint = gs.NoStroke(p.Style.Normal.StrokeColor)
+	if p.hovered || p.dragging {
+		p.divider.FillColor = p.Style.Accent
+	}
+	gs.SetAccessible(p.divider, gs.Accessible{Role: gs.RoleSplitter, Value: strconv.Itoa(p.pos)})
+	gs.Invalidate(p.divider)
+}
+
+// ApplyStyle restyles the divider from DefaultStyle with the rules of the
+// stylesheet, then the content of the panes
+func (p *SplitPane) ApplyStyle(sheet *gs.Stylesheet) {
+	p.Style = StyleFrom(sheet, p.AbstractElement, DefaultStyle)
+	p.layout()
+	restyleContent(p.AbstractElement, p.first, p.second)
+}
+
+// OnResize keeps the size of the first pane, as far as the limits allow
+func (p *SplitPane) OnResize(area image.Rectangle) {
+	p.SetPosition(p.pos)
+}
+
+func (p *SplitPane) coord(pt image.Point) int {
+	if p.orient == Horizontal {
+		return pt.X - p.X()
+	}
+	return pt.Y - p.Y()
+}
+
+func (p *SplitPane) OnMouseEvent(evt *gs.MouseEvent) bool {
+	onDivider := evt.Pos.In(p.divider.Area)
+	switch {
+	case evt.Action == gs.EventPress && onDivider && evt.Button == gs.MouseButtonLeft:
+		p.dragging = true
+		p.dragOffset = p.coord(evt.Pos) - p.pos
+		if s := gs.SceneOf(p.AbstractElement); s != nil {
+			s.GrabMouse(p.AbstractElement)
+		}
+		p.layout()
+		return false
+	case evt.Action == gs.EventMove && p.dragging:
+		old := p.pos
+		p.SetPosition(p.coord(evt.Pos) - p.dragOffset)
+		if p.pos != old && p.OnChange != nil {
+			p.OnChange(p.pos)
+		}
+		return false
+	case evt.Action == gs.EventRelease && p.dragging:
+		p.dragging = false
+		p.layout()
+		return false
+	case evt.Action == gs.EventMove && onDivider != p.hovered:
+		p.hovered = onDivider
+		p.layout()
+	}
+	return true
+}
+
+// Accessible describes the panes to assistive technologies
+func (p *SplitPane) Accessible() gs.Accessible {
+	return gs.Accessible{Role: gs.RoleGroup}
+}
+
+func (p *SplitPane) OnMouseEnter() {}
+
+func (p *SplitPane) OnMouseLeave() {
+	if p.hovered {
+		p.hovered = false
+		p.layout()
+	}
+}
@@ -0,0 +1,318 @@
+package widgets
+
+import (
+	"image"
+
+	gs "github.com/phaikawl/gosui"
+)
+
+var (
+	// TabBarHeight is the height of the tab strip of tab views
+	TabBarHeight = 28
+
+	tabPadding    = 12
+	tabCloseWidth = 18
+)
+
+type tab struct {
+	title   string
+	content gs.IElement
+	area    image.Rectangle // Area of the header
+}
+
+// TabView shows one page at a time under a strip of tabs.
+// Tabs can be reordered by dragging them, and closed if the view is Closable.
+// Only the content of the current page is attached to the tree.
+type TabView struct {
+	*gs.AbstractElement
+	Style    Style
+	strip    *gs.AbstractElement
+	page     *gs.AbstractElement
+	tabs     []tab
+	current  int
+	dragging int // Index of the tab being dragged, -1 if none
+
+	// Closable shows a close button on each tab
+	Closable bool
+	// OnChange is called when the current page changes, with -1 when the last tab is closed
+	OnChange func(i int)
+	// OnClose is called when the user clicks the close button of a tab,
+	// the tab is closed only if it returns true. A nil OnClose closes every tab.
+	OnClose func(i int) bool
+}
+
+// NewTabView creates an empty TabView as a child of parent
+func NewTabView(parent *gs.AbstractElement, area image.Rectangle) *TabView {
+	t := &TabView{Style: DefaultStyle, current: -1, dragging: -1}
+	t.AbstractElement = gs.NewAbstractElement(parent, area)
+	t.SetType("tabview")
+	t.Handler = t
+	t.strip = gs.NewAbstractElement(t.AbstractElement, t.stripArea())
+	gs.SetAccessible(t.strip, gs.Accessible{Role: gs.RoleTabList})
+	t.page = newFill(t.AbstractElement, t.pageArea())
+	return t
+}
+
+func (t *TabView) stripArea() image.Rectangle {
+	return gs.MakeRectWH(t.X(), t.Y(), t.W(), TabBarHeight)
+}
+
+func (t *TabView) pageArea() image.Rectangle {
+	return gs.MakeRect(t.X(), t.Y()+TabBarHeight, t.Area.Max.X, t.Area.Max.Y)
+}
+
+// Len returns the number of tabs
+func (t *TabView) Len() int {
+	return len(t.tabs)
+}
+
+// Title returns the title of tab i
+func (t *TabView) Title(i int) string {
+	return t.tabs[i].title
+}
+
+// SetTitle changes the title of tab i
+func (t *TabView) SetTitle(i int, title string) {
+	t.tabs[i].title = title
+	t.build()
+}
+
+// Content returns the content of tab i
+func (t *TabView) Content(i int) gs.IElement {
+	return t.tabs[i].content
+}
+
+// AddTab adds a tab showing content, which is detached from its parent, and returns its index.
+// The first tab added becomes the current one.
+func (t *TabView) AddTab(title string, content gs.IElement) int {
+	return t.InsertTab(len(t.tabs), title, content)
+}
+
+// InsertTab inserts a tab at index i
+func (t *TabView) InsertTab(i int, title string, content gs.IElement) int {
+	detach(content)
+	t.tabs = append(t.tabs, tab{})
+	copy(t.tabs[i+1:], t.tabs[i:])
+	t.tabs[i] = tab{title: title, content: content}
+	switch {
+	case t.current < 0:
+		t.show(i)
+	case i <= t.current:
+		t.current++
+	}
+	t.build()
+	return i
+}
+
+// RemoveTab removes tab i and returns its content, detached from the tree
+func (t *TabView) RemoveTab(i int) gs.IElement {
+	content := t.tabs[i].content
+	t.tabs = append(t.tabs[:i], t.tabs[i+1:]...)
+	switch {
+	case i < t.current:
+		t.current--
+	case i == t.current:
+		detach(content)
+		t.current = -1
+		if len(t.tabs) > 0 {
+			if i == len(t.tabs) {
+				i--
+			}
+			t.show(i)
+		}
+		if t.OnChange != nil {
+			t.OnChange(t.current)
+		}
+	}
+	t.build()
+	return content
+}
+
+// CloseTab asks OnClose whether tab i can be closed, and removes it if so
+func (t *TabView) CloseTab(i int) bool {
+	if t.OnClose != nil && !t.OnClose(i) {
+		return false
+	}
+	t.RemoveTab(i)
+	return true
+}
+
+// MoveTab moves tab from to index to, keeping the same page current
+func (t *TabView) MoveTab(from, to int) {
+	if from == to {
+		return
+	}
+	tb := t.tabs[from]
+	t.tabs = append(t.tabs[:from], t.tabs[from+1:]...)
+	t.tabs = append(t.tabs, tab{})
+	copy(t.tabs[to+1:], t.tabs[to:])
+	t.tabs[to] = tb
+	switch {
+	case t.current == from:
+		t.current = to
+	case from < t.current && t.current <= to:
+		t.current--
+	case to <= t.current && t.current < from:
+		t.current++
+	}
+	t.build()
+}
+
+// Current returns the index of the current page, -1 if there's no tab
+func (t *TabView) Current() int {
+	return t.current
+}
+
+// SetCurrent shows page i
+func (t *TabView) SetCurrent(i int) {
+	if i == t.current || i < 0 || i >= len(t.tabs) {
+		return
+	}
+	if t.current >= 0 {
+		detach(t.tabs[t.current].content)
+	}
+	t.show(i)
+	t.build()
+	if t.OnChange != nil {
+		t.OnChange(i)
+	}
+}
+
+func (t *TabView) show(i int) {
+	t.current = i
+	reparent(t.tabs[i].content, t.page, t.page.Area)
+	restyleContent(t.AbstractElement, t.tabs[i].content)
+}
+
+// build recreates the tab headers
+func (t *TabView) build() {
+	removeChildren(t.strip)
+	bar := gs.NewRectElement(t.strip, t.strip.Area)
+	bar.Paint = gs.NoStroke(t.Style.Normal.StrokeColor)
+	x := t.X()
+	for i := range t.tabs {
+		tb := &t.tabs[i]
+		w, _ := gs.MeasureText(tb.title, t.Style.Font)
+		w += 2 * tabPadding
+		if t.Closable {
+			w += tabCloseWidth
+		}
+		tb.area = gs.MakeRectWH(x, t.Y(), w, TabBarHeight)
+		x += w
+
+		bg := gs.NewRectElement(t.strip, tb.area)
+		bg.SetZIndex(0.1)
+		bg.Paint = t.Style.Paint(State{Hovered: i == t.dragging})
+		if i == t.current {
+			bg.FillColor = t.Style.Base
+		}
+		la := gs.MakeRect(tb.area.Min.X+tabPadding, tb.area.Min.Y, tb.area.Max.X, tb.area.Max.Y)
+		label := newLabel(t.strip, la, tb.title, t.Style.Font)
+		label.SetZIndex(0.2)
+		label.FillColor = t.Style.Text
+		a := gs.Accessible{Role: gs.RoleTab}
+		if i == t.current {
+			a.States = gs.StateSelected
+		}
+		gs.SetAccessible(label, a)
+		if t.Closable {
+			cl := newLabel(t.strip, t.closeArea(i), "×", t.Style.Font)
+			cl.SetZIndex(0.2)
+			cl.FillColor = t.Style.Text
+			hideGlyph(cl)
+		}
+	}
+	page := gs.Accessible{Role: gs.RoleTabPanel}
+	if t.current >= 0 {
+		page.Name = t.tabs[t.current].title
+	}
+	gs.SetAccessible(t.page, page)
+	gs.Invalidate(t.strip)
+}
+
+// ApplyStyle restyles the tab strip from DefaultStyle with the rules of the
+// stylesheet, then the content of the current tab
+func (t *TabView) ApplyStyle(sheet *gs.Stylesheet) {
+	t.Style = StyleFrom(sheet, t.AbstractElement, DefaultStyle)
+	t.build()
+	if t.current >= 0 {
+		restyleContent(t.AbstractElement, t.tabs[t.current].content)
+	}
+}
+
+func (t *TabView) closeArea(i int) image.Rectangle {
+	a := t.tabs[i].area
+	return gs.MakeRect(a.Max.X-tabPadding/2-tabCloseWidth, a.Min.Y, a.Max.X-tabPadding/2, a.Max.Y)
+}
+
+// tabAt returns the index of the tab whose header contains x, -1 if none
+func (t *TabView) tabAt(x int) int {
+	for i, tb := range t.tabs {
+		if x >= tb.area.Min.X && x < tb.area.Max.X {
+			return i
+		}
+	}
+	return -1
+}
+
+// OnResize lays out the strip and the current page
+func (t *TabView) OnResize(area image.Rectangle) {
+	gs.Resize(t.strip, t.stripArea())
+	gs.Resize(t.page, t.pageArea())
+	t.build()
+}
+
+func (t *TabView) OnMouseEvent(evt *gs.MouseEvent) bool {
+	if t.dragging >= 0 {
+		switch evt.Action {
+		case gs.EventMove:
+			if i := t.tabAt(evt.Pos.X); i >= 0 && i != t.dragging {
+				t.MoveTab(t.dragging, i)
+				t.dragging = i
+			}
+		case gs.EventRelease:
+			t.dragging = -1
+			t.build()
+		}
+		return false
+	}
+	if !evt.Pos.In(t.strip.Area) {
+		return true
+	}
+	i := t.tabAt(evt.Pos.X)
+	if i < 0 || evt.Action != gs.EventPress || evt.Button != gs.MouseButtonLeft {
+		return false
+	}
+	if t.Closable && evt.Pos.In(t.closeArea(i)) {
+		t.CloseTab(i)
+		return false
+	}
+	t.SetCurrent(i)
+	t.dragging = i
+	t.build()
+	if s := gs.SceneOf(t.AbstractElement); s != nil {
+		s.GrabMouse(t.AbstractElement)
+	}
+	return false
+}
+
+// OnKeyEvent switches pages with Ctrl+Tab, Ctrl+Shift+Tab, Ctrl+PageDown and Ctrl+PageUp
+// while the focus is in the view
+func (t *TabView) OnKeyEvent(evt *gs.KeyEvent) bool {
+	if !evt.Mod.Control || len(t.tabs) == 0 {
+		return false
+	}
+	d := 0
+	switch {
+	case evt.Key == gs.KeyTab && evt.Mod.Shift, evt.Key == gs.KeyPageUp:
+		d = -1
+	case evt.Key == gs.KeyTab, evt.Key == gs.KeyPageDown:
+		d = 1
+	default:
+		return false
+	}
+	if evt.Action != gs.EventRelease {
+		t.SetCurrent((t.current + d + len(t.tabs)) % len(t.tabs))
+	}
+	return true
+}
@@ -0,0 +1,151 @@
+package widgets
+
+import (
+	"fmt"
+	"strings"
+
+	gs "github.com/phaikawl/gosui"
+)
+
+// widgetTypes are the type names of the widgets, as matched by stylesheets
+var widgetTypes = []string{
+	"button", "checkbox", "toggle", "radiogroup", "slider", "progress", "dropdown", "combobox",
+	"spinbox", "datagrid", "treeview", "tabview", "splitpane", "menubar", "collapsible",
+}
+
+// themeColors are the colors a theme is made of, written like in stylesheets
+type themeColors struct {
+	normal, hovered, pressed, disabled [2]string // Fill and stroke
+	accent, focus, text, base          string
+}
+
+func newTheme(c themeColors) *gs.Stylesheet {
+	s := gs.NewStylesheet()
+	add := func(sels, decls string) {
+		if err := s.Add(sels, decls); err != nil {
+			panic(err)
+		}
+	}
+	state := func(pseudo string) string {
+		sels := make([]string, len(widgetTypes))
+		for i, t := range widgetTypes {
+			sels[i] = t + pseudo
+		}
+		return strings.Join(sels, ", ")
+	}
+	paint := func(p [2]string) string {
+		return fmt.Sprintf("fill: %s; stroke: %s", p[0], p[1])
+	}
+	add("root", "font: Arial 14; color: "+c.text)
+	add(state(""), paint(c.normal)+"; stroke-width: 1; corners: 3; padding: 6; accent: "+c.accent+"; background: "+c.base)
+	add(state(":hover"), paint(c.hovered))
+	add(state(":pressed"), paint(c.pressed))
+	add(state(":focused"), "stroke: "+c.focus)
+	add(state(":disabled"), paint(c.disabled))
+	return s
+}
+
+var (
+	// LightTheme is a stylesheet giving widgets the look of DefaultStyle
+	LightTheme = newTheme(themeColors{
+		normal:   [2]string{"#f0f0f0", "#a0a0a0"},
+		hovered:  [2]string{"#e5f1fb", "#0078d7"},
+		pressed:  [2]string{"#cce4f7", "#005499"},
+		disabled: [2]string{"#e6e6e6", "#c8c8c8"},
+		accent:   "#0078d7",
+		focus:    "#005ab4",
+		text:     "#000000",
+		base:     "#ffffff",
+	})
+
+	// DarkTheme is a stylesheet giving widgets light text on dark backgrounds
+	DarkTheme = newTheme(themeColors{
+		normal:   [2]string{"#2d2d30", "#5a5a5e"},
+		hovered:  [2]string{"#3e3e42", "#3c9be6"},
+		pressed:  [2]string{"#1f3b53", "#5fb2f2"},
+		disabled: [2]string{"#27272a", "#48484c"},
+		accent:   "#3c9be6",
+		focus:    "#64aff0",
+		text:     "#e6e6e6",
+		base:     "#1e1e1e",
+	})
+)
+
+// overridePaint returns p with the paint properties set in props
+func overridePaint(p gs.Paint, props gs.StyleProps) gs.Paint {
+	if props.Has(gs.PropFill) {
+		p.FillColor = props.FillColor
+	}
+	if props.Has(gs.PropStroke) {
+		p.StrokeColor = props.StrokeColor
+	}
+	if props.Has(gs.PropStrokeWidth) {
+		p.StrokeWidth = props.StrokeWidth
+	}
+	return p
+}
+
+// StyleFrom returns the style of a widget for a stylesheet: the properties set
+// by the rules matching the widget in each state override those of base.
+// The focus color is the stroke of the focused state, when it differs from the normal one.
+func StyleFrom(sheet *gs.Stylesheet, e gs.IElement, base Style) Style {
+	if sheet == nil {
+		return base
+	}
+	s := base
+	normal := sheet.Compute(e, gs.StyleState{})
+	s.Normal = overridePaint(base.Normal, normal)
+	s.Hovered = overridePaint(base.Hovered, sheet.Compute(e, gs.StyleState{Hovered: true}))
+	s.Pressed = overridePaint(base.Pressed, sheet.Compute(e, gs.StyleState{Hovered: true, Pressed: true}))
+	s.Disabled = overridePaint(base.Disabled, sheet.Compute(e, gs.StyleState{Disabled: true}))
+	focused := sheet.Compute(e, gs.StyleState{Focused: true})
+	if focused.Has(gs.PropStroke) && (!normal.Has(gs.PropStroke) || focused.StrokeColor != normal.StrokeColor) {
+		s.Focus = focused.StrokeColor
+	}
+	if normal.Has(gs.PropColor) {
+		s.Text = normal.Color
+	}
+	if normal.Has(gs.PropAccent) {
+		s.Accent = normal.Accent
+	}
+	if normal.Has(gs.PropBackground) {
+		s.Base = normal.Background
+	}
+	if normal.Has(gs.PropFontFamily) {
+		s.Font.Family = normal.Font.Family
+	}
+	if normal.Has(gs.PropFontSize) {
+		s.Font.Size = normal.Font.Size
+	}
+	if normal.Has(gs.PropFontStyle) {
+		s.Font.Style = normal.Font.Style
+	}
+	if normal.Has(gs.PropCorners) {
+		s.CornerRadius = normal.Corners.TopLeft
+	}
+	if normal.Has(gs.PropPadding) {
+		s.Padding = normal.Padding
+	}
+	return s
+}
+
+// ApplyStyle restyles the widget from DefaultStyle with the rules of the stylesheet
+func (c *control) ApplyStyle(sheet *gs.Stylesheet) {
+	c.Style = StyleFrom(sheet, c.AbstractElement, DefaultStyle)
+	c.refresh()
+}
+
+// restyleContent applies the stylesheet of the scene to the content of a
+// container widget, which the restyling of the scene doesn't reach.
+// Detached content is restyled by the container when it's attached again.
+func restyleContent(owner gs.IElement, content ...gs.IElement) {
+	s := gs.SceneOf(owner)
+	if s == nil || s.Stylesheet() == nil {
+		return
+	}
+	for _, e := range content {
+		if e.BaseElement().Parent() != nil {
+			s.Restyle(e)
+		}
+	}
+}
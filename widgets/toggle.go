@@ -0,0 +1,80 @@
+package widgets
+
+import (
+	"image"
+
+	gs "github.com/phaikawl/gosui"
+)
+
+const (
+	trackWidth  = 36
+	trackHeight = 18
+)
+
+// Toggle is an on/off switch followed by a label
+type Toggle struct {
+	control
+	track, thumb *gs.ConcreteElement
+	label        *gs.ConcreteElement
+	on           bool
+
+	// OnChange is called with the new value when the user flips the switch
+	OnChange func(on bool)
+}
+
+// NewToggle creates a new Toggle in the off position as a child of parent
+func NewToggle(parent *gs.AbstractElement, area image.Rectangle, text string) *Toggle {
+	t := new(Toggle)
+	t.init(parent, area)
+	t.SetType("toggle")
+	t.role = gs.RoleSwitch
+	t.describe = func(a *gs.Accessible) { a.States |= checkState(t.on) }
+	y := area.Min.Y + (area.Dy()-trackHeight)/2
+	t.track = gs.NewRectElement(t.AbstractElement, gs.MakeRectWH(area.Min.X, y, trackWidth, trackHeight))
+	t.track.RectShape().SetAllCornerRadiusTo(trackHeight / 2)
+	t.thumb = gs.NewRectElement(t.AbstractElement, image.Rectangle{})
+	t.thumb.RectShape().SetAllCornerRadiusTo(trackHeight/2 - 3)
+	t.thumb.SetZIndex(0.1)
+	la := area
+	la.Min.X += trackWidth + markGap
+	t.label = newLabel(t.AbstractElement, la, text, t.Style.Font)
+	t.activate = func() {
+		t.SetOn(!t.on)
+		if t.OnChange != nil {
+			t.OnChange(t.on)
+		}
+	}
+	t.update = t.updateLook
+	t.refresh()
+	return t
+}
+
+// On reports whether the switch is on
+func (t *Toggle) On() bool {
+	return t.on
+}
+
+// SetOn flips the switch without calling OnChange
+func (t *Toggle) SetOn(on bool) {
+	t.on = on
+	t.refresh()
+}
+
+func (t *Toggle) updateLook() {
+	t.track.Paint = t.Style.Paint(t.State())
+	if t.on && t.Enabled() {
+		t.track.FillColor = t.Style.Accent
+	}
+	tr := t.track.Area.Inset(3)
+	x := tr.Min.X
+	if t.on {
+		x = tr.Max.X - tr.Dy()
+	}
+	t.thumb.Area = gs.MakeRectWH(x, tr.Min.Y, tr.Dy(), tr.Dy())
+	t.thumb.Paint = gs.NoStroke(rgb(255, 255, 255))
+	if !t.on {
+		t.thumb.FillColor = t.Style.Normal.StrokeColor
+	}
+	t.label.FillColor = t.Style.TextColor(t.State())
+	t.label.TextShape().Font = t.Style.Font
+}
@@ -0,0 +1,52 @@
+package widgets
+
+import (
+	"strings"
+
+	gs "github.com/phaikawl/gosui"
+)
+
+// TooltipStyle is used by the tooltips created by SetTooltip
+var TooltipStyle = Style{
+	Normal: paint(rgb(255, 255, 225), rgb(118, 118, 118)),
+	Text:   rgb(0, 0, 0),
+	Font:   gs.Font{Family: DefaultFont.Family, Size: 12, Style: gs.Regular},
+
+	CornerRadius: 2,
+}
+
+const tooltipPadding = 4
+
+// SetTooltip gives the element a tooltip showing text, which can have several lines.
+// An empty text removes the tooltip. The element must be in a Scene.
+func SetTooltip(e gs.IElement, text string) {
+	s := gs.SceneOf(e)
+	if s == nil {
+		return
+	}
+	if text == "" {
+		s.SetTooltip(e, nil)
+		return
+	}
+	lines := strings.Split(text, "\n")
+	s.SetTooltip(e, func(p *gs.Popup) {
+		st := TooltipStyle
+		lineHeight := st.Font.Size * 3 / 2
+		w := 0
+		for _, l := range lines {
+			if lw, _ := gs.MeasureText(l, st.Font); lw > w {
+				w = lw
+			}
+		}
+		bg := gs.NewRectElement(p.AbstractElement,
+			gs.MakeRectWH(0, 0, w+2*tooltipPadding, len(lines)*lineHeight+2*tooltipPadding))
+		bg.Paint = st.Normal
+		bg.RectShape().SetAllCornerRadiusTo(st.CornerRadius)
+		for i, l := range lines {
+			area := gs.MakeRectWH(tooltipPadding, tooltipPadding+i*lineHeight, w, lineHeight)
+			label := newLabel(p.AbstractElement, area, l, st.Font)
+			label.SetZIndex(0.1)
+			label.FillColor = st.Text
+		}
+	})
+}
@@ -0,0 +1,467 @@
+package widgets
+
+import (
+	"fmt"
+	"image"
+
+	gs "github.com/phaikawl/gosui"
+)
+
+var (
+	// TreeRowHeight is the height of the rows of tree views
+	TreeRowHeight = 22
+	// TreeIndent is the indentation of each level of tree views
+	TreeIndent = 16
+
+	treeArrowWidth = 14
+)
+
+// TreeSource provides the nodes of a TreeView. Nodes are arbitrary comparable values,
+// the root is nil and isn't shown.
+type TreeSource interface {
+	// Children returns the children of a node, it's called the first time the node is expanded
+	Children(node interface{}) []interface{}
+	// HasChildren reports whether the node can be expanded, without loading its children
+	HasChildren(node interface{}) bool
+	// Text returns the label of a node
+	Text(node interface{}) string
+}
+
+// treeNode caches what the tree view knows about a node
+type treeNode struct {
+	value    interface{}
+	parent   *treeNode
+	depth    int
+	children []*treeNode
+	loaded   bool
+	expanded bool
+}
+
+// TreeView shows a hierarchy of nodes that can be expanded and collapsed.
+// Children are loaded lazily from the TreeSource when their parent is first expanded.
+// Like DataGrid, only the visible rows have elements.
+type TreeView struct {
+	*gs.AbstractElement
+	Style    Style
+	src      TreeSource
+	root     *treeNode
+	rows     []*treeNode // Visible nodes, in display order
+	body     *gs.AbstractElement
+	scroll   int // Index of the first visible row
+	selected *treeNode
+	focused  bool
+
+	// OnSelect is called when the selected node changes
+	OnSelect func(node interface{})
+	// OnActivate is called when a node is activated with Enter or by clicking it while it's selected
+	OnActivate func(node interface{})
+}
+
+// NewTreeView creates a TreeView showing the top level nodes of src
+func NewTreeView(parent *gs.AbstractElement, area image.Rectangle, src TreeSource) *TreeView {
+	t := &TreeView{Style: DefaultStyle, src: src}
+	t.AbstractElement = gs.NewAbstractElement(parent, area)
+	t.SetType("treeview")
+	t.Handler = t
+	t.body = gs.NewAbstractElement(t.AbstractElement, area)
+	t.root = &treeNode{depth: -1, expanded: true}
+	t.load(t.root)
+	t.flatten()
+	return t
+}
+
+func (t *TreeView) load(n *treeNode) {
+	n.children = nil
+	for _, v := range t.src.Children(n.value) {
+		n.children = append(n.children, &treeNode{value: v, parent: n, depth: n.depth + 1})
+	}
+	n.loaded = true
+}
+
+// flatten recomputes the visible rows and renders them
+func (t *TreeView) flatten() {
+	t.rows = t.rows[:0]
+	var add func(n *treeNode)
+	add = func(n *treeNode) {
+		for _, c := range n.children {
+			t.rows = append(t.rows, c)
+			if c.expanded {
+				add(c)
+			}
+		}
+	}
+	add(t.root)
+	t.ScrollTo(t.scroll)
+}
+
+// find returns the loaded node holding value, nil if there's none
+func (t *TreeView) find(value interface{}) *treeNode {
+	var search func(n *treeNode) *treeNode
+	search = func(n *treeNode) *treeNode {
+		if n.value == value {
+			return n
+		}
+		for _, c := range n.children {
+			if f := search(c); f != nil {
+				return f
+			}
+		}
+		return nil
+	}
+	return search(t.root)
+}
+
+func (t *TreeView) rowIndex(n *treeNode) int {
+	for i, r := range t.rows {
+		if r == n {
+			return i
+		}
+	}
+	return -1
+}
+
+// Expand shows the children of a loaded node, loading them if needed
+func (t *TreeView) Expand(node interface{}) {
+	if n := t.find(node); n != nil {
+		t.setExpanded(n, true)
+	}
+}
+
+// Collapse hides the children of a node
+func (t *TreeView) Collapse(node interface{}) {
+	if n := t.find(node); n != nil {
+		t.setExpanded(n, false)
+	}
+}
+
+// IsExpanded reports whether a node is expanded
+func (t *TreeView) IsExpanded(node interface{}) bool {
+	n := t.find(node)
+	return n != nil && n.expanded
+}
+
+func (t *TreeView) setExpanded(n *treeNode, expanded bool) {
+	if n == t.root || n.expanded == expanded || (expanded && !t.src.HasChildren(n.value)) {
+		return
+	}
+	if expanded && !n.loaded {
+		t.load(n)
+	}
+	n.expanded = expanded
+	if !expanded && t.selected != nil && t.selected != n && isUnder(t.selected, n) {
+		t.selectNode(n)
+	}
+	t.flatten()
+}
+
+func isUnder(n, anc *treeNode) bool {
+	for p := n.parent; p != nil; p = p.parent {
+		if p == anc {
+			return true
+		}
+	}
+	return false
+}
+
+// Reload drops the loaded children of a node, nil being the root, and asks the
+// source for them again. Expanded nodes stay expanded if they're still there.
+func (t *TreeView) Reload(node interface{}) {
+	n := t.root
+	if node != nil {
+		n = t.find(node)
+	}
+	if n == nil || !n.loaded {
+		return
+	}
+	old := n.children
+	t.load(n)
+	for _, c := range n.children {
+		for _, o := range old {
+			if o.value == c.value {
+				c.children, c.loaded, c.expanded = o.children, o.loaded, o.expanded
+				for _, gc := range c.children {
+					gc.parent = c
+				}
+			}
+		}
+	}
+	if t.selected != nil && t.find(t.selected.value) != t.selected {
+		t.selected = nil
+	}
+	t.flatten()
+}
+
+// Selected returns the selected node, nil if there's none
+func (t *TreeView) Selected() interface{} {
+	if t.selected == nil {
+		return nil
+	}
+	return t.selected.value
+}
+
+// Select selects a loaded node, expanding its ancestors. OnSelect isn't called.
+func (t *TreeView) Select(node interface{}) {
+	n := t.find(node)
+	if n == nil || n == t.root {
+		return
+	}
+	for p := n.parent; p != t.root; p = p.parent {
+		p.expanded = true
+	}
+	t.selected = n
+	t.flatten()
+	t.EnsureVisible(t.rowIndex(n))
+}
+
+func (t *TreeView) selectNode(n *treeNode) {
+	if n == t.selected {
+		return
+	}
+	t.selected = n
+	t.EnsureVisible(t.rowIndex(n))
+	t.render()
+	if t.OnSelect != nil {
+		t.OnSelect(n.value)
+	}
+}
+
+func (t *TreeView) visibleRows() int {
+	return t.H() / TreeRowHeight
+}
+
+// ScrollTo scrolls so that row i is the first visible one, as far as possible
+func (t *TreeView) ScrollTo(i int) {
+	if max := len(t.rows) - t.visibleRows(); i > max {
+		i = max
+	}
+	if i < 0 {
+		i = 0
+	}
+	t.scroll = i
+	t.render()
+}
+
+// FirstVisible returns the index of the first visible row
+func (t *TreeView) FirstVisible() int {
+	return t.scroll
+}
+
+// EnsureVisible scrolls as little as possible to show row i
+func (t *TreeView) EnsureVisible(i int) {
+	switch {
+	case i < 0:
+	case i < t.scroll:
+		t.ScrollTo(i)
+	case i >= t.scroll+t.visibleRows():
+		t.ScrollTo(i - t.visibleRows() + 1)
+	}
+}
+
+func (t *TreeView) arrowArea(i int) image.Rectangle {
+	n := t.rows[i]
+	x := t.X() + n.depth*TreeIndent + 4
+	y := t.Y() + (i-t.scroll)*TreeRowHeight
+	return gs.MakeRectWH(x, y, treeArrowWidth, TreeRowHeight)
+}
+
+// render recreates the elements of the visible rows
+func (t *TreeView) render() {
+	removeChildren(t.body)
+	bg := gs.NewRectElement(t.body, t.Area)
+	bg.Paint = gs.NoStroke(t.Style.Base)
+	if t.focused {
+		bg.StrokeWidth, bg.StrokeColor = 1, t.Style.Focus
+	}
+	font := t.Style.Font
+	for i := t.scroll; i < t.scroll+t.visibleRows() && i < len(t.rows); i++ {
+		n := t.rows[i]
+		arrow := t.arrowArea(i)
+		if n == t.selected {
+			sel := gs.NewRectElement(t.body, gs.MakeRectWH(t.X(), arrow.Min.Y, t.W(), TreeRowHeight))
+			sel.SetZIndex(0.1)
+			sel.Paint = gs.NoStroke(t.Style.Pressed.FillColor)
+		}
+		item := gs.Accessible{Role: gs.RoleTreeItem, Description: fmt.Sprintf("level %d", n.depth+1)}
+		if t.src.HasChildren(n.value) {
+			mark := "▶"
+			if n.expanded {
+				mark = "▼"
+			}
+			a := newLabel(t.body, arrow, mark, font)
+			a.SetZIndex(0.2)
+			a.FillColor = t.Style.Text
+			hideGlyph(a)
+			item.States |= expandState(n.expanded)
+		}
+		if n == t.selected {
+			item.States |= gs.StateSelected
+		}
+		la := arrow
+		la.Min.X, la.Max.X = arrow.Max.X+2, t.Area.Max.X
+		l := newLabel(t.body, la, t.src.Text(n.value), font)
+		l.SetZIndex(0.2)
+		l.FillColor = t.Style.Text
+		gs.SetAccessible(l, item)
+	}
+	gs.Invalidate(t.body)
+}
+
+// ApplyStyle restyles the tree from DefaultStyle with the rules of the stylesheet
+func (t *TreeView) ApplyStyle(sheet *gs.Stylesheet) {
+	t.Style = StyleFrom(sheet, t.AbstractElement, DefaultStyle)
+	t.render()
+}
+
+// OnResize lays out the visible rows
+func (t *TreeView) OnResize(area image.Rectangle) {
+	t.body.Area = area
+	t.ScrollTo(t.scroll)
+}
+
+// Accessible describes the tree to assistive technologies
+func (t *TreeView) Accessible() gs.Accessible {
+	return gs.Accessible{Role: gs.RoleTree}
+}
+
+func (t *TreeView) Focusable() bool {
+	return true
+}
+
+func (t *TreeView) OnFocus() {
+	t.focused = true
+	t.render()
+}
+
+func (t *TreeView) OnBlur() {
+	t.focused = false
+	t.render()
+}
+
+func (t *TreeView) OnMouseEvent(evt *gs.MouseEvent) bool {
+	switch {
+	case evt.Action == gs.EventScroll:
+		t.ScrollTo(t.scroll - evt.Scroll.Y*gridWheelRows)
+		return false
+	case evt.Action != gs.EventPress || evt.Button != gs.MouseButtonLeft:
+		return false
+	}
+	i := t.scroll + (evt.Pos.Y-t.Y())/TreeRowHeight
+	if i >= len(t.rows) {
+		return false
+	}
+	n := t.rows[i]
+	switch {
+	case evt.Pos.In(t.arrowArea(i)):
+		t.setExpanded(n, !n.expanded)
+	case n == t.selected:
+		t.activate(n)
+	default:
+		t.selectNode(n)
+	}
+	return false
+}
+
+func (t *TreeView) activate(n *treeNode) {
+	if t.OnActivate != nil {
+		t.OnActivate(n.value)
+	}
+}
+
+// OnKeyEvent moves the selection with Up, Down, Home, End, Page Up and Page Down.
+// Right expands the selected node or moves to its first child, Left collapses it
+// or moves to its parent, Enter activates it.
+func (t *TreeView) OnKeyEvent(evt *gs.KeyEvent) bool {
+	if evt.Action == gs.EventRelease || len(t.rows) == 0 {
+		return false
+	}
+	n := t.selected
+	i := t.rowIndex(n)
+	page := t.visibleRows() - 1
+	if page < 1 {
+		page = 1
+	}
+	switch evt.Key {
+	case gs.KeyUp:
+		i--
+	case gs.KeyDown:
+		i++
+	case gs.KeyPageUp:
+		i -= page
+	case gs.KeyPageDown:
+		i += page
+	case gs.KeyHome:
+		i = 0
+	case gs.KeyEnd:
+		i = len(t.rows) - 1
+	case gs.KeyRight:
+		switch {
+		case n == nil:
+			i = 0
+		case !n.expanded:
+			t.setExpanded(n, true)
+			return true
+		case len(n.children) > 0:
+			i++
+		}
+	case gs.KeyLeft:
+		switch {
+		case n == nil:
+			i = 0
+		case n.expanded:
+			t.setExpanded(n, false)
+			return true
+		case n.parent != t.root:
+			i = t.rowIndex(n.parent)
+		}
+	case gs.KeyEnter:
+		if n != nil {
+			t.activate(n)
+		}
+		return true
+	default:
+		return false
+	}
+	if i < 0 {
+		i = 0
+	} else if i >= len(t.rows) {
+		i = len(t.rows) - 1
+	}
+	t.selectNode(t.rows[i])
+	return true
+}
+
+// ElementTree is a TreeSource showing a tree of elements, for debugging
+type ElementTree struct {
+	Root gs.IElement
+}
+
+func (s ElementTree) Children(node interface{}) (l []interface{}) {
+	if node == nil {
+		return []interface{}{s.Root}
+	}
+	if a, ok := node.(*gs.AbstractElement); ok {
+		for _, c := range a.Children() {
+			l = append(l, c)
+		}
+	}
+	return l
+}
+
+func (s ElementTree) HasChildren(node interface{}) bool {
+	a, ok := node.(*gs.AbstractElement)
+	return node == nil || ok && len(a.Children()) > 0
+}
+
+func (s ElementTree) Text(node interface{}) string {
+	e := node.(gs.IElement)
+	b := e.BaseElement()
+	kind := "Abstract"
+	if c, ok := e.(*gs.ConcreteElement); ok {
+		if c.IsText() {
+			kind = fmt.Sprintf("Text %q", c.TextShape().Content)
+		} else {
+			kind = "Rect"
+		}
+	}
+	return fmt.Sprintf("%s %v z=%g", kind, b.Area, b.ZIndex())
+}
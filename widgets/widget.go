@@ -0,0 +1,285 @@
+// Package widgets provides standard controls built on gosui elements
+package widgets
+
+import (
+	"image"
+
+	gs "github.com/phaikawl/gosui"
+)
+
+var (
+	// DefaultFont is used for the text of widgets that aren't given a font
+	DefaultFont = gs.Font{Family: "Arial", Size: 14, Style: gs.Regular}
+
+	// DefaultStyle is the style given to new widgets
+	DefaultStyle = Style{
+		Normal:   paint(rgb(240, 240, 240), rgb(160, 160, 160)),
+		Hovered:  paint(rgb(229, 241, 251), rgb(0, 120, 215)),
+		Pressed:  paint(rgb(204, 228, 247), rgb(0, 84, 153)),
+		Disabled: paint(rgb(230, 230, 230), rgb(200, 200, 200)),
+		Accent:   rgb(0, 120, 215),
+		Focus:    rgb(0, 90, 180),
+		Text:     rgb(0, 0, 0),
+		Base:     rgb(255, 255, 255),
+		Font:     DefaultFont,
+
+		CornerRadius: 3,
+		Padding:      6,
+	}
+)
+
+// Style holds the paints used for each visual state of a widget
+type Style struct {
+	Normal, Hovered, Pressed, Disabled gs.Paint
+	Accent                             gs.Color // Color of marks, like checkbox ticks or the on state of a toggle
+	Focus                              gs.Color // Stroke color when the widget has the focus
+	Text                               gs.Color
+	Base                               gs.Color // Background of content areas, like lists and text fields
+	Font                               gs.Font
+	CornerRadius                       int
+	Padding                            int // Space between the edges of cells and their text
+}
+
+// State is the visual state of a widget
+type State struct {
+	Hovered, Pressed, Focused, Disabled bool
+}
+
+// Paint returns the paint to use for the state
+func (s *Style) Paint(st State) (p gs.Paint) {
+	switch {
+	case st.Disabled:
+		return s.Disabled
+	case st.Pressed:
+		p = s.Pressed
+	case st.Hovered:
+		p = s.Hovered
+	default:
+		p = s.Normal
+	}
+	if st.Focused {
+		p.StrokeColor = s.Focus
+		p.StrokeWidth++
+	}
+	return p
+}
+
+// TextColor returns the color of the text for the state
+func (s *Style) TextColor(st State) gs.Color {
+	if st.Disabled {
+		return s.Disabled.StrokeColor
+	}
+	return s.Text
+}
+
+// control implements the event handling shared by all widgets:
+// hovering, pressing, focus and keyboard activation.
+// It's set as the Handler of the widget's AbstractElement.
+type control struct {
+	*gs.AbstractElement
+	Style Style
+	state State
+	// Position of the last mouse press
+	pressPos image.Point
+
+	activate func()                  // Called on click, Space or Enter
+	keyHook  func(*gs.KeyEvent) bool // Extra key handling, can be nil
+	drag     func(image.Point)       // Called on press and on moves while pressed, can be nil
+	update   func()                  // Refreshes the appearance after a state change
+	noFocus  bool                    // Set for widgets that shouldn't take the focus
+	role     gs.Role                 // Role given to assistive technologies
+	describe func(*gs.Accessible)    // Adds the value and states of the widget, can be nil
+}
+
+func (c *control) init(parent *gs.AbstractElement, area image.Rectangle) {
+	c.AbstractElement = gs.NewAbstractElement(parent, area)
+	c.Handler = c
+	c.Style = DefaultStyle
+}
+
+// State returns the current visual state of the widget, disabled when the
+// widget or one of its containers is
+func (c *control) State() State {
+	st := c.state
+	st.Disabled = !c.Enabled()
+	return st
+}
+
+// Enabled reports whether the widget accepts input, that is whether it and
+// its containers are enabled
+func (c *control) Enabled() bool {
+	return c.EnabledInTree()
+}
+
+// SetEnabled enables or disables the widget
+func (c *control) SetEnabled(enabled bool) {
+	gs.SetEnabled(c.AbstractElement, enabled)
+}
+
+// OnEnabledChange refreshes the widget when it or one of its containers is
+// enabled or disabled
+func (c *control) OnEnabledChange(enabled bool) {
+	if !enabled {
+		c.state.Pressed = false
+	}
+	c.refresh()
+}
+
+// Focus gives the keyboard focus to the widget
+func (c *control) Focus() {
+	if s := gs.SceneOf(c.AbstractElement); s != nil {
+		s.Focus(c.AbstractElement)
+	}
+}
+
+func (c *control) refresh() {
+	c.state.Disabled = !c.Enabled()
+	// Parts may move, so the old area is redrawn too
+	gs.Invalidate(c.AbstractElement)
+	if c.update != nil {
+		c.update()
+	}
+	gs.Invalidate(c.AbstractElement)
+}
+
+// Accessible describes the widget to assistive technologies
+func (c *control) Accessible() gs.Accessible {
+	a := gs.Accessible{Role: c.role}
+	if c.state.Pressed {
+		a.States |= gs.StatePressed
+	}
+	if !c.Enabled() {
+		a.States |= gs.StateDisabled
+	}
+	if c.describe != nil {
+		c.describe(&a)
+	}
+	return a
+}
+
+// checkState returns the states of a checkable element
+func checkState(checked bool) gs.AccessState {
+	if checked {
+		return gs.StateCheckable | gs.StateChecked
+	}
+	return gs.StateCheckable
+}
+
+// expandState returns the states of an element that can be expanded
+func expandState(expanded bool) gs.AccessState {
+	if expanded {
+		return gs.StateExpandable | gs.StateExpanded
+	}
+	return gs.StateExpandable
+}
+
+// hideGlyph hides a decorative label from assistive technologies
+func hideGlyph(l *gs.ConcreteElement) {
+	gs.SetAccessible(l, gs.Accessible{Hidden: true})
+}
+
+func (c *control) Focusable() bool {
+	return c.Enabled() && !c.noFocus
+}
+
+func (c *control) OnFocus() {
+	c.state.Focused = true
+	c.refresh()
+}
+
+func (c *control) OnBlur() {
+	c.state.Focused = false
+	c.state.Pressed = false
+	c.refresh()
+}
+
+func (c *control) OnMouseEnter() {
+	c.state.Hovered = true
+	c.refresh()
+}
+
+func (c *control) OnMouseLeave() {
+	c.state.Hovered = false
+	c.refresh()
+}
+
+func (c *control) OnMouseEvent(evt *gs.MouseEvent) bool {
+	if !c.Enabled() || evt.Button != gs.MouseButtonLeft {
+		return false
+	}
+	switch evt.Action {
+	case gs.EventPress:
+		c.state.Pressed = true
+		c.pressPos = evt.Pos
+		if s := gs.SceneOf(c.AbstractElement); s != nil {
+			s.GrabMouse(c.AbstractElement)
+		}
+		if c.drag != nil {
+			c.drag(evt.Pos)
+		}
+		c.refresh()
+	case gs.EventMove:
+		if c.state.Pressed && c.drag != nil {
+			c.drag(evt.Pos)
+			return false
+		}
+		return true
+	case gs.EventRelease:
+		if !c.state.Pressed {
+			return false
+		}
+		c.state.Pressed = false
+		c.refresh()
+		if evt.Pos.In(c.Area) && c.activate != nil {
+			c.activate()
+		}
+	default:
+		return true
+	}
+	return false
+}
+
+func (c *control) OnKeyEvent(evt *gs.KeyEvent) bool {
+	if !c.Enabled() {
+		return false
+	}
+	if c.keyHook != nil && c.keyHook(evt) {
+		return true
+	}
+	if evt.Key != gs.KeySpace && evt.Key != gs.KeyEnter {
+		return false
+	}
+	switch evt.Action {
+	case gs.EventPress:
+		c.state.Pressed = true
+		c.refresh()
+	case gs.EventRelease:
+		if !c.state.Pressed {
+			return true
+		}
+		c.state.Pressed = false
+		c.refresh()
+		if c.activate != nil {
+			c.activate()
+		}
+	}
+	return true
+}
+
+func rgb(r, g, b uint8) gs.Color {
+	return gs.Color{R: r, G: g, B: b, A: 255}
+}
+
+// paint returns a Paint with a 1px stroke
+func paint(fill, stroke gs.Color) gs.Paint {
+	return gs.Paint{FillColor: fill, StrokeWidth: 1, StrokeColor: stroke}
+}
+
+// newLabel creates a text element whose text is vertically centered in area,
+// starting from its left edge
+func newLabel(parent *gs.AbstractElement, area image.Rectangle, text string, font gs.Font) *gs.ConcreteElement {
+	baseline := area.Min.Y + (area.Dy()+font.Size*7/10)/2
+	l := gs.NewTextElement(parent, area.Min.X, baseline, font, false)
+	l.TextShape().Content = text
+	return l
+}
@@ -0,0 +1,803 @@
+package widgets
+
+import (
+	"image"
+	"strconv"
+	"testing"
+	"time"
+
+	gs "github.com/phaikawl/gosui"
+	chk "launchpad.net/gocheck"
+)
+
+func Test(t *testing.T) { chk.TestingT(t) }
+
+type WidgetsSuite struct{}
+
+var _ = chk.Suite(&WidgetsSuite{})
+
+func click(s *gs.Scene, x, y int) {
+	pos := image.Point{x, y}
+	s.HandleMouse(&gs.MouseEvent{Pos: pos, Action: gs.EventPress})
+	s.HandleMouse(&gs.MouseEvent{Pos: pos, Action: gs.EventRelease})
+}
+
+func press(s *gs.Scene, key gs.Key) {
+	s.HandleKey(&gs.KeyEvent{Key: key, Action: gs.EventPress})
+	s.HandleKey(&gs.KeyEvent{Key: key, Action: gs.EventRelease})
+}
+
+func (s *WidgetsSuite) TestButton(c *chk.C) {
+	sc := gs.NewScene()
+	b := NewButton(sc.Root(), gs.MakeRectWH(10, 10, 100, 30), "OK")
+	clicks := 0
+	b.OnClick = func() { clicks++ }
+
+	sc.HandleMouse(&gs.MouseEvent{Pos: image.Point{20, 20}, Action: gs.EventMove})
+	c.Check(b.State().Hovered, chk.Equals, true)
+	sc.HandleMouse(&gs.MouseEvent{Pos: image.Point{20, 20}, Action: gs.EventPress})
+	c.Check(b.State(), chk.Equals, State{Hovered: true, Pressed: true, Focused: true})
+	c.Check(sc.Focused(), chk.Equals, gs.IElement(b.AbstractElement))
+	// Releasing outside doesn't click
+	sc.HandleMouse(&gs.MouseEvent{Pos: image.Point{300, 300}, Action: gs.EventRelease})
+	c.Check(clicks, chk.Equals, 0)
+	c.Check(b.State(), chk.Equals, State{Focused: true})
+
+	click(sc, 20, 20)
+	press(sc, gs.KeySpace)
+	press(sc, gs.KeyEnter)
+	c.Check(clicks, chk.Equals, 3)
+
+	b.SetEnabled(false)
+	c.Check(sc.Focused(), chk.IsNil)
+	click(sc, 20, 20)
+	b.Click()
+	c.Check(clicks, chk.Equals, 3)
+
+	// Disabling a container disables the widgets in it
+	b.SetEnabled(true)
+	gs.SetEnabled(sc.Root(), false)
+	c.Check(b.Enabled(), chk.Equals, false)
+	c.Check(b.State().Disabled, chk.Equals, true)
+	click(sc, 20, 20)
+	c.Check(clicks, chk.Equals, 3)
+	gs.SetEnabled(sc.Root(), true)
+	click(sc, 20, 20)
+	c.Check(clicks, chk.Equals, 4)
+}
+
+func (s *WidgetsSuite) TestCheckboxAndToggle(c *chk.C) {
+	sc := gs.NewScene()
+	cb := NewCheckbox(sc.Root(), gs.MakeRectWH(0, 0, 100, 20), "Check")
+	t := NewToggle(sc.Root(), gs.MakeRectWH(0, 30, 100, 20), "Toggle")
+	var changes []bool
+	cb.OnChange = func(v bool) { changes = append(changes, v) }
+	t.OnChange = func(v bool) { changes = append(changes, v) }
+
+	click(sc, 5, 5)
+	c.Check(cb.Checked(), chk.Equals, true)
+	press(sc, gs.KeySpace)
+	c.Check(cb.Checked(), chk.Equals, false)
+	press(sc, gs.KeyTab)
+	press(sc, gs.KeySpace)
+	c.Check(t.On(), chk.Equals, true)
+	t.SetOn(false)
+	c.Check(changes, chk.DeepEquals, []bool{true, false, true})
+}
+
+func (s *WidgetsSuite) TestRadioGroup(c *chk.C) {
+	sc := gs.NewScene()
+	g := NewRadioGroup(sc.Root(), gs.MakeRectWH(0, 0, 100, 3*RadioItemHeight), []string{"a", "b", "c"})
+	var changes []int
+	g.OnChange = func(i int) { changes = append(changes, i) }
+	c.Check(g.Selected(), chk.Equals, -1)
+
+	click(sc, 5, RadioItemHeight+5)
+	c.Check(g.Selected(), chk.Equals, 1)
+	press(sc, gs.KeyDown)
+	press(sc, gs.KeyDown)
+	press(sc, gs.KeyUp)
+	c.Check(changes, chk.DeepEquals, []int{1, 2, 0, 2})
+}
+
+func (s *WidgetsSuite) TestSlider(c *chk.C) {
+	sc := gs.NewScene()
+	sl := NewSlider(sc.Root(), gs.MakeRectWH(0, 0, 116, 20), Horizontal, 0, 10)
+	sl.SetStep(1)
+	var changes []float64
+	sl.OnChange = func(v float64) { changes = append(changes, v) }
+
+	sc.HandleMouse(&gs.MouseEvent{Pos: image.Point{58, 10}, Action: gs.EventPress})
+	sc.HandleMouse(&gs.MouseEvent{Pos: image.Point{1000, 500}, Action: gs.EventMove})
+	sc.HandleMouse(&gs.MouseEvent{Pos: image.Point{1000, 500}, Action: gs.EventRelease})
+	press(sc, gs.KeyLeft)
+	press(sc, gs.KeyHome)
+	press(sc, gs.KeyPageUp)
+	c.Check(changes, chk.DeepEquals, []float64{5, 10, 9, 0, 10})
+
+	v := NewSlider(sc.Root(), gs.MakeRectWH(200, 0, 20, 116), Vertical, 0, 100)
+	v.SetValue(100)
+	c.Check(v.thumb.Area.Min.Y, chk.Equals, 0)
+}
+
+func (s *WidgetsSuite) TestProgressBar(c *chk.C) {
+	sc := gs.NewScene()
+	p := NewProgressBar(sc.Root(), gs.MakeRectWH(0, 0, 200, 10), Horizontal)
+	p.SetValue(0.25)
+	c.Check(p.fill.Area, chk.Equals, gs.MakeRectWH(0, 0, 50, 10))
+	p.SetValue(2)
+	c.Check(p.Value(), chk.Equals, 1.0)
+	p.SetIndeterminate(true)
+	for i := 0; i < PulseSteps; i++ {
+		p.Pulse()
+	}
+	c.Check(p.fill.Area, chk.Equals, gs.MakeRectWH(150, 0, 50, 10))
+}
+
+func (s *WidgetsSuite) TestSpinBox(c *chk.C) {
+	sc := gs.NewScene()
+	sp := NewSpinBox(sc.Root(), gs.MakeRectWH(0, 0, 100, 24), 0, 10, 0.5, 1)
+	var changes []float64
+	sp.OnChange = func(v float64) { changes = append(changes, v) }
+	c.Check(sp.Text(), chk.Equals, "0.0")
+
+	sp.Focus()
+	press(sc, gs.KeyBackspace)
+	press(sc, gs.KeyBackspace)
+	press(sc, gs.KeyBackspace)
+	for _, r := range "4a2" {
+		sc.HandleChar(&gs.CharEvent{Char: r})
+	}
+	press(sc, gs.KeyEnter)
+	c.Check(sp.Value(), chk.Equals, 10.0)
+	press(sc, gs.KeyDown)
+	click(sc, 95, 3)
+	c.Check(sp.Text(), chk.Equals, "10.0")
+
+	sp.Focus()
+	sc.HandleChar(&gs.CharEvent{Char: '.'})
+	sc.HandleChar(&gs.CharEvent{Char: '.'})
+	sc.Focus(nil)
+	c.Check(sp.Text(), chk.Equals, "10.0")
+	c.Check(changes, chk.DeepEquals, []float64{10, 9.5, 10})
+
+	gs.SetEnabled(sc.Root(), false)
+	c.Check(sp.State().Disabled, chk.Equals, true)
+	sp.Focus()
+	c.Check(sc.Focused(), chk.IsNil)
+	click(sc, 95, 3)
+	c.Check(sp.Value(), chk.Equals, 10.0)
+}
+
+func (s *WidgetsSuite) TestDropdown(c *chk.C) {
+	sc := gs.NewScene()
+	d := NewDropdown(sc.Root(), gs.MakeRectWH(0, 0, 100, 24), []string{"a", "b", "c"})
+	var changes []int
+	d.OnChange = func(i int) { changes = append(changes, i) }
+
+	click(sc, 5, 5)
+	c.Check(d.IsOpen(), chk.Equals, true)
+	c.Check(d.list.Area.Min, chk.Equals, image.Point{0, 24})
+	// Clicking the dropdown again closes the list
+	click(sc, 5, 5)
+	c.Check(d.IsOpen(), chk.Equals, false)
+
+	click(sc, 5, 5)
+	itemY := 24 + 1 + ListItemHeight + 5
+	sc.HandleMouse(&gs.MouseEvent{Pos: image.Point{5, itemY}, Action: gs.EventMove})
+	click(sc, 5, itemY)
+	c.Check(d.IsOpen(), chk.Equals, false)
+	c.Check(d.Selected(), chk.Equals, 1)
+	c.Check(sc.Focused(), chk.Equals, gs.IElement(d.AbstractElement))
+
+	press(sc, gs.KeyDown)
+	press(sc, gs.KeySpace)
+	press(sc, gs.KeyUp)
+	press(sc, gs.KeyEnter)
+	c.Check(changes, chk.DeepEquals, []int{1, 2, 1})
+	press(sc, gs.KeyEnter)
+	press(sc, gs.KeyEscape)
+	c.Check(d.IsOpen(), chk.Equals, false)
+}
+
+func (s *WidgetsSuite) TestComboBox(c *chk.C) {
+	sc := gs.NewScene()
+	cb := NewComboBox(sc.Root(), gs.MakeRectWH(0, 0, 100, 24), []string{"Apple", "Apricot", "Banana"})
+	var changes []string
+	cb.OnChange = func(t string) { changes = append(changes, t) }
+
+	cb.Focus()
+	sc.HandleChar(&gs.CharEvent{Char: 'a'})
+	c.Check(cb.IsOpen(), chk.Equals, true)
+	c.Check(cb.shown, chk.DeepEquals, []string{"Apple", "Apricot"})
+	sc.HandleChar(&gs.CharEvent{Char: 'x'})
+	c.Check(cb.IsOpen(), chk.Equals, false)
+	press(sc, gs.KeyEnter)
+	press(sc, gs.KeyBackspace)
+	press(sc, gs.KeyDown)
+	press(sc, gs.KeyDown)
+	press(sc, gs.KeyEnter)
+	c.Check(cb.Text(), chk.Equals, "Apricot")
+	c.Check(changes, chk.DeepEquals, []string{"ax", "Apricot"})
+}
+
+func (s *WidgetsSuite) TestComboBoxRefilter(c *chk.C) {
+	sc := gs.NewScene()
+	cb := NewComboBox(sc.Root(), gs.MakeRectWH(0, 0, 100, 24), []string{"Apple", "Apricot", "Avocado", "Banana"})
+	cb.Focus()
+	for _, r := range "apr" {
+		sc.HandleChar(&gs.CharEvent{Char: r})
+	}
+	c.Check(cb.shown, chk.DeepEquals, []string{"Apricot"})
+	// The frame, then the background and label of the item
+	c.Check(cb.list.Children(), chk.HasLen, 3)
+	c.Check(cb.list.Size().Y, chk.Equals, ListItemHeight+2)
+}
+
+func (s *WidgetsSuite) TestContextMenu(c *chk.C) {
+	sc := gs.NewScene()
+	target := gs.NewRectElement(sc.Root(), gs.MakeRectWH(0, 0, 300, 300))
+	var log []string
+	item := func(text string) *MenuItem {
+		return &MenuItem{Text: text, OnSelect: func() { log = append(log, text) }}
+	}
+	wrap := item("Wrap")
+	wrap.Checkable = true
+	disabled := item("Disabled")
+	disabled.Disabled = true
+	sub := NewMenu(item("Sub 1"), item("Sub 2"))
+	m := NewMenu(item("Cut"), Separator(), disabled, wrap, &MenuItem{Text: "More", Submenu: sub})
+	m.AttachTo(target)
+
+	sc.HandleMouse(&gs.MouseEvent{Pos: image.Point{10, 10}, Button: gs.MouseButtonRight, Action: gs.EventPress})
+	sc.HandleMouse(&gs.MouseEvent{Pos: image.Point{10, 10}, Button: gs.MouseButtonRight, Action: gs.EventRelease})
+	c.Check(m.IsOpen(), chk.Equals, true)
+	c.Check(sc.Focused(), chk.Equals, gs.IElement(m.popup.AbstractElement))
+	// Down skips the separator and the disabled item
+	press(sc, gs.KeyDown)
+	press(sc, gs.KeyDown)
+	press(sc, gs.KeyEnter)
+	c.Check(m.IsOpen(), chk.Equals, false)
+	c.Check(wrap.Checked, chk.Equals, true)
+
+	m.Popup(sc, image.Point{10, 10})
+	press(sc, gs.KeyUp)
+	press(sc, gs.KeyRight)
+	c.Check(sub.IsOpen(), chk.Equals, true)
+	press(sc, gs.KeyLeft)
+	c.Check(sub.IsOpen(), chk.Equals, false)
+	c.Check(m.IsOpen(), chk.Equals, true)
+	press(sc, gs.KeyRight)
+	press(sc, gs.KeyDown)
+	press(sc, gs.KeyEnter)
+	c.Check(m.IsOpen(), chk.Equals, false)
+	c.Check(sub.IsOpen(), chk.Equals, false)
+
+	// Clicking an item with the mouse
+	m.Popup(sc, image.Point{10, 10})
+	pos := image.Point{20, 10 + 1 + MenuItemHeight/2}
+	sc.HandleMouse(&gs.MouseEvent{Pos: pos, Action: gs.EventMove})
+	click(sc, pos.X, pos.Y)
+	c.Check(log, chk.DeepEquals, []string{"Wrap", "Sub 2", "Cut"})
+}
+
+func (s *WidgetsSuite) TestMenuRebuild(c *chk.C) {
+	sc := gs.NewScene()
+	wrap := &MenuItem{Text: "Wrap", Checkable: true, Checked: true}
+	m := NewMenu(&MenuItem{Text: "Cut"}, wrap)
+	m.Popup(sc, image.Point{10, 10})
+	// The frame, then the background and label of each item and the check mark
+	c.Check(m.popup.Children(), chk.HasLen, 6)
+	m.Close()
+	wrap.Checked = false
+	m.Popup(sc, image.Point{10, 10})
+	c.Check(m.popup.Children(), chk.HasLen, 5)
+}
+
+func (s *WidgetsSuite) TestMenuBar(c *chk.C) {
+	sc := gs.NewScene()
+	bar := NewMenuBar(sc.Root(), gs.MakeRectWH(0, 0, 400, 24))
+	saved := 0
+	save := &MenuItem{Text: "Save", Shortcut: gs.Shortcut{Key: 'S', Mod: gs.Modifiers{Control: true}},
+		OnSelect: func() { saved++ }}
+	file, edit := NewMenu(save), NewMenu(&MenuItem{Text: "Undo"})
+	bar.AddMenu("File", file)
+	bar.AddMenu("Edit", edit)
+
+	sc.HandleKey(&gs.KeyEvent{Key: 'S', Mod: gs.Modifiers{Control: true}})
+	c.Check(saved, chk.Equals, 1)
+
+	click(sc, 5, 5)
+	c.Check(file.IsOpen(), chk.Equals, true)
+	c.Check(file.popup.Area.Min, chk.Equals, image.Point{0, 24})
+	// Hovering the other title switches menus
+	sc.HandleMouse(&gs.MouseEvent{Pos: bar.titles[1].bg.Area.Min, Action: gs.EventMove})
+	c.Check(file.IsOpen(), chk.Equals, false)
+	c.Check(edit.IsOpen(), chk.Equals, true)
+	press(sc, gs.KeyRight)
+	c.Check(file.IsOpen(), chk.Equals, true)
+	press(sc, gs.KeyEnter)
+	c.Check(saved, chk.Equals, 2)
+	c.Check(bar.open, chk.Equals, -1)
+}
+
+func (s *WidgetsSuite) TestDialogs(c *chk.C) {
+	sc := gs.NewScene()
+	sc.SetViewport(gs.MakeRect(0, 0, 800, 600))
+	under := NewButton(sc.Root(), gs.MakeRectWH(0, 0, 800, 600), "Under")
+	under.OnClick = func() { c.Error("clicked beneath a dialog") }
+
+	var answers []bool
+	d := Confirm(sc, "Quit", "Really quit?", func(ok bool) { answers = append(answers, ok) })
+	sz := d.Size()
+	c.Check(d.Area.Min, chk.Equals, image.Point{400 - sz.X/2, 300 - sz.Y/2})
+	c.Check(sc.Focused(), chk.Equals, gs.IElement(d.Buttons[0].AbstractElement))
+	click(sc, 5, 5)
+	press(sc, gs.KeyEscape)
+	c.Check(d.IsOpen(), chk.Equals, false)
+
+	d = Confirm(sc, "Quit", "Really quit?", func(ok bool) { answers = append(answers, ok) })
+	press(sc, gs.KeyEnter)
+	c.Check(answers, chk.DeepEquals, []bool{false, true})
+
+	var name string
+	d = Prompt(sc, "Name", "Your name:", "Bo", func(text string, ok bool) {
+		if ok {
+			name = text
+		}
+	})
+	result := d.Result()
+	sc.HandleChar(&gs.CharEvent{Char: 'b'})
+	press(sc, gs.KeyEnter)
+	c.Check(name, chk.Equals, "Bob")
+	c.Check(<-result, chk.Equals, 0)
+
+	// Finishing twice before the result is read doesn't block
+	d = Confirm(sc, "Quit", "Really quit?", func(ok bool) { answers = append(answers, ok) })
+	result = d.Result()
+	d.Finish(1)
+	d.Finish(0)
+	c.Check(<-result, chk.Equals, 1)
+	c.Check(answers, chk.DeepEquals, []bool{false, true, false})
+
+	done := false
+	MessageBox(sc, "Info", "Done\nreally", func() { done = true })
+	press(sc, gs.KeySpace)
+	c.Check(done, chk.Equals, true)
+	c.Check(sc.Popups(), chk.HasLen, 0)
+}
+
+func (s *WidgetsSuite) TestSplitPane(c *chk.C) {
+	sc := gs.NewScene()
+	p := NewSplitPane(sc.Root(), gs.MakeRectWH(0, 0, 206, 100), Horizontal)
+	c.Check(p.Position(), chk.Equals, 100)
+	left := gs.NewRectElement(p.First(), image.Rectangle{})
+	c.Check(p.First().Area, chk.Equals, gs.MakeRect(0, 0, 100, 100))
+	c.Check(p.Second().Area, chk.Equals, gs.MakeRect(106, 0, 206, 100))
+
+	var moves []int
+	p.OnChange = func(pos int) { moves = append(moves, pos) }
+	p.SetFirstLimits(50, 150)
+	p.SetSecondLimits(80, 0)
+	sc.HandleMouse(&gs.MouseEvent{Pos: image.Point{102, 50}, Action: gs.EventPress})
+	sc.HandleMouse(&gs.MouseEvent{Pos: image.Point{62, 50}, Action: gs.EventMove})
+	sc.HandleMouse(&gs.MouseEvent{Pos: image.Point{10, 50}, Action: gs.EventMove})
+	// The mouse is grabbed, so the divider follows it outside the pane
+	sc.HandleMouse(&gs.MouseEvent{Pos: image.Point{500, 50}, Action: gs.EventMove})
+	sc.HandleMouse(&gs.MouseEvent{Pos: image.Point{500, 50}, Action: gs.EventRelease})
+	c.Check(moves, chk.DeepEquals, []int{60, 50, 120})
+	// Children of panes fill them
+	c.Check(left.Area, chk.Equals, gs.MakeRect(0, 0, 120, 100))
+
+	gs.Resize(p, gs.MakeRectWH(0, 0, 160, 100))
+	c.Check(p.Position(), chk.Equals, 74)
+	c.Check(p.Second().Area, chk.Equals, gs.MakeRect(80, 0, 160, 100))
+}
+
+func (s *WidgetsSuite) TestTabView(c *chk.C) {
+	sc := gs.NewScene()
+	t := NewTabView(sc.Root(), gs.MakeRectWH(0, 0, 300, 200))
+	pages := []*gs.AbstractElement{}
+	for _, title := range []string{"A", "B", "C"} {
+		page := gs.NewAbstractElement(sc.Root(), image.Rectangle{})
+		NewButton(page, gs.MakeRectWH(0, 0, 50, 20), title)
+		pages = append(pages, page)
+		t.AddTab(title, page)
+	}
+	var changes []int
+	t.OnChange = func(i int) { changes = append(changes, i) }
+	c.Check(t.Current(), chk.Equals, 0)
+	c.Check(pages[0].Parent(), chk.NotNil)
+	c.Check(pages[1].Parent(), chk.IsNil)
+	c.Check(pages[0].Area, chk.Equals, gs.MakeRect(0, TabBarHeight, 300, 200))
+
+	// Headers are 31px wide
+	click(sc, 40, 10)
+	c.Check(t.Current(), chk.Equals, 1)
+	c.Check(pages[0].Parent(), chk.IsNil)
+	c.Check(pages[1].Parent(), chk.NotNil)
+
+	// Only works when the focus is inside the view
+	sc.Focus(pages[1].Children()[0])
+	sc.HandleKey(&gs.KeyEvent{Key: gs.KeyTab, Mod: gs.Modifiers{Control: true}, Action: gs.EventPress})
+	c.Check(t.Current(), chk.Equals, 2)
+	c.Check(sc.Focused(), chk.IsNil) // The focused page was detached
+
+	// Dragging C over A moves it first
+	sc.HandleMouse(&gs.MouseEvent{Pos: image.Point{70, 10}, Action: gs.EventPress})
+	sc.HandleMouse(&gs.MouseEvent{Pos: image.Point{40, 10}, Action: gs.EventMove})
+	sc.HandleMouse(&gs.MouseEvent{Pos: image.Point{5, 10}, Action: gs.EventMove})
+	sc.HandleMouse(&gs.MouseEvent{Pos: image.Point{5, 10}, Action: gs.EventRelease})
+	c.Check([]string{t.Title(0), t.Title(1), t.Title(2)}, chk.DeepEquals, []string{"C", "A", "B"})
+	c.Check(t.Current(), chk.Equals, 0)
+	c.Check(changes, chk.DeepEquals, []int{1, 2})
+
+	t.Closable = true
+	t.OnClose = func(i int) bool { return t.Title(i) != "A" }
+	t.SetCurrent(1)
+	// Headers are 49px wide with the close button, which is on the right
+	click(sc, 49+40, 10)
+	c.Check(t.Len(), chk.Equals, 3)
+	click(sc, 40, 10) // Closes C
+	c.Check(t.Len(), chk.Equals, 2)
+	c.Check(t.Current(), chk.Equals, 0)
+	c.Check(t.Title(0), chk.Equals, "A")
+	c.Check(pages[2].Parent(), chk.IsNil)
+
+	page := t.RemoveTab(0)
+	c.Check(page, chk.Equals, gs.IElement(pages[0]))
+	c.Check(t.Current(), chk.Equals, 0)
+	c.Check(pages[1].Parent(), chk.NotNil)
+	c.Check(changes, chk.DeepEquals, []int{1, 2, 1, 0})
+}
+
+func (s *WidgetsSuite) TestAccordion(c *chk.C) {
+	sc := gs.NewScene()
+	a := NewAccordion(sc.Root(), gs.MakeRectWH(0, 0, 200, 400))
+	s1 := a.AddSection("One", 100)
+	s2 := a.AddSection("Two", 50)
+	content := gs.NewRectElement(s1.Body(), image.Rectangle{})
+	gs.Resize(content, s1.Body().Area)
+	c.Check(s2.Y(), chk.Equals, HeaderHeight+100)
+
+	click(sc, 10, 10)
+	c.Check(s1.Expanded(), chk.Equals, false)
+	c.Check(s1.Body().Parent(), chk.IsNil)
+	c.Check(sc.Animating(), chk.Equals, true)
+	t0 := time.Unix(0, 0)
+	sc.Tick(t0)
+	sc.Tick(t0.Add(ExpandDuration / 2))
+	c.Check(s1.H(), chk.Equals, HeaderHeight+50)
+	c.Check(s2.Y(), chk.Equals, HeaderHeight+50)
+	sc.Tick(t0.Add(ExpandDuration))
+	c.Check(sc.Animating(), chk.Equals, false)
+	c.Check(s2.Y(), chk.Equals, HeaderHeight)
+
+	a.Exclusive = true
+	press(sc, gs.KeyRight)
+	for i := 0; i <= 2; i++ {
+		sc.Tick(t0.Add(time.Duration(i) * ExpandDuration))
+	}
+	c.Check(s1.Expanded(), chk.Equals, true)
+	c.Check(s2.Expanded(), chk.Equals, false)
+	c.Check(s1.Body().Parent(), chk.NotNil)
+	c.Check(content.Area, chk.Equals, gs.MakeRectWH(0, HeaderHeight, 200, 100))
+	c.Check(s2.Area, chk.Equals, gs.MakeRectWH(0, HeaderHeight+100, 200, HeaderHeight))
+
+	gs.Resize(a, gs.MakeRectWH(10, 0, 300, 400))
+	c.Check(content.Area, chk.Equals, gs.MakeRectWH(10, HeaderHeight, 300, 100))
+}
+
+type tableModel [][]string
+
+func (m tableModel) Rows() int                         { return len(m) }
+func (m tableModel) Cell(row, col int) string          { return m[row][col] }
+func (m tableModel) SetCell(row, col int, text string) { m[row][col] = text }
+
+// countConcrete returns the number of concrete elements in e
+func countConcrete(e gs.IElement) int {
+	return e.AllConcreteDescns().Len()
+}
+
+func (s *WidgetsSuite) TestDataGrid(c *chk.C) {
+	sc := gs.NewScene()
+	var m tableModel
+	for i := 0; i < 10000; i++ {
+		m = append(m, []string{strconv.Itoa(i), strconv.Itoa(i % 7)})
+	}
+	// The header and 10 rows
+	g := NewDataGrid(sc.Root(), gs.MakeRectWH(0, 0, 200, GridHeaderHeight+10*GridRowHeight), m)
+	id := g.AddColumn("ID", 100)
+	mod := g.AddColumn("Mod", 100)
+	mod.Sortable, mod.Editable = true, true
+	c.Check(countConcrete(g.AbstractElement) < 100, chk.Equals, true)
+
+	rowY := func(i int) int { return GridHeaderHeight + i*GridRowHeight + 5 }
+	var selections [][]int
+	g.OnSelect = func(rows []int) { selections = append(selections, rows) }
+	click(sc, 10, rowY(2))
+	c.Check(g.Selected(), chk.DeepEquals, []int{2})
+	c.Check(sc.Focused(), chk.Equals, gs.IElement(g.AbstractElement))
+
+	press(sc, gs.KeyPageDown)
+	c.Check(g.Selected(), chk.DeepEquals, []int{11})
+	c.Check(g.FirstVisible(), chk.Equals, 2)
+	sc.HandleMouse(&gs.MouseEvent{Pos: image.Point{10, rowY(0)}, Action: gs.EventScroll, Scroll: image.Point{0, 1}})
+	c.Check(g.FirstVisible(), chk.Equals, 0)
+	press(sc, gs.KeyEnd)
+	c.Check(g.FirstVisible(), chk.Equals, 9990)
+	c.Check(g.Selected(), chk.DeepEquals, []int{9999})
+
+	g.Multiselect = true
+	click(sc, 10, rowY(0))
+	sc.HandleKey(&gs.KeyEvent{Key: gs.KeyDown, Mod: gs.Modifiers{Shift: true}, Action: gs.EventPress})
+	sc.HandleMouse(&gs.MouseEvent{Pos: image.Point{10, rowY(5)}, Mod: gs.Modifiers{Control: true}, Action: gs.EventPress})
+	c.Check(g.Selected(), chk.DeepEquals, []int{9990, 9991, 9995})
+	c.Check(len(selections), chk.Equals, 6)
+
+	// Sorting by clicking the header, the selection follows the rows
+	click(sc, 150, 10)
+	sorted, _ := g.SortColumn()
+	c.Check(sorted, chk.Equals, mod)
+	c.Check([]int{g.RowAt(0), g.RowAt(1), g.RowAt(1428), g.RowAt(1429)}, chk.DeepEquals, []int{0, 7, 9996, 1})
+	c.Check(g.Selected(), chk.DeepEquals, []int{9990, 9991, 9995})
+	click(sc, 150, 10)
+	_, desc := g.SortColumn()
+	c.Check(desc, chk.Equals, true)
+	c.Check(g.RowAt(0), chk.Equals, 6)
+
+	// Resizing and reordering columns
+	sc.HandleMouse(&gs.MouseEvent{Pos: image.Point{100, 10}, Action: gs.EventPress})
+	sc.HandleMouse(&gs.MouseEvent{Pos: image.Point{60, 10}, Action: gs.EventMove})
+	sc.HandleMouse(&gs.MouseEvent{Pos: image.Point{5, 10}, Action: gs.EventMove})
+	sc.HandleMouse(&gs.MouseEvent{Pos: image.Point{5, 10}, Action: gs.EventRelease})
+	c.Check(id.Width, chk.Equals, id.MinWidth)
+	sc.HandleMouse(&gs.MouseEvent{Pos: image.Point{10, 10}, Action: gs.EventPress})
+	sc.HandleMouse(&gs.MouseEvent{Pos: image.Point{50, 10}, Action: gs.EventMove})
+	sc.HandleMouse(&gs.MouseEvent{Pos: image.Point{50, 10}, Action: gs.EventRelease})
+	c.Check(g.Columns(), chk.DeepEquals, []*GridColumn{mod, id})
+	_, desc = g.SortColumn()
+	c.Check(desc, chk.Equals, true)
+
+	// Inline editing, the Mod column is now first
+	g.Multiselect = false
+	press(sc, gs.KeyHome)
+	press(sc, gs.KeyEnter)
+	c.Check(g.Editing(), chk.Equals, true)
+	for _, r := range "42" {
+		sc.HandleChar(&gs.CharEvent{Char: r})
+	}
+	press(sc, gs.KeyEnter)
+	c.Check(g.Editing(), chk.Equals, false)
+	c.Check(m[6][1], chk.Equals, "642")
+	c.Check(sc.Focused(), chk.Equals, gs.IElement(g.AbstractElement))
+
+	press(sc, gs.KeyF2)
+	sc.HandleChar(&gs.CharEvent{Char: '!'})
+	press(sc, gs.KeyEscape)
+	c.Check(m[6][1], chk.Equals, "642")
+	// The second click on the current cell edits it, the ID column isn't editable
+	click(sc, 10, rowY(1))
+	click(sc, 10, rowY(1))
+	c.Check(g.Editing(), chk.Equals, true)
+	click(sc, 30, rowY(1))
+	c.Check(g.Editing(), chk.Equals, true)
+	click(sc, 110, rowY(3))
+	c.Check(g.Editing(), chk.Equals, false)
+	click(sc, 110, rowY(3))
+	c.Check(g.Editing(), chk.Equals, false)
+}
+
+// numberTree has the nodes 1 to 9, the children of n being 10n to 10n+9, up to depth 3
+type numberTree struct {
+	loads []interface{}
+}
+
+func (t *numberTree) Children(node interface{}) (l []interface{}) {
+	t.loads = append(t.loads, node)
+	n := 0
+	if node != nil {
+		n = node.(int) * 10
+	}
+	for i := 1; i < 10; i++ {
+		l = append(l, n+i)
+	}
+	return l
+}
+
+func (t *numberTree) HasChildren(node interface{}) bool { return node == nil || node.(int) < 100 }
+func (t *numberTree) Text(node interface{}) string      { return strconv.Itoa(node.(int)) }
+
+func (s *WidgetsSuite) TestTreeView(c *chk.C) {
+	sc := gs.NewScene()
+	src := new(numberTree)
+	t := NewTreeView(sc.Root(), gs.MakeRectWH(0, 0, 200, 5*TreeRowHeight), src)
+	c.Check(src.loads, chk.DeepEquals, []interface{}{nil})
+	var selected []interface{}
+	t.OnSelect = func(node interface{}) { selected = append(selected, node) }
+
+	rowY := func(i int) int { return i*TreeRowHeight + 5 }
+	click(sc, 50, rowY(1))
+	c.Check(t.Selected(), chk.Equals, 2)
+	press(sc, gs.KeyRight)
+	c.Check(t.IsExpanded(2), chk.Equals, true)
+	c.Check(src.loads, chk.DeepEquals, []interface{}{nil, 2})
+	press(sc, gs.KeyRight)
+	press(sc, gs.KeyRight)
+	press(sc, gs.KeyRight)
+	c.Check(t.Selected(), chk.Equals, 211)
+	press(sc, gs.KeyRight)
+	c.Check(t.Selected(), chk.Equals, 211)
+	press(sc, gs.KeyLeft)
+	press(sc, gs.KeyLeft)
+	c.Check(t.Selected(), chk.Equals, 21)
+	c.Check(t.IsExpanded(21), chk.Equals, false)
+	press(sc, gs.KeyLeft)
+	press(sc, gs.KeyLeft)
+	c.Check(t.Selected(), chk.Equals, 2)
+	c.Check(selected, chk.DeepEquals, []interface{}{2, 21, 211, 21, 2})
+
+	// Clicking the arrow toggles, collapsed children keep their state
+	t.Expand(21)
+	t.Collapse(2)
+	click(sc, 10, rowY(1))
+	c.Check(t.IsExpanded(2), chk.Equals, true)
+	c.Check(t.IsExpanded(21), chk.Equals, true)
+	click(sc, 10, rowY(1))
+	c.Check(t.IsExpanded(2), chk.Equals, false)
+
+	var activated interface{}
+	t.OnActivate = func(node interface{}) { activated = node }
+	press(sc, gs.KeyEnd)
+	press(sc, gs.KeyEnter)
+	c.Check(activated, chk.Equals, 9)
+
+	t.Select(999)
+	c.Check(t.Selected(), chk.Equals, 9) // 999 isn't loaded
+	t.Select(211)
+	c.Check(t.IsExpanded(2), chk.Equals, true)
+	c.Check(t.FirstVisible(), chk.Equals, 3)
+	t.Reload(2)
+	c.Check(t.Selected(), chk.Equals, 211)
+	c.Check(src.loads, chk.DeepEquals, []interface{}{nil, 2, 21, 2})
+
+	root := gs.NewScene().Root()
+	gs.NewRectElement(root, gs.MakeRectWH(0, 0, 10, 10))
+	et := NewTreeView(sc.Root(), gs.MakeRectWH(0, 200, 200, 100), ElementTree{root})
+	et.Expand(root)
+	c.Check(et.IsExpanded(root), chk.Equals, true)
+	c.Check(len(et.rows), chk.Equals, 3) // The root, the overlay of the scene and the rect
+}
+
+func (s *WidgetsSuite) TestTooltip(c *chk.C) {
+	sc := gs.NewScene()
+	clock := gs.NewFakeClock(time.Unix(0, 0))
+	sc.SetClock(clock)
+	b := NewButton(sc.Root(), gs.MakeRectWH(0, 0, 100, 30), "OK")
+	SetTooltip(b, "Save\nthe file")
+	sc.HandleMouse(&gs.MouseEvent{Pos: image.Point{10, 10}, Action: gs.EventMove})
+	clock.Advance(gs.TooltipDelay)
+	sc.Update()
+	p := sc.Tooltip()
+	c.Assert(p, chk.NotNil)
+	// Two lines of 18px, the longest being 52px wide
+	c.Check(p.Size(), chk.Equals, image.Point{52 + 2*tooltipPadding, 36 + 2*tooltipPadding})
+
+	SetTooltip(b, "")
+	c.Check(sc.Tooltip(), chk.IsNil)
+}
+
+func (s *WidgetsSuite) TestThemes(c *chk.C) {
+	sc := gs.NewScene()
+	b := NewButton(sc.Root(), gs.MakeRect(0, 0, 80, 24), "OK")
+	c.Check(StyleFrom(LightTheme, b.AbstractElement, DefaultStyle), chk.DeepEquals, DefaultStyle)
+
+	tabs := NewTabView(sc.Root(), gs.MakeRect(0, 40, 200, 200))
+	content := gs.NewAbstractElement(sc.Root(), gs.MakeRect(0, 0, 200, 160))
+	label := gs.NewTextElement(content, 0, 20, DefaultFont, false)
+	tabs.AddTab("A", content)
+	hidden := gs.NewTextElement(gs.NewAbstractElement(sc.Root(), gs.MakeRect(0, 0, 200, 160)), 0, 20, DefaultFont, false)
+	tabs.AddTab("B", hidden.Parent())
+
+	sc.SetStylesheet(DarkTheme)
+	c.Check(b.Style.Normal.FillColor, chk.Equals, rgb(0x2d, 0x2d, 0x30))
+	c.Check(b.Style.Text, chk.Equals, rgb(0xe6, 0xe6, 0xe6))
+	c.Check(b.bg.FillColor, chk.Equals, rgb(0x2d, 0x2d, 0x30))
+	c.Check(tabs.Style.Base, chk.Equals, rgb(0x1e, 0x1e, 0x1e))
+	c.Check(label.FillColor, chk.Equals, rgb(0xe6, 0xe6, 0xe6))
+	c.Check(hidden.FillColor, chk.Equals, gs.Color{})
+	tabs.SetCurrent(1)
+	c.Check(hidden.FillColor, chk.Equals, rgb(0xe6, 0xe6, 0xe6))
+
+	sc.SetStylesheet(LightTheme)
+	c.Check(b.Style, chk.DeepEquals, DefaultStyle)
+	c.Check(hidden.FillColor, chk.Equals, rgb(0, 0, 0))
+
+	sheet := gs.MustParseStylesheet(`
+		button.primary { fill: #0078d7; color: #fff }
+		button.primary:hover { fill: #1084e0 }
+	`)
+	b.AddClass("primary")
+	sc.SetStylesheet(sheet)
+	c.Check(b.Style.Normal, chk.Equals, paint(rgb(0, 120, 215), DefaultStyle.Normal.StrokeColor))
+	c.Check(b.Style.Hovered.FillColor, chk.Equals, rgb(0x10, 0x84, 0xe0))
+	c.Check(b.Style.Pressed.FillColor, chk.Equals, rgb(0x10, 0x84, 0xe0))
+	c.Check(b.Style.Text, chk.Equals, rgb(255, 255, 255))
+	c.Check(b.Style.Focus, chk.Equals, DefaultStyle.Focus)
+
+	// Labels are centered again for the font of the new style
+	sc.SetStylesheet(gs.MustParseStylesheet(`button { font: Arial 30 }`))
+	c.Check(b.Style.Font.Size, chk.Equals, 30)
+	w, _ := gs.MeasureText("OK", b.Style.Font)
+	c.Check(b.label.TextShape().Origin(), chk.Equals, image.Point{(80 - w) / 2, (24 + 30*7/10) / 2})
+}
+
+func (s *WidgetsSuite) TestAccessibility(c *chk.C) {
+	sc := gs.NewScene()
+	rec := new(gs.AccessRecorder)
+	sc.SetAccessAdapter(rec)
+	b := NewButton(sc.Root(), gs.MakeRectWH(0, 0, 100, 30), "Save")
+	cb := NewCheckbox(sc.Root(), gs.MakeRectWH(0, 40, 100, 30), "Remember")
+	cb.SetChecked(true)
+	r := NewRadioGroup(sc.Root(), gs.MakeRectWH(0, 80, 100, 2*RadioItemHeight), []string{"A", "B"})
+	r.SetSelected(1)
+	sl := NewSlider(sc.Root(), gs.MakeRectWH(0, 140, 100, 20), Horizontal, 0, 10)
+	sl.SetValue(2.5)
+	sl.SetEnabled(false)
+	tv := NewTabView(sc.Root(), gs.MakeRectWH(200, 0, 200, 100))
+	tv.Closable = true
+	tv.AddTab("One", newLabel(sc.Root(), gs.MakeRectWH(200, 30, 100, 20), "First page", DefaultFont))
+	tv.AddTab("Two", gs.NewAbstractElement(sc.Root(), gs.MakeRectWH(200, 30, 100, 20)))
+	g := NewDataGrid(sc.Root(), gs.MakeRectWH(200, 200, 200, GridHeaderHeight+2*GridRowHeight), tableModel{{"1", "a"}, {"2", "b"}, {"3", "c"}})
+	g.AddColumn("ID", 100)
+	g.AddColumn("Name", 100)
+	g.SetSelected(1)
+	c.Check(sc.AccessTree().Dump(), chk.Equals, `window
+  button "Save" [focusable]
+  check box "Remember" [focusable checkable checked]
+  group [focusable]
+    radio button "A" [checkable]
+    radio button "B" [checkable checked]
+  slider value="2.5" [disabled]
+  tab list
+    tab "One" [selected]
+    tab "Two"
+  tab panel "One"
+    text "First page"
+  grid [focusable]
+    row
+      column header "ID"
+      column header "Name"
+    row
+      cell "1"
+      cell "a"
+    row [selected]
+      cell "2"
+      cell "b"
+`)
+
+	b.Focus()
+	c.Check(rec.Focus, chk.DeepEquals, []string{`button "Save" [focusable focused]`})
+
+	// Menus and dropdowns
+	bar := NewMenuBar(sc.Root(), gs.MakeRectWH(0, 400, 300, 24))
+	bar.AddMenu("File", NewMenu(&MenuItem{Text: "Open", Shortcut: gs.Shortcut{Key: gs.KeyA + 'O' - 'A', Mod: gs.Modifiers{Control: true}}},
+		&MenuItem{Text: "Recent", Submenu: NewMenu(&MenuItem{Text: "a.txt"})}, Separator(),
+		&MenuItem{Text: "Autosave", Checkable: true, Checked: true}))
+	bar.OpenMenu(0)
+	m := bar.Menus()[0]
+	m.move(1)
+	m.openSub(1, false)
+	tree := sc.AccessTree().Children
+	n := tree[len(tree)-3]
+	c.Check(n.Dump(), chk.Equals, `menu bar
+  menu item "File" [expandable expanded]
+`)
+	n = tree[len(tree)-2]
+	c.Check(n.Dump(), chk.Equals, `menu [focusable focused]
+  menu item "Open" [selected]
+  menu item "Recent" [expandable expanded]
+  menu item "Autosave" [checkable checked]
+`)
+	c.Check(n.Children[0].Description, chk.Equals, "Ctrl+O")
+}